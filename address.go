@@ -4,6 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/rpcclient"
+	"github.com/btcsuite/btcd/txscript"
 )
 
 // GenerateBech32 generates a new Bech32 (native SegWit) address for the given label.
@@ -87,3 +93,268 @@ func (r *Regtest) generateAddress(ctx context.Context, label, addrType string) (
 	}
 	return address, nil
 }
+
+// maxGenerateAddresses caps GenerateAddresses so a typo can't ask bitcoind
+// to derive millions of keys in one batch.
+const maxGenerateAddresses = 10_000
+
+// GenerateAddresses returns count fresh addresses for label from the loaded
+// wallet. The getnewaddress calls go out as a single JSON-RPC batch — one
+// HTTP round-trip instead of count — which makes fan-out setups with
+// hundreds of receive addresses fast.
+//
+// Parameters:
+//   - label: address label, as for GenerateBech32
+//   - addrType: "legacy", "p2sh-segwit", "bech32", "bech32m", or "" for the
+//     wallet's -addresstype default
+//   - count: number of addresses, 1 to 10,000
+//
+// Returns:
+//   - []string: the addresses, in derivation order
+//   - error: validation error for an out-of-range count; errNotConnected
+//     before Start; otherwise the wrapped error of the batch or of the first
+//     failing call (e.g. an unknown addrType).
+//
+// Example:
+//
+//	addrs, err := rt.GenerateAddresses("fanout", "bech32", 200)
+//	if err != nil {
+//	    return err
+//	}
+func (r *Regtest) GenerateAddresses(label, addrType string, count int) ([]string, error) {
+	return r.GenerateAddressesContext(context.Background(), label, addrType, count)
+}
+
+// GenerateAddressesContext is the context-aware variant of GenerateAddresses.
+func (r *Regtest) GenerateAddressesContext(ctx context.Context, label, addrType string, count int) ([]string, error) {
+	if count <= 0 || count > maxGenerateAddresses {
+		return nil, fmt.Errorf("count must be in [1, %d], got %d", maxGenerateAddresses, count)
+	}
+	if _, err := r.lockedClient(); err != nil {
+		return nil, err
+	}
+
+	params := []json.RawMessage{marshalString(label)}
+	if addrType != "" {
+		params = append(params, marshalString(addrType))
+	}
+
+	batch, err := rpcclient.NewBatch(r.dialConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create batch client: %w", err)
+	}
+	defer batch.Shutdown()
+
+	futures := make([]rpcclient.FutureRawResult, count)
+	for i := range futures {
+		futures[i] = batch.RawRequestAsync("getnewaddress", params)
+	}
+	if _, err := runWithContext(ctx, func() (struct{}, error) {
+		return struct{}{}, batch.Send()
+	}); err != nil {
+		return nil, fmt.Errorf("getnewaddress batch: %w", err)
+	}
+
+	addrs := make([]string, count)
+	for i, f := range futures {
+		resp, err := f.Receive()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get new address %d (%s): %w", i, addrType, err)
+		}
+		if err := json.Unmarshal(resp, &addrs[i]); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal address response: %w", err)
+		}
+	}
+	return addrs, nil
+}
+
+// marshalString JSON-encodes s; encoding a string cannot fail.
+func marshalString(s string) json.RawMessage {
+	b, _ := json.Marshal(s)
+	return b
+}
+
+// LabelAddress is one entry of GetAddressesByLabel.
+type LabelAddress struct {
+	// Purpose is "receive" for addresses handed out by getnewaddress and
+	// "send" for address-book entries.
+	Purpose string `json:"purpose"`
+}
+
+// GetAddressesByLabel returns every address the loaded wallet holds under
+// label, keyed by address, with each address's purpose.
+//
+// Returns the curated LabelAddress rather than btcjson.GetAddressInfoResult:
+// getaddressesbylabel only reports the purpose, which the btcjson type does
+// not model.
+//
+// Parameters:
+//   - label: label to enumerate ("" is the default label)
+//
+// Returns:
+//   - map[string]LabelAddress: address → entry; empty (not nil) when no
+//     address carries the label
+//   - error: errNotConnected before Start; otherwise wrapped RPC or
+//     unmarshal error (e.g. no wallet loaded).
+//
+// Example:
+//
+//	addrs, err := rt.GetAddressesByLabel("payroll")
+//	if err != nil {
+//	    return err
+//	}
+//	for addr, info := range addrs {
+//	    fmt.Println(addr, info.Purpose)
+//	}
+func (r *Regtest) GetAddressesByLabel(label string) (map[string]LabelAddress, error) {
+	return r.GetAddressesByLabelContext(context.Background(), label)
+}
+
+// GetAddressesByLabelContext is the context-aware variant of
+// GetAddressesByLabel.
+func (r *Regtest) GetAddressesByLabelContext(ctx context.Context, label string) (map[string]LabelAddress, error) {
+	resp, err := r.rawRPC(ctx, "getaddressesbylabel", label)
+	if err != nil {
+		// bitcoind reports an unused label as an error rather than an
+		// empty object.
+		if strings.Contains(err.Error(), "No addresses with label") {
+			return map[string]LabelAddress{}, nil
+		}
+		return nil, fmt.Errorf("getaddressesbylabel %q: %w", label, err)
+	}
+	out := map[string]LabelAddress{}
+	if err := json.Unmarshal(resp, &out); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal getaddressesbylabel: %w", err)
+	}
+	return out, nil
+}
+
+// SetLabel moves address to label in the loaded wallet via setlabel. After
+// the call GetAddressesByLabel(label) includes address and its previous
+// label no longer does.
+//
+// The address is checked with getaddressinfo first: bitcoind's setlabel
+// would silently add a foreign address to the address book as a "send"
+// entry, which is rarely what a test means.
+//
+// Parameters:
+//   - address: address owned by the loaded wallet (must be non-empty)
+//   - label: new label ("" moves it to the default label)
+//
+// Returns:
+//   - error: validation error for an empty address; an error naming the
+//     address when the wallet doesn't own it; errNotConnected before Start;
+//     otherwise wrapped RPC error.
+//
+// Example:
+//
+//	if err := rt.SetLabel(addr, "cold-storage"); err != nil {
+//	    return err
+//	}
+func (r *Regtest) SetLabel(address, label string) error {
+	return r.SetLabelContext(context.Background(), address, label)
+}
+
+// SetLabelContext is the context-aware variant of SetLabel.
+func (r *Regtest) SetLabelContext(ctx context.Context, address, label string) error {
+	if address == "" {
+		return fmt.Errorf("address must be provided")
+	}
+	resp, err := r.rawRPC(ctx, "getaddressinfo", address)
+	if err != nil {
+		return fmt.Errorf("getaddressinfo %s: %w", address, err)
+	}
+	var info struct {
+		IsMine bool `json:"ismine"`
+	}
+	if err := json.Unmarshal(resp, &info); err != nil {
+		return fmt.Errorf("failed to unmarshal getaddressinfo: %w", err)
+	}
+	if !info.IsMine {
+		return fmt.Errorf("address %s is not owned by the loaded wallet", address)
+	}
+	if _, err := r.rawRPC(ctx, "setlabel", address, label); err != nil {
+		return fmt.Errorf("setlabel %s: %w", address, err)
+	}
+	return nil
+}
+
+// ListLabels returns the labels in the loaded wallet via listlabels,
+// optionally only those attached to addresses of one purpose. Together with
+// GetAddressesByLabel it lets a test check that the labels it passed to
+// GenerateBech32 or SetLabel were registered.
+//
+// Parameters:
+//   - purpose: "receive", "send", or "" for every label
+//
+// Returns:
+//   - []string: labels as bitcoind orders them (empty, not nil, when there
+//     are none); the default label is reported as ""
+//   - error: validation error for an unknown purpose; errNotConnected
+//     before Start; otherwise wrapped RPC or unmarshal error.
+//
+// Example:
+//
+//	labels, err := rt.ListLabels("receive")
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Println("receive labels:", labels)
+func (r *Regtest) ListLabels(purpose string) ([]string, error) {
+	return r.ListLabelsContext(context.Background(), purpose)
+}
+
+// ListLabelsContext is the context-aware variant of ListLabels.
+func (r *Regtest) ListLabelsContext(ctx context.Context, purpose string) ([]string, error) {
+	var args []any
+	switch purpose {
+	case "":
+	case "receive", "send":
+		args = append(args, purpose)
+	default:
+		return nil, fmt.Errorf("purpose must be \"receive\", \"send\" or empty, got %q", purpose)
+	}
+	resp, err := r.rawRPC(ctx, "listlabels", args...)
+	if err != nil {
+		return nil, fmt.Errorf("listlabels: %w", err)
+	}
+	labels := []string{}
+	if err := json.Unmarshal(resp, &labels); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal listlabels: %w", err)
+	}
+	return labels, nil
+}
+
+// ScriptForAddress returns the scriptPubKey paying a regtest address,
+// computed locally with txscript.PayToAddrScript — the bytes a wire.TxOut
+// needs when building a transaction by hand. No RPC is issued. All standard
+// address types are supported (P2PKH, P2SH, P2WPKH, P2WSH, P2TR).
+//
+// Parameters:
+//   - address: regtest address (e.g. "bcrt1q...")
+//
+// Returns:
+//   - []byte: the output script
+//   - error: decode error for an invalid address or one for another network.
+//
+// Example:
+//
+//	script, err := regtest.ScriptForAddress(addr)
+//	if err != nil {
+//	    return err
+//	}
+//	tx.AddTxOut(wire.NewTxOut(50_000, script))
+func ScriptForAddress(address string) ([]byte, error) {
+	addr, err := btcutil.DecodeAddress(address, &chaincfg.RegressionNetParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode address %q: %w", address, err)
+	}
+	if !addr.IsForNet(&chaincfg.RegressionNetParams) {
+		return nil, fmt.Errorf("address %q is not a regtest address", address)
+	}
+	script, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build script for %q: %w", address, err)
+	}
+	return script, nil
+}
@@ -0,0 +1,198 @@
+package regtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// CheckUTXO verifies that op is an unspent output worth exactly expectedSats.
+// It collapses the GetTxOut / nil-check / compare-value pattern into one call
+// with a descriptive error.
+//
+// Parameters:
+//   - op: outpoint to look up
+//   - expectedSats: expected output value in satoshis
+//   - includeMempool: also accept outputs created by mempool transactions
+//     (and treat outputs spent in the mempool as spent)
+//
+// Returns:
+//   - error: nil when the output exists with the expected value; otherwise
+//     a descriptive error for a spent/missing output or a value mismatch.
+//     errNotConnected before Start; wrapped RPC error on failure.
+//
+// Example:
+//
+//	if err := rt.CheckUTXO(wire.OutPoint{Hash: *txid, Index: 0}, 50_000, true); err != nil {
+//	    return err
+//	}
+func (r *Regtest) CheckUTXO(op wire.OutPoint, expectedSats int64, includeMempool bool) error {
+	return r.CheckUTXOContext(context.Background(), op, expectedSats, includeMempool)
+}
+
+// CheckUTXOContext is the context-aware variant of CheckUTXO.
+func (r *Regtest) CheckUTXOContext(ctx context.Context, op wire.OutPoint, expectedSats int64, includeMempool bool) error {
+	out, err := r.GetTxOutContext(ctx, &op.Hash, op.Index, includeMempool)
+	if err != nil {
+		return fmt.Errorf("utxo %s: %w", op, err)
+	}
+	if out == nil {
+		return fmt.Errorf("utxo %s: spent or does not exist", op)
+	}
+	got, err := btcutil.NewAmount(out.Value)
+	if err != nil {
+		return fmt.Errorf("utxo %s: converting value %v: %w", op, out.Value, err)
+	}
+	if int64(got) != expectedSats {
+		return fmt.Errorf("utxo %s: value %d sat, want %d sat", op, int64(got), expectedSats)
+	}
+	return nil
+}
+
+// AssertUTXO is the testing.TB form of CheckUTXO: it fails the test with
+// tb.Fatalf when op is spent, missing, or not worth expectedSats. Like any
+// Fatal call it must run on the test's own goroutine.
+//
+// Example:
+//
+//	rt.AssertUTXO(t, wire.OutPoint{Hash: *txid, Index: 1}, 50_000, true)
+func (r *Regtest) AssertUTXO(tb testing.TB, op wire.OutPoint, expectedSats int64, includeMempool bool) {
+	tb.Helper()
+	if err := r.CheckUTXO(op, expectedSats, includeMempool); err != nil {
+		tb.Fatalf("AssertUTXO: %v", err)
+	}
+}
+
+// CheckDeploymentActiveAt verifies that deployment activates exactly at
+// height: the block at height is the first validated under its rules.
+// getdeploymentinfo reports whether rules apply to the block after the one
+// it is evaluated at, so the deployment must be active when evaluated at
+// height-1 and inactive at height-2. For buried deployments this pins the
+// height given to -testactivationheight=<name>@<height>.
+//
+// Parameters:
+//   - deployment: deployment name as known to bitcoind (e.g. "csv",
+//     "segwit", "testdummy")
+//   - height: expected activation height (must be >= 1; the chain must
+//     have reached height-1)
+//
+// Returns:
+//   - error: nil when the deployment activates exactly at height; a
+//     descriptive error when it isn't active yet or was already active one
+//     block earlier; ErrUnknownDeployment (errors.Is compatible) when the
+//     node doesn't report it; errNotConnected before Start; otherwise
+//     wrapped RPC error.
+//
+// Example:
+//
+//	// node started with ExtraArgs: -testactivationheight=csv@150
+//	if err := rt.CheckDeploymentActiveAt("csv", 150); err != nil {
+//	    return err
+//	}
+func (r *Regtest) CheckDeploymentActiveAt(deployment string, height int64) error {
+	return r.CheckDeploymentActiveAtContext(context.Background(), deployment, height)
+}
+
+// CheckDeploymentActiveAtContext is the context-aware variant of
+// CheckDeploymentActiveAt.
+func (r *Regtest) CheckDeploymentActiveAtContext(ctx context.Context, deployment string, height int64) error {
+	if height < 1 {
+		return fmt.Errorf("height must be >= 1, got %d", height)
+	}
+	active, err := r.deploymentActiveAfter(ctx, deployment, height-1)
+	if err != nil {
+		return err
+	}
+	if !active {
+		return fmt.Errorf("deployment %q is not active at height %d", deployment, height)
+	}
+	if height < 2 {
+		return nil
+	}
+	active, err = r.deploymentActiveAfter(ctx, deployment, height-2)
+	if err != nil {
+		return err
+	}
+	if active {
+		return fmt.Errorf("deployment %q is already active at height %d, want activation at %d",
+			deployment, height-1, height)
+	}
+	return nil
+}
+
+// deploymentActiveAfter reports whether deployment's rules apply to the
+// block after the one at height, via getdeploymentinfo at that block.
+func (r *Regtest) deploymentActiveAfter(ctx context.Context, deployment string, height int64) (bool, error) {
+	hash, err := r.GetBlockHashContext(ctx, height)
+	if err != nil {
+		return false, err
+	}
+	raw, err := r.rawRPC(ctx, "getdeploymentinfo", hash.String())
+	if err != nil {
+		return false, fmt.Errorf("getdeploymentinfo %s: %w", hash, err)
+	}
+	var info DeploymentInfo
+	if err := json.Unmarshal(raw, &info); err != nil {
+		return false, fmt.Errorf("unmarshal getdeploymentinfo: %w", err)
+	}
+	d, ok := info.Deployments[deployment]
+	if !ok {
+		return false, fmt.Errorf("%w: %q", ErrUnknownDeployment, deployment)
+	}
+	return d.Active, nil
+}
+
+// AssertDeploymentActiveAt is the testing.TB form of CheckDeploymentActiveAt:
+// it fails the test with tb.Fatalf unless deployment activates exactly at
+// height. Like any Fatal call it must run on the test's own goroutine.
+//
+// Example:
+//
+//	rt.AssertDeploymentActiveAt(t, "csv", 150)
+func (r *Regtest) AssertDeploymentActiveAt(tb testing.TB, deployment string, height int64) {
+	tb.Helper()
+	if err := r.CheckDeploymentActiveAt(deployment, height); err != nil {
+		tb.Fatalf("AssertDeploymentActiveAt: %v", err)
+	}
+}
+
+// CheckValueConserved verifies that the UTXO set's total value moved by
+// exactly expectedDelta between two TotalValue readings — normally the
+// BlockSubsidies of the blocks mined in between. A shortfall means value
+// went missing: an unclaimed fee or subsidy, an OP_RETURN burn, or a
+// transaction builder whose outputs don't add up. No RPC is issued.
+//
+// Returns:
+//   - error: nil when after-before == expectedDelta; otherwise an error
+//     giving both readings and the difference from the expected change.
+//
+// Example:
+//
+//	if err := regtest.CheckValueConserved(before, after, regtest.BlockSubsidies(h0, h1)); err != nil {
+//	    return err
+//	}
+func CheckValueConserved(before, after, expectedDelta int64) error {
+	if got := after - before; got != expectedDelta {
+		return fmt.Errorf("value not conserved: total went from %d to %d sat (%+d), want %+d (off by %+d)",
+			before, after, got, expectedDelta, got-expectedDelta)
+	}
+	return nil
+}
+
+// AssertValueConserved is the testing.TB form of CheckValueConserved: it
+// fails the test with tb.Fatalf when the total moved by anything other than
+// expectedDelta.
+//
+// Example:
+//
+//	regtest.AssertValueConserved(t, before, after, regtest.BlockSubsidies(h0, h1))
+func AssertValueConserved(tb testing.TB, before, after, expectedDelta int64) {
+	tb.Helper()
+	if err := CheckValueConserved(before, after, expectedDelta); err != nil {
+		tb.Fatalf("AssertValueConserved: %v", err)
+	}
+}
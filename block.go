@@ -2,10 +2,17 @@ package regtest
 
 import (
 	"context"
+	"encoding/binary"
+	"encoding/hex"
 	"fmt"
+	"math"
+	"time"
 
+	"github.com/btcsuite/btcd/blockchain"
 	"github.com/btcsuite/btcd/btcjson"
 	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 )
 
@@ -61,8 +68,9 @@ func (r *Regtest) GetBlockTemplateContext(ctx context.Context, req *btcjson.Temp
 //     commitment, and proof-of-work must already be valid.
 //
 // Returns:
-//   - error: validation error for nil block; errNotConnected before Start;
-//     otherwise wrapped RPC error including bitcoind's reject reason
+//   - error: validation error for nil block; ErrChainFrozen while the chain
+//     is frozen; errNotConnected before Start; otherwise wrapped RPC error
+//     including bitcoind's reject reason
 //     ("bad-cb-amount", "high-hash", "block-validation-failed", etc.).
 //
 // Example:
@@ -79,15 +87,116 @@ func (r *Regtest) SubmitBlockContext(ctx context.Context, block *wire.MsgBlock)
 	if block == nil {
 		return fmt.Errorf("block must not be nil")
 	}
+	if r.frozen.Load() {
+		return ErrChainFrozen
+	}
 	client, err := r.lockedClient()
 	if err != nil {
 		return err
 	}
+	r.beginMining()
 	_, err = runWithContext(ctx, func() (struct{}, error) {
+		defer r.abortMining()
 		return struct{}{}, client.SubmitBlock(btcutil.NewBlock(block), nil)
 	})
+	r.InvalidateHeightCache()
 	if err != nil {
 		return fmt.Errorf("submitblock: %w", err)
 	}
 	return nil
 }
+
+// maxCoinbaseScriptSig is the consensus upper bound on the coinbase
+// scriptSig length (bad-cb-length above it).
+const maxCoinbaseScriptSig = 100
+
+// coinbaseScriptSig builds a BIP34-compliant coinbase scriptSig: the height
+// push followed by a single push of data. An empty data pushes OP_0, which
+// also keeps low heights above the 2-byte consensus minimum.
+func coinbaseScriptSig(height int64, data []byte) ([]byte, error) {
+	script, err := txscript.NewScriptBuilder().AddInt64(height).AddData(data).Script()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build coinbase scriptSig: %w", err)
+	}
+	if len(script) > maxCoinbaseScriptSig {
+		return nil, fmt.Errorf("coinbase scriptSig would be %d bytes, max %d (data is %d bytes)",
+			len(script), maxCoinbaseScriptSig, len(data))
+	}
+	return script, nil
+}
+
+// assembleBlock builds and solves a block on top of tmpl: a coinbase with
+// scriptSig paying value to payout, followed by txs in order, with a segwit
+// witness commitment. The coinbase sets nLockTime to height-1 and a
+// non-final sequence, which BIP54 (Inquisition) requires and Core accepts.
+func assembleBlock(tmpl *btcjson.GetBlockTemplateResult, scriptSig, payout []byte, value int64, txs []*wire.MsgTx) (*wire.MsgBlock, error) {
+	prev, err := chainhash.NewHashFromStr(tmpl.PreviousHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template previous hash: %w", err)
+	}
+	bitsBytes, err := hex.DecodeString(tmpl.Bits)
+	if err != nil || len(bitsBytes) != 4 {
+		return nil, fmt.Errorf("failed to parse template bits %q", tmpl.Bits)
+	}
+	if tmpl.Height < 1 || tmpl.Height > math.MaxUint32 {
+		return nil, fmt.Errorf("template height %d out of range", tmpl.Height)
+	}
+
+	var witnessNonce [32]byte
+	coinbase := wire.NewMsgTx(2)
+	coinbase.LockTime = uint32(tmpl.Height - 1)
+	coinbase.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Index: wire.MaxPrevOutIndex},
+		SignatureScript:  scriptSig,
+		Sequence:         wire.MaxTxInSequenceNum - 1,
+		Witness:          wire.TxWitness{witnessNonce[:]},
+	})
+	coinbase.AddTxOut(wire.NewTxOut(value, payout))
+
+	all := make([]*btcutil.Tx, 0, len(txs)+1)
+	all = append(all, btcutil.NewTx(coinbase))
+	for _, tx := range txs {
+		all = append(all, btcutil.NewTx(tx))
+	}
+
+	// Witness commitment: sha256d(witness merkle root || nonce), where the
+	// coinbase wtxid counts as all zeroes (BIP141).
+	witnessTree := blockchain.BuildMerkleTreeStore(all, true)
+	witnessRoot := witnessTree[len(witnessTree)-1]
+	commitment := chainhash.DoubleHashB(append(witnessRoot[:], witnessNonce[:]...))
+	commitScript, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_RETURN).
+		AddData(append([]byte{0xaa, 0x21, 0xa9, 0xed}, commitment...)).
+		Script()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build witness commitment: %w", err)
+	}
+	coinbase.AddTxOut(wire.NewTxOut(0, commitScript))
+	all[0] = btcutil.NewTx(coinbase)
+
+	merkleTree := blockchain.BuildMerkleTreeStore(all, false)
+	block := wire.NewMsgBlock(&wire.BlockHeader{
+		Version:    tmpl.Version,
+		PrevBlock:  *prev,
+		MerkleRoot: *merkleTree[len(merkleTree)-1],
+		Timestamp:  time.Unix(tmpl.CurTime, 0),
+		Bits:       binary.BigEndian.Uint32(bitsBytes),
+	})
+	for _, tx := range all {
+		if err := block.AddTransaction(tx.MsgTx()); err != nil {
+			return nil, fmt.Errorf("failed to add transaction: %w", err)
+		}
+	}
+
+	target := blockchain.CompactToBig(block.Header.Bits)
+	for nonce := uint32(0); ; nonce++ {
+		block.Header.Nonce = nonce
+		h := block.Header.BlockHash()
+		if blockchain.HashToBig(&h).Cmp(target) <= 0 {
+			return block, nil
+		}
+		if nonce == ^uint32(0) {
+			return nil, fmt.Errorf("could not solve proof of work for bits %08x", block.Header.Bits)
+		}
+	}
+}
@@ -2,10 +2,15 @@ package regtest
 
 import (
 	"context"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"math/big"
 
+	"github.com/btcsuite/btcd/blockchain"
 	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/wire"
 )
@@ -168,6 +173,49 @@ func (r *Regtest) GetBlockContext(ctx context.Context, hash *chainhash.Hash) (*w
 	return block, nil
 }
 
+// GetBlockBytes returns the block's raw serialization exactly as bitcoind
+// stores it (getblock verbosity 0), witness data included, without parsing
+// it into a wire.MsgBlock — e.g. to seed a fuzzer or test a custom parser.
+//
+// Parameters:
+//   - hash: block hash (must be non-nil)
+//
+// Returns:
+//   - []byte: the serialized block
+//   - error: validation error for nil hash; errNotConnected if Start has not
+//     been called; otherwise wrapped RPC or hex-decode error.
+//
+// Example:
+//
+//	raw, err := rt.GetBlockBytes(hash)
+//	if err != nil {
+//	    return err
+//	}
+//	f.Add(raw) // seed corpus for a fuzz target
+func (r *Regtest) GetBlockBytes(hash *chainhash.Hash) ([]byte, error) {
+	return r.GetBlockBytesContext(context.Background(), hash)
+}
+
+// GetBlockBytesContext is the context-aware variant of GetBlockBytes.
+func (r *Regtest) GetBlockBytesContext(ctx context.Context, hash *chainhash.Hash) ([]byte, error) {
+	if hash == nil {
+		return nil, fmt.Errorf("hash must not be nil")
+	}
+	raw, err := r.rawRPC(ctx, "getblock", hash.String(), 0)
+	if err != nil {
+		return nil, fmt.Errorf("getblock %s: %w", hash, err)
+	}
+	var blockHex string
+	if err := json.Unmarshal(raw, &blockHex); err != nil {
+		return nil, fmt.Errorf("unmarshal getblock %s: %w", hash, err)
+	}
+	block, err := hex.DecodeString(blockHex)
+	if err != nil {
+		return nil, fmt.Errorf("decode getblock %s hex: %w", hash, err)
+	}
+	return block, nil
+}
+
 // GetBlockVerbose returns the verbose JSON form of the block (with tx ids,
 // confirmations, height, etc.) for the given hash.
 //
@@ -208,6 +256,50 @@ func (r *Regtest) GetBlockVerboseContext(ctx context.Context, hash *chainhash.Ha
 	return res, nil
 }
 
+// GetBlockVerboseTx is GetBlockVerbose at verbosity 2: the block with every
+// transaction decoded in place (inputs, outputs, scripts, witnesses) rather
+// than listed by txid, so a block's contents can be asserted without a
+// getrawtransaction per tx. Tx[0] is the coinbase.
+//
+// Parameters:
+//   - hash: block hash (must be non-nil)
+//
+// Returns:
+//   - *btcjson.GetBlockVerboseTxResult: the block with decoded transactions
+//   - error: validation error for nil hash; errNotConnected if Start has not
+//     been called; otherwise wrapped RPC error.
+//
+// Example:
+//
+//	b, err := rt.GetBlockVerboseTx(hash)
+//	if err != nil {
+//	    return err
+//	}
+//	for _, tx := range b.Tx[1:] {
+//	    fmt.Printf("%s: %d inputs, %d outputs\n", tx.Txid, len(tx.Vin), len(tx.Vout))
+//	}
+func (r *Regtest) GetBlockVerboseTx(hash *chainhash.Hash) (*btcjson.GetBlockVerboseTxResult, error) {
+	return r.GetBlockVerboseTxContext(context.Background(), hash)
+}
+
+// GetBlockVerboseTxContext is the context-aware variant of GetBlockVerboseTx.
+func (r *Regtest) GetBlockVerboseTxContext(ctx context.Context, hash *chainhash.Hash) (*btcjson.GetBlockVerboseTxResult, error) {
+	if hash == nil {
+		return nil, fmt.Errorf("hash must not be nil")
+	}
+	client, err := r.lockedClient()
+	if err != nil {
+		return nil, err
+	}
+	res, err := runWithContext(ctx, func() (*btcjson.GetBlockVerboseTxResult, error) {
+		return client.GetBlockVerboseTx(hash)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getblock verbosity 2 %s: %w", hash, err)
+	}
+	return res, nil
+}
+
 // GetBlockHeader returns the deserialized header for the given block hash.
 //
 // Parameters:
@@ -280,3 +372,230 @@ func (r *Regtest) GetChainTipsContext(ctx context.Context) ([]*btcjson.GetChainT
 	}
 	return tips, nil
 }
+
+// GetChainWork returns the total work of the active chain — the chainwork
+// field of getblockchaininfo — which is what bitcoind's fork choice
+// compares, rather than height. Regtest blocks at the minimum difficulty
+// add 2 each.
+//
+// Returns:
+//   - *big.Int: accumulated chain work of the active tip
+//   - error: errNotConnected if Start has not been called; otherwise wrapped
+//     RPC error, or an error when chainwork is not a hex number.
+//
+// Example:
+//
+//	work, err := rt.GetChainWork()
+//	if err != nil {
+//	    return err
+//	}
+//	if work.Cmp(otherWork) <= 0 {
+//	    t.Fatalf("node kept the chain with less work")
+//	}
+func (r *Regtest) GetChainWork() (*big.Int, error) {
+	return r.GetChainWorkContext(context.Background())
+}
+
+// GetChainWorkContext is the context-aware variant of GetChainWork.
+func (r *Regtest) GetChainWorkContext(ctx context.Context) (*big.Int, error) {
+	info, err := r.GetBlockChainInfoContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	work, ok := new(big.Int).SetString(info.Chainwork, 16)
+	if !ok {
+		return nil, fmt.Errorf("invalid chainwork %q", info.Chainwork)
+	}
+	return work, nil
+}
+
+// UTXOSetInfo is a curated subset of bitcoind's gettxoutsetinfo response.
+type UTXOSetInfo struct {
+	// Height and BestBlock identify the chain tip the statistics are for.
+	Height    int64
+	BestBlock string
+	// TxOuts is the number of unspent outputs.
+	TxOuts int64
+	// Hash is the serialized UTXO set hash (gettxoutsetinfo's
+	// hash_serialized_3, or hash_serialized_2 before Core 26). It commits
+	// to every unspent output, so two nodes with equal Hash at the same
+	// Height hold identical UTXO sets.
+	Hash string
+	// TotalAmount is the value of all unspent outputs.
+	TotalAmount btcutil.Amount
+}
+
+// GetTxOutSetInfo returns statistics about the node's UTXO set, including a
+// hash committing to its full contents. bitcoind computes it by scanning the
+// chainstate, which is fast at regtest sizes.
+//
+// Returns:
+//   - *UTXOSetInfo: tip height and hash, output count, set hash and total
+//   - error: errNotConnected before Start; otherwise the wrapped RPC or
+//     decode error.
+//
+// Example:
+//
+//	info, err := rt.GetTxOutSetInfo()
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Printf("height %d: %d utxos, hash %s\n", info.Height, info.TxOuts, info.Hash)
+func (r *Regtest) GetTxOutSetInfo() (*UTXOSetInfo, error) {
+	return r.GetTxOutSetInfoContext(context.Background())
+}
+
+// GetTxOutSetInfoContext is the context-aware variant of GetTxOutSetInfo.
+func (r *Regtest) GetTxOutSetInfoContext(ctx context.Context) (*UTXOSetInfo, error) {
+	raw, err := r.rawRPC(ctx, "gettxoutsetinfo")
+	if err != nil {
+		return nil, fmt.Errorf("gettxoutsetinfo: %w", err)
+	}
+	var res struct {
+		Height          int64   `json:"height"`
+		BestBlock       string  `json:"bestblock"`
+		TxOuts          int64   `json:"txouts"`
+		HashSerialized3 string  `json:"hash_serialized_3"`
+		HashSerialized2 string  `json:"hash_serialized_2"`
+		TotalAmount     float64 `json:"total_amount"`
+	}
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return nil, fmt.Errorf("unmarshal gettxoutsetinfo: %w", err)
+	}
+	total, err := btcutil.NewAmount(res.TotalAmount)
+	if err != nil {
+		return nil, fmt.Errorf("gettxoutsetinfo total_amount: %w", err)
+	}
+	info := &UTXOSetInfo{
+		Height:      res.Height,
+		BestBlock:   res.BestBlock,
+		TxOuts:      res.TxOuts,
+		Hash:        res.HashSerialized3,
+		TotalAmount: total,
+	}
+	if info.Hash == "" {
+		info.Hash = res.HashSerialized2
+	}
+	return info, nil
+}
+
+// TotalValue returns the value of every unspent output on the node, in
+// satoshis: gettxoutsetinfo's total_amount. Between two calls it grows by
+// exactly the subsidy of the blocks mined in between (BlockSubsidies) when
+// every transaction's inputs equal its outputs plus fee and every coinbase
+// claims its full reward. Outputs bitcoind never adds to the UTXO set —
+// OP_RETURN and other provably unspendable scripts — count as lost value.
+// Mempool transactions don't affect it.
+//
+// Returns:
+//   - int64: total unspent value in satoshis
+//   - error: errNotConnected before Start; otherwise the wrapped
+//     gettxoutsetinfo error.
+//
+// Example:
+//
+//	before, _ := rt.TotalValue()
+//	h0, _ := rt.GetBlockCount()
+//	// ... build, broadcast and mine transactions ...
+//	after, _ := rt.TotalValue()
+//	h1, _ := rt.GetBlockCount()
+//	regtest.AssertValueConserved(t, before, after, regtest.BlockSubsidies(h0, h1))
+func (r *Regtest) TotalValue() (int64, error) {
+	return r.TotalValueContext(context.Background())
+}
+
+// TotalValueContext is the context-aware variant of TotalValue.
+func (r *Regtest) TotalValueContext(ctx context.Context) (int64, error) {
+	info, err := r.GetTxOutSetInfoContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return int64(info.TotalAmount), nil
+}
+
+// BlockSubsidies returns the combined regtest block subsidy, in satoshis, of
+// the blocks above fromHeight up to and including toHeight — the value
+// TotalValue gains when the chain grows from one to the other. Regtest
+// halves the subsidy every 150 blocks. Returns 0 when toHeight <= fromHeight.
+//
+// Example:
+//
+//	regtest.BlockSubsidies(0, 101)   // 101 × 50 BTC = 5_050_000_000
+//	regtest.BlockSubsidies(148, 150) // 50 BTC + 25 BTC = 7_500_000_000
+func BlockSubsidies(fromHeight, toHeight int64) int64 {
+	var total int64
+	for h := int32(1); int64(h) <= toHeight; h++ {
+		subsidy := blockchain.CalcBlockSubsidy(h, &chaincfg.RegressionNetParams)
+		if subsidy == 0 {
+			break
+		}
+		if int64(h) > fromHeight {
+			total += subsidy
+		}
+	}
+	return total
+}
+
+// UTXOSetComparison is the result of UTXOSetsEqual: both nodes' UTXO set
+// hashes, read once at the same height.
+type UTXOSetComparison struct {
+	// Equal is true when HashA and HashB match.
+	Equal bool
+	// HashA and HashB are the serialized UTXO set hashes (UTXOSetInfo.Hash)
+	// of nodes a and b.
+	HashA, HashB string
+	// Height is the height both hashes were taken at.
+	Height int64
+}
+
+// UTXOSetsEqual reports whether nodes a and b hold identical UTXO sets, by
+// comparing GetTxOutSetInfo's set hash on both. It catches divergence a tip
+// comparison can miss, e.g. after a reorg-and-merge scenario. Both nodes
+// must be at the same height — sync them first (WaitForTip) — or an error
+// is returned. The result carries both hashes from the same readings, so a
+// mismatch can be reported without re-querying nodes that may have moved.
+//
+// Parameters:
+//   - a, b: started instances (must be non-nil)
+//
+// Returns:
+//   - *UTXOSetComparison: the match flag, both hashes and the height
+//   - error: validation error for a nil node; an error naming both heights
+//     when they differ; otherwise the wrapped gettxoutsetinfo error.
+//
+// Example:
+//
+//	cmp, err := regtest.UTXOSetsEqual(a, b)
+//	if err != nil {
+//	    return err
+//	}
+//	if !cmp.Equal {
+//	    t.Fatalf("UTXO sets differ at height %d: %s vs %s", cmp.Height, cmp.HashA, cmp.HashB)
+//	}
+func UTXOSetsEqual(a, b *Regtest) (*UTXOSetComparison, error) {
+	return UTXOSetsEqualContext(context.Background(), a, b)
+}
+
+// UTXOSetsEqualContext is the context-aware variant of UTXOSetsEqual.
+func UTXOSetsEqualContext(ctx context.Context, a, b *Regtest) (*UTXOSetComparison, error) {
+	if a == nil || b == nil {
+		return nil, fmt.Errorf("UTXOSetsEqual: nodes must not be nil")
+	}
+	infoA, err := a.GetTxOutSetInfoContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("UTXOSetsEqual: a: %w", err)
+	}
+	infoB, err := b.GetTxOutSetInfoContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("UTXOSetsEqual: b: %w", err)
+	}
+	if infoA.Height != infoB.Height {
+		return nil, fmt.Errorf("UTXOSetsEqual: nodes are at different heights (%d and %d)", infoA.Height, infoB.Height)
+	}
+	return &UTXOSetComparison{
+		Equal:  infoA.Hash == infoB.Hash,
+		HashA:  infoA.Hash,
+		HashB:  infoB.Hash,
+		Height: infoA.Height,
+	}, nil
+}
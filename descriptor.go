@@ -0,0 +1,124 @@
+package regtest
+
+import (
+	"fmt"
+	"strings"
+)
+
+// descriptorInputCharset is the character set output descriptors may use,
+// ordered so each character's index splits into a 5-bit symbol and a group
+// (BIP380).
+const descriptorInputCharset = "0123456789()[],'/*abcdefgh@:$%{}" +
+	"IJKLMNOPQRSTUVWXYZ&+-.;<=>?!^_|~" +
+	"ijklmnopqrstuvwxyzABCDEFGH`#\"\\ "
+
+// descriptorCharPos maps each descriptorInputCharset character to its index.
+var descriptorCharPos = func() map[rune]uint64 {
+	m := make(map[rune]uint64, len(descriptorInputCharset))
+	for i := uint64(0); int(i) < len(descriptorInputCharset); i++ {
+		m[rune(descriptorInputCharset[i])] = i
+	}
+	return m
+}()
+
+// descriptorChecksumCharset is the bech32 alphabet the 8-character checksum
+// is written in.
+const descriptorChecksumCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// descriptorPolyMod folds the 5-bit value val into the checksum state c
+// using the BIP380 generator.
+func descriptorPolyMod(c, val uint64) uint64 {
+	c0 := c >> 35
+	c = ((c & 0x7ffffffff) << 5) ^ val
+	if c0&1 != 0 {
+		c ^= 0xf5dee51989
+	}
+	if c0&2 != 0 {
+		c ^= 0xa9fdca3312
+	}
+	if c0&4 != 0 {
+		c ^= 0x1bab10e32d
+	}
+	if c0&8 != 0 {
+		c ^= 0x3706b1677a
+	}
+	if c0&16 != 0 {
+		c ^= 0x644d626ffd
+	}
+	return c
+}
+
+// DescriptorChecksum computes the 8-character BIP380 checksum of desc, which
+// must not carry a "#checksum" suffix. No RPC is issued; the result matches
+// getdescriptorinfo's checksum field.
+//
+// Returns:
+//   - string: the checksum, without the leading '#'
+//   - error: when desc contains a character descriptors may not use.
+//
+// Example:
+//
+//	sum, err := regtest.DescriptorChecksum("raw(deadbeef)") // "89f8spxm"
+func DescriptorChecksum(desc string) (string, error) {
+	c, cls, clsCount := uint64(1), uint64(0), 0
+	for i, ch := range desc {
+		pos, ok := descriptorCharPos[ch]
+		if !ok {
+			return "", fmt.Errorf("invalid descriptor character %q at position %d", ch, i)
+		}
+		c = descriptorPolyMod(c, pos&31)
+		cls = cls*3 + pos>>5
+		if clsCount++; clsCount == 3 {
+			c = descriptorPolyMod(c, cls)
+			cls, clsCount = 0, 0
+		}
+	}
+	if clsCount > 0 {
+		c = descriptorPolyMod(c, cls)
+	}
+	for range 8 {
+		c = descriptorPolyMod(c, 0)
+	}
+	c ^= 1
+
+	var sum [8]byte
+	for j := range sum {
+		sum[j] = descriptorChecksumCharset[(c>>(5*(7-j)))&31]
+	}
+	return string(sum[:]), nil
+}
+
+// ValidateDescriptorChecksum checks the "#checksum" suffix of desc against
+// the BIP380 checksum of the descriptor before it, without any RPC, so a
+// typo in a hand-written descriptor fails fast instead of at
+// importdescriptors or scanblocks.
+//
+// Parameters:
+//   - desc: a descriptor with its checksum, e.g. "wpkh(...)#abcd1234"
+//
+// Returns:
+//   - error: nil when the checksum matches; otherwise an error naming the
+//     missing, malformed or wrong checksum (with the expected one).
+//
+// Example:
+//
+//	if err := regtest.ValidateDescriptorChecksum(desc); err != nil {
+//	    t.Fatalf("bad descriptor: %v", err)
+//	}
+func ValidateDescriptorChecksum(desc string) error {
+	body, sum, ok := strings.Cut(desc, "#")
+	if !ok {
+		return fmt.Errorf("descriptor %q has no checksum", desc)
+	}
+	if len(sum) != 8 {
+		return fmt.Errorf("descriptor checksum %q must be 8 characters, got %d", sum, len(sum))
+	}
+	want, err := DescriptorChecksum(body)
+	if err != nil {
+		return err
+	}
+	if sum != want {
+		return fmt.Errorf("descriptor checksum %q is wrong, expected %q", sum, want)
+	}
+	return nil
+}
@@ -164,8 +164,8 @@ symlinking it as bitcoind-inquisition on PATH so the auto-detect chain finds it.
 # Port Considerations
 
 When running multiple instances, use widely spaced ports (e.g., 19000, 19100) because Bitcoin
-Core uses both RPC and P2P ports (typically RPC port + 1). Each instance needs a unique
-data directory.
+Core uses both RPC and P2P ports (RPC port + 1 unless Config.P2PPort is set; P2PPort reports
+the effective one). Each instance needs a unique data directory.
 
 # Use Cases
 
@@ -0,0 +1,152 @@
+package regtest
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+// coinbaseMaturity is the number of confirmations a coinbase output needs
+// before it can be spent.
+const coinbaseMaturity = 100
+
+// FixtureOpts configures SetupFundedChain. Zero values select the defaults
+// noted on each field.
+type FixtureOpts struct {
+	// Config is the node configuration; nil uses DefaultConfig with DataDir
+	// under tb.TempDir(). Tests running fixtures in parallel must give each
+	// its own Host port.
+	Config *Config
+	// Height is the chain height after setup (default 200). It must leave
+	// room for the funding coinbases to mature: at least 100 blocks more
+	// than the number of funding blocks.
+	Height int64
+	// WalletName is the wallet created and funded (default "fixture").
+	WalletName string
+	// FundingSats is the minimum spendable balance the wallet ends up with
+	// (default one block reward, 50 BTC). Funding is paid in whole coinbase
+	// rewards, so the balance may exceed it.
+	FundingSats int64
+}
+
+// SetupFundedChain starts a node with a chain at opts.Height and a wallet
+// holding at least opts.FundingSats of mature, spendable coins — the "give
+// me a ready-to-spend environment" setup in one call. Stop and Cleanup are
+// registered with tb.Cleanup. Any setup failure fails the test via
+// tb.Fatalf.
+//
+// The wallet is funded by mining just enough blocks to its address, then
+// maturing them with blocks paying an anyone-can-spend P2WSH(OP_TRUE)
+// address, so the wallet's balance comes only from the funding blocks.
+//
+// Parameters:
+//   - tb: the test or benchmark
+//   - opts: fixture options; nil for defaults
+//
+// Returns:
+//   - *Regtest: the started instance, with the wallet loaded
+//   - string: the funded wallet address
+//
+// Example:
+//
+//	rt, addr := regtest.SetupFundedChain(t, &regtest.FixtureOpts{Height: 150})
+//	txid, err := rt.SendToAddress(addr, 100_000)
+func SetupFundedChain(tb testing.TB, opts *FixtureOpts) (*Regtest, string) {
+	tb.Helper()
+	o := FixtureOpts{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Height == 0 {
+		o.Height = 200
+	}
+	if o.WalletName == "" {
+		o.WalletName = "fixture"
+	}
+	if o.FundingSats == 0 {
+		o.FundingSats = int64(blockchain.CalcBlockSubsidy(1, &chaincfg.RegressionNetParams))
+	}
+	funding, err := fundingBlocks(o.FundingSats)
+	if err != nil {
+		tb.Fatalf("SetupFundedChain: %v", err)
+	}
+	if o.Height < funding+coinbaseMaturity {
+		tb.Fatalf("SetupFundedChain: Height %d too low to mature %d funding blocks, need at least %d",
+			o.Height, funding, funding+coinbaseMaturity)
+	}
+
+	cfg := o.Config
+	if cfg == nil {
+		cfg = DefaultConfig()
+		cfg.DataDir = filepath.Join(tb.TempDir(), "regtest")
+	}
+	rt, err := New(cfg)
+	if err != nil {
+		tb.Fatalf("SetupFundedChain: New: %v", err)
+	}
+	tb.Cleanup(func() {
+		if err := rt.Stop(); err != nil {
+			tb.Logf("SetupFundedChain: Stop: %v", err)
+		}
+		if err := rt.Cleanup(); err != nil {
+			tb.Logf("SetupFundedChain: Cleanup: %v", err)
+		}
+	})
+	if err := rt.Start(); err != nil {
+		tb.Fatalf("SetupFundedChain: Start: %v", err)
+	}
+	if err := rt.EnsureWallet(o.WalletName); err != nil {
+		tb.Fatalf("SetupFundedChain: EnsureWallet: %v", err)
+	}
+	addr, err := rt.GenerateBech32(o.WalletName)
+	if err != nil {
+		tb.Fatalf("SetupFundedChain: GenerateBech32: %v", err)
+	}
+	burn, err := anyoneCanSpendAddress()
+	if err != nil {
+		tb.Fatalf("SetupFundedChain: %v", err)
+	}
+	if err := rt.Warp(funding, addr); err != nil {
+		tb.Fatalf("SetupFundedChain: funding Warp: %v", err)
+	}
+	if err := rt.Warp(o.Height-funding, burn); err != nil {
+		tb.Fatalf("SetupFundedChain: maturing Warp: %v", err)
+	}
+	return rt, addr
+}
+
+// fundingBlocks returns how many blocks from height 1 must pay the funded
+// wallet for their combined subsidy to reach sats.
+func fundingBlocks(sats int64) (int64, error) {
+	if sats < 0 {
+		return 0, fmt.Errorf("FundingSats must be >= 0, got %d", sats)
+	}
+	var total int64
+	for h := int32(1); ; h++ {
+		subsidy := blockchain.CalcBlockSubsidy(h, &chaincfg.RegressionNetParams)
+		if subsidy == 0 {
+			return 0, fmt.Errorf("FundingSats %d exceeds the total regtest subsidy", sats)
+		}
+		total += subsidy
+		if total >= sats {
+			return int64(h), nil
+		}
+	}
+}
+
+// anyoneCanSpendAddress returns the regtest P2WSH address of the script
+// OP_TRUE, used to mine blocks whose rewards no wallet claims.
+func anyoneCanSpendAddress() (string, error) {
+	script := sha256.Sum256([]byte{txscript.OP_TRUE})
+	addr, err := btcutil.NewAddressWitnessScriptHash(script[:], &chaincfg.RegressionNetParams)
+	if err != nil {
+		return "", fmt.Errorf("failed to build anyone-can-spend address: %w", err)
+	}
+	return addr.EncodeAddress(), nil
+}
@@ -0,0 +1,774 @@
+package regtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// MempoolEntry is the per-transaction record from getrawmempool true. Fee
+// fields are read from the "fees" object that Bitcoin Core 21+ reports; the
+// legacy top-level "fee"/"modifiedfee" keys that
+// btcjson.GetRawMempoolVerboseResult still models were removed in Core 22.
+type MempoolEntry struct {
+	// VSize is the virtual size in vbytes.
+	VSize int64
+	// Weight is the BIP141 weight.
+	Weight int64
+	// Time is the unix time the tx entered the mempool.
+	Time int64
+	// Height is the block height when the tx entered the mempool.
+	Height int64
+	// Fee is the tx's own base fee.
+	Fee btcutil.Amount
+	// ModifiedFee is Fee plus any prioritisetransaction delta.
+	ModifiedFee btcutil.Amount
+	// AncestorCount is the number of in-mempool ancestors, including this tx.
+	AncestorCount int64
+	// AncestorSize is the vsize of in-mempool ancestors, including this tx.
+	AncestorSize int64
+	// AncestorFee is the modified fee of in-mempool ancestors, including this tx.
+	AncestorFee btcutil.Amount
+	// DescendantCount is the number of in-mempool descendants, including this tx.
+	DescendantCount int64
+	// DescendantSize is the vsize of in-mempool descendants, including this tx.
+	DescendantSize int64
+	// DescendantFee is the modified fee of in-mempool descendants, including this tx.
+	DescendantFee btcutil.Amount
+	// Depends lists the txids of unconfirmed parents.
+	Depends []string
+	// SpentBy lists the txids of unconfirmed children.
+	SpentBy []string
+	// BIP125Replaceable reports whether the tx signals BIP125
+	// replaceability, directly or inherited from an unconfirmed ancestor.
+	// Under full RBF (the Core 28+ default) every tx is replaceable
+	// regardless of this flag.
+	BIP125Replaceable bool
+}
+
+// rawMempoolEntry mirrors bitcoind's JSON shape for a mempool entry.
+type rawMempoolEntry struct {
+	VSize           int64    `json:"vsize"`
+	Weight          int64    `json:"weight"`
+	Time            int64    `json:"time"`
+	Height          int64    `json:"height"`
+	DescendantCount int64    `json:"descendantcount"`
+	DescendantSize  int64    `json:"descendantsize"`
+	AncestorCount   int64    `json:"ancestorcount"`
+	AncestorSize    int64    `json:"ancestorsize"`
+	Depends         []string `json:"depends"`
+	SpentBy         []string `json:"spentby"`
+	BIP125          bool     `json:"bip125-replaceable"`
+	Fees            struct {
+		Base       float64 `json:"base"`
+		Modified   float64 `json:"modified"`
+		Ancestor   float64 `json:"ancestor"`
+		Descendant float64 `json:"descendant"`
+	} `json:"fees"`
+}
+
+// toEntry converts the raw JSON entry into a MempoolEntry.
+func (e rawMempoolEntry) toEntry() (MempoolEntry, error) {
+	out := MempoolEntry{
+		VSize:             e.VSize,
+		Weight:            e.Weight,
+		Time:              e.Time,
+		Height:            e.Height,
+		AncestorCount:     e.AncestorCount,
+		AncestorSize:      e.AncestorSize,
+		DescendantCount:   e.DescendantCount,
+		DescendantSize:    e.DescendantSize,
+		Depends:           append([]string(nil), e.Depends...),
+		SpentBy:           append([]string(nil), e.SpentBy...),
+		BIP125Replaceable: e.BIP125,
+	}
+	fees := []struct {
+		dst *btcutil.Amount
+		btc float64
+	}{
+		{&out.Fee, e.Fees.Base},
+		{&out.ModifiedFee, e.Fees.Modified},
+		{&out.AncestorFee, e.Fees.Ancestor},
+		{&out.DescendantFee, e.Fees.Descendant},
+	}
+	for _, f := range fees {
+		amt, err := btcutil.NewAmount(f.btc)
+		if err != nil {
+			return MempoolEntry{}, fmt.Errorf("converting fee %v: %w", f.btc, err)
+		}
+		*f.dst = amt
+	}
+	return out, nil
+}
+
+// GetMempoolInfo returns getmempoolinfo's transaction count and total size.
+// The btcjson result models only size and bytes; use MempoolMinFee for the
+// relay floor.
+//
+// Returns:
+//   - *btcjson.GetMempoolInfoResult: Size (transactions) and Bytes (sum of
+//     vsizes)
+//   - error: errNotConnected before Start; otherwise wrapped RPC or
+//     unmarshal error.
+//
+// Example:
+//
+//	info, err := rt.GetMempoolInfo()
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Printf("%d txs, %d vB\n", info.Size, info.Bytes)
+func (r *Regtest) GetMempoolInfo() (*btcjson.GetMempoolInfoResult, error) {
+	return r.GetMempoolInfoContext(context.Background())
+}
+
+// GetMempoolInfoContext is the context-aware variant of GetMempoolInfo.
+func (r *Regtest) GetMempoolInfoContext(ctx context.Context) (*btcjson.GetMempoolInfoResult, error) {
+	resp, err := r.rawRPC(ctx, "getmempoolinfo")
+	if err != nil {
+		return nil, fmt.Errorf("getmempoolinfo: %w", err)
+	}
+	var info btcjson.GetMempoolInfoResult
+	if err := json.Unmarshal(resp, &info); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal getmempoolinfo: %w", err)
+	}
+	return &info, nil
+}
+
+// GetRawMempool returns the txids of every transaction in the mempool, in
+// no particular order. Use GetRawMempoolVerbose for fees and package stats.
+//
+// Returns:
+//   - []*chainhash.Hash: mempool txids; empty for an empty mempool
+//   - error: errNotConnected before Start; otherwise wrapped RPC error.
+//
+// Example:
+//
+//	txids, err := rt.GetRawMempool()
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Printf("%d unconfirmed\n", len(txids))
+func (r *Regtest) GetRawMempool() ([]*chainhash.Hash, error) {
+	return r.GetRawMempoolContext(context.Background())
+}
+
+// GetRawMempoolContext is the context-aware variant of GetRawMempool.
+func (r *Regtest) GetRawMempoolContext(ctx context.Context) ([]*chainhash.Hash, error) {
+	client, err := r.lockedClient()
+	if err != nil {
+		return nil, err
+	}
+	txids, err := runWithContext(ctx, client.GetRawMempool)
+	if err != nil {
+		return nil, fmt.Errorf("getrawmempool: %w", err)
+	}
+	return txids, nil
+}
+
+// GetRawMempoolVerbose returns every mempool transaction keyed by txid, with
+// its fee, vsize, ancestor/descendant package stats, and in-mempool parents.
+// One call captures the whole mempool's fee structure, which is what eviction
+// order and package-composition assertions need.
+//
+// Returns the curated MempoolEntry rather than
+// btcjson.GetRawMempoolVerboseResult: the btcjson type has no ancestor or
+// descendant fields and reads fees from keys Bitcoin Core no longer sends.
+//
+// Returns:
+//   - map[string]MempoolEntry: txid → entry; empty (not nil) for an empty mempool
+//   - error: errNotConnected before Start; otherwise wrapped RPC or
+//     unmarshal error.
+//
+// Example:
+//
+//	pool, err := rt.GetRawMempoolVerbose()
+//	if err != nil {
+//	    return err
+//	}
+//	for txid, e := range pool {
+//	    fmt.Printf("%s: %d vB, fee %v, %d ancestors\n", txid, e.VSize, e.Fee, e.AncestorCount)
+//	}
+func (r *Regtest) GetRawMempoolVerbose() (map[string]MempoolEntry, error) {
+	return r.GetRawMempoolVerboseContext(context.Background())
+}
+
+// GetRawMempoolVerboseContext is the context-aware variant of GetRawMempoolVerbose.
+func (r *Regtest) GetRawMempoolVerboseContext(ctx context.Context) (map[string]MempoolEntry, error) {
+	resp, err := r.rawRPC(ctx, "getrawmempool", true)
+	if err != nil {
+		return nil, fmt.Errorf("getrawmempool: %w", err)
+	}
+	var raw map[string]rawMempoolEntry
+	if err := json.Unmarshal(resp, &raw); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal getrawmempool: %w", err)
+	}
+	out := make(map[string]MempoolEntry, len(raw))
+	for txid, e := range raw {
+		entry, err := e.toEntry()
+		if err != nil {
+			return nil, fmt.Errorf("mempool entry %s: %w", txid, err)
+		}
+		out[txid] = entry
+	}
+	return out, nil
+}
+
+// IsReplaceableInMempool reports bitcoind's view of whether a mempool
+// transaction is BIP125-replaceable, via getmempoolentry's
+// bip125-replaceable field. Unlike IsReplaceable this includes signaling
+// inherited from unconfirmed ancestors: a non-signaling child of a signaling
+// parent reads as replaceable.
+//
+// Parameters:
+//   - txid: transaction to look up (must be non-nil and in the mempool)
+//
+// Returns:
+//   - bool: the node's bip125-replaceable verdict
+//   - error: validation error for nil txid; errNotConnected before Start;
+//     otherwise wrapped RPC error ("Transaction not in mempool" when absent).
+//
+// Example:
+//
+//	ok, err := rt.IsReplaceableInMempool(txid)
+//	if err != nil {
+//	    return err
+//	}
+func (r *Regtest) IsReplaceableInMempool(txid *chainhash.Hash) (bool, error) {
+	return r.IsReplaceableInMempoolContext(context.Background(), txid)
+}
+
+// IsReplaceableInMempoolContext is the context-aware variant of
+// IsReplaceableInMempool.
+func (r *Regtest) IsReplaceableInMempoolContext(ctx context.Context, txid *chainhash.Hash) (bool, error) {
+	e, err := r.mempoolEntry(ctx, txid)
+	return e.BIP125Replaceable, err
+}
+
+// MempoolTxFeeRate returns txid's effective fee rate in sat/vB: its
+// modified fee (base fee plus any prioritisetransaction delta) divided by
+// its vsize, as read from getmempoolentry. This is the rate a miner sorts
+// the tx by on its own, ignoring ancestors — see MempoolTxAncestorFeeRate
+// for the CPFP-adjusted view.
+//
+// Parameters:
+//   - txid: transaction to look up (must be non-nil and in the mempool)
+//
+// Returns:
+//   - float64: fee rate in sat/vB
+//   - error: validation error for nil txid; errNotConnected before Start;
+//     otherwise wrapped RPC error ("Transaction not in mempool" when absent).
+//
+// Example:
+//
+//	rate, err := rt.MempoolTxFeeRate(txid)
+//	if err != nil {
+//	    return err
+//	}
+//	if rate < 10 {
+//	    t.Errorf("bumped rate %.2f sat/vB, want >= 10", rate)
+//	}
+func (r *Regtest) MempoolTxFeeRate(txid *chainhash.Hash) (float64, error) {
+	return r.MempoolTxFeeRateContext(context.Background(), txid)
+}
+
+// MempoolTxFeeRateContext is the context-aware variant of MempoolTxFeeRate.
+func (r *Regtest) MempoolTxFeeRateContext(ctx context.Context, txid *chainhash.Hash) (float64, error) {
+	e, err := r.mempoolEntry(ctx, txid)
+	if err != nil {
+		return 0, err
+	}
+	return feeRateSatVB(e.ModifiedFee, e.VSize), nil
+}
+
+// MempoolTxAncestorFeeRate returns txid's ancestor-package fee rate in
+// sat/vB: the modified fees of the tx and all its in-mempool ancestors
+// divided by their combined vsize. For a CPFP child this is the rate the
+// parent+child package is mined at; for a tx without unconfirmed parents it
+// equals MempoolTxFeeRate.
+//
+// Parameters:
+//   - txid: transaction to look up (must be non-nil and in the mempool)
+//
+// Returns:
+//   - float64: ancestor-package fee rate in sat/vB
+//   - error: validation error for nil txid; errNotConnected before Start;
+//     otherwise wrapped RPC error ("Transaction not in mempool" when absent).
+//
+// Example:
+//
+//	pkg, err := rt.MempoolTxAncestorFeeRate(childTxid)
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Printf("package rate %.2f sat/vB\n", pkg)
+func (r *Regtest) MempoolTxAncestorFeeRate(txid *chainhash.Hash) (float64, error) {
+	return r.MempoolTxAncestorFeeRateContext(context.Background(), txid)
+}
+
+// MempoolTxAncestorFeeRateContext is the context-aware variant of
+// MempoolTxAncestorFeeRate.
+func (r *Regtest) MempoolTxAncestorFeeRateContext(ctx context.Context, txid *chainhash.Hash) (float64, error) {
+	e, err := r.mempoolEntry(ctx, txid)
+	if err != nil {
+		return 0, err
+	}
+	return feeRateSatVB(e.AncestorFee, e.AncestorSize), nil
+}
+
+// mempoolEntry reads txid's getmempoolentry record.
+func (r *Regtest) mempoolEntry(ctx context.Context, txid *chainhash.Hash) (MempoolEntry, error) {
+	if txid == nil {
+		return MempoolEntry{}, fmt.Errorf("txid must not be nil")
+	}
+	resp, err := r.rawRPC(ctx, "getmempoolentry", txid.String())
+	if err != nil {
+		return MempoolEntry{}, fmt.Errorf("getmempoolentry: %w", err)
+	}
+	var raw rawMempoolEntry
+	if err := json.Unmarshal(resp, &raw); err != nil {
+		return MempoolEntry{}, fmt.Errorf("failed to unmarshal getmempoolentry: %w", err)
+	}
+	return raw.toEntry()
+}
+
+// feeRateSatVB divides fee by vsize, returning 0 for a non-positive vsize.
+func feeRateSatVB(fee btcutil.Amount, vsize int64) float64 {
+	if vsize <= 0 {
+		return 0
+	}
+	return float64(fee) / float64(vsize)
+}
+
+// FeeBucket is one fee-rate range of a MempoolFeeHistogram. A transaction
+// falls in the bucket when MinFeeRate <= fee rate < MaxFeeRate.
+type FeeBucket struct {
+	// MinFeeRate is the inclusive lower bound in sat/vB.
+	MinFeeRate float64
+	// MaxFeeRate is the exclusive upper bound in sat/vB; +Inf for the top
+	// bucket.
+	MaxFeeRate float64
+	// Count is the number of transactions in the bucket.
+	Count int
+	// VSize is the aggregate virtual size of those transactions in vbytes.
+	VSize int64
+	// Fee is the aggregate base fee of those transactions.
+	Fee btcutil.Amount
+}
+
+// feeHistogramEdges are the bucket lower bounds in sat/vB. The first bucket
+// starts at 0 so sub-1 sat/vB relay (Core 29+ regtest defaults to 0.1)
+// still lands somewhere; the last bucket is open-ended.
+var feeHistogramEdges = []float64{
+	0, 1, 2, 3, 4, 5, 6, 8, 10, 12, 15, 20, 25, 30, 40, 50, 60, 80,
+	100, 150, 200, 300, 500, 1000,
+}
+
+// MempoolFeeHistogram buckets every mempool transaction by its own fee rate
+// (base fee / vsize, ignoring ancestors and prioritisetransaction deltas)
+// into fixed sat/vB ranges, with the transaction count, aggregate vsize and
+// aggregate fee per bucket. This is the mempool shape a fee estimator sees,
+// so a test can build a known fee market and assert the rate its estimation
+// logic would pick.
+//
+// Bucket lower bounds are 0, 1, 2, 3, 4, 5, 6, 8, 10, 12, 15, 20, 25, 30,
+// 40, 50, 60, 80, 100, 150, 200, 300, 500 and 1000 sat/vB.
+//
+// Returns:
+//   - []FeeBucket: non-empty buckets in ascending fee-rate order; empty
+//     (not nil) for an empty mempool
+//   - error: errNotConnected before Start; otherwise wrapped RPC or
+//     unmarshal error.
+//
+// Example:
+//
+//	hist, err := rt.MempoolFeeHistogram()
+//	if err != nil {
+//	    return err
+//	}
+//	for _, b := range hist {
+//	    fmt.Printf("%g-%g sat/vB: %d txs, %d vB\n", b.MinFeeRate, b.MaxFeeRate, b.Count, b.VSize)
+//	}
+func (r *Regtest) MempoolFeeHistogram() ([]FeeBucket, error) {
+	return r.MempoolFeeHistogramContext(context.Background())
+}
+
+// MempoolFeeHistogramContext is the context-aware variant of
+// MempoolFeeHistogram.
+func (r *Regtest) MempoolFeeHistogramContext(ctx context.Context) ([]FeeBucket, error) {
+	pool, err := r.GetRawMempoolVerboseContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return feeHistogram(pool), nil
+}
+
+// feeHistogram buckets pool by fee rate over feeHistogramEdges, dropping
+// empty buckets. Entries with a non-positive vsize are skipped.
+func feeHistogram(pool map[string]MempoolEntry) []FeeBucket {
+	buckets := make([]FeeBucket, len(feeHistogramEdges))
+	for i, lo := range feeHistogramEdges {
+		buckets[i].MinFeeRate = lo
+		buckets[i].MaxFeeRate = math.Inf(1)
+		if i+1 < len(feeHistogramEdges) {
+			buckets[i].MaxFeeRate = feeHistogramEdges[i+1]
+		}
+	}
+	for _, e := range pool {
+		if e.VSize <= 0 {
+			continue
+		}
+		rate := float64(e.Fee) / float64(e.VSize)
+		// Index of the last edge <= rate.
+		i := sort.Search(len(feeHistogramEdges), func(i int) bool {
+			return feeHistogramEdges[i] > rate
+		}) - 1
+		if i < 0 {
+			i = 0
+		}
+		buckets[i].Count++
+		buckets[i].VSize += e.VSize
+		buckets[i].Fee += e.Fee
+	}
+	out := make([]FeeBucket, 0, len(buckets))
+	for _, b := range buckets {
+		if b.Count > 0 {
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// MempoolMinFee returns the minimum fee rate, per kvB, a transaction needs to
+// enter this node's mempool: getmempoolinfo's mempoolminfee, which is the
+// larger of -minrelaytxfee and the dynamic floor raised when the mempool
+// fills up and starts evicting.
+//
+// Returns:
+//   - btcutil.Amount: minimum fee per 1000 vbytes (1000 sat = 1 sat/vB)
+//   - error: errNotConnected before Start; otherwise wrapped RPC or
+//     unmarshal error.
+//
+// Example:
+//
+//	floor, err := rt.MempoolMinFee()
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Printf("relay floor: %.3f sat/vB\n", float64(floor)/1000)
+func (r *Regtest) MempoolMinFee() (btcutil.Amount, error) {
+	return r.MempoolMinFeeContext(context.Background())
+}
+
+// MempoolMinFeeContext is the context-aware variant of MempoolMinFee.
+func (r *Regtest) MempoolMinFeeContext(ctx context.Context) (btcutil.Amount, error) {
+	resp, err := r.rawRPC(ctx, "getmempoolinfo")
+	if err != nil {
+		return 0, fmt.Errorf("getmempoolinfo: %w", err)
+	}
+	var info struct {
+		MempoolMinFee float64 `json:"mempoolminfee"`
+	}
+	if err := json.Unmarshal(resp, &info); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal getmempoolinfo: %w", err)
+	}
+	amt, err := btcutil.NewAmount(info.MempoolMinFee)
+	if err != nil {
+		return 0, fmt.Errorf("converting mempoolminfee %v: %w", info.MempoolMinFee, err)
+	}
+	return amt, nil
+}
+
+// WouldRelay reports whether tx would be accepted into this node's mempool.
+// The verdict is testmempoolaccept's; alongside it the tx's fee rate is
+// computed locally from its prevouts and compared against MempoolMinFee, so
+// a fee-floor rejection comes back with both rates in the reason instead of
+// just bitcoind's reject string.
+//
+// The local fee-rate check is skipped when a prevout can't be found (already
+// spent, or not yet known to the node); the node's verdict still applies.
+//
+// Parameters:
+//   - tx: signed transaction to check (must be non-nil)
+//
+// Returns:
+//   - bool: true when bitcoind would accept the tx
+//   - string: empty when accepted; otherwise bitcoind's reject reason,
+//     extended with the local and minimum fee rates when the tx pays
+//     below MempoolMinFee
+//   - error: validation error for nil tx; errNotConnected before Start;
+//     otherwise wrapped RPC error.
+//
+// Example:
+//
+//	ok, reason, err := rt.WouldRelay(tx)
+//	if err != nil {
+//	    return err
+//	}
+//	if !ok {
+//	    t.Fatalf("tx would not relay: %s", reason)
+//	}
+func (r *Regtest) WouldRelay(tx *wire.MsgTx) (bool, string, error) {
+	return r.WouldRelayContext(context.Background(), tx)
+}
+
+// WouldRelayContext is the context-aware variant of WouldRelay.
+func (r *Regtest) WouldRelayContext(ctx context.Context, tx *wire.MsgTx) (bool, string, error) {
+	if tx == nil {
+		return false, "", fmt.Errorf("tx must not be nil")
+	}
+	minFee, err := r.MempoolMinFeeContext(ctx)
+	if err != nil {
+		return false, "", err
+	}
+	fee, known, err := r.txFee(ctx, tx)
+	if err != nil {
+		return false, "", err
+	}
+	res, err := r.TestMempoolAcceptContext(ctx, tx)
+	if err != nil {
+		return false, "", err
+	}
+	if res[0].Allowed {
+		return true, "", nil
+	}
+	reason := res[0].RejectReason
+	if known {
+		vsize := txVirtualSize(tx)
+		// fee/vsize < minFee/1000, cross-multiplied to stay in integers.
+		if int64(fee)*1000 < int64(minFee)*vsize {
+			reason = fmt.Sprintf("%s (fee rate %.3f sat/vB below mempool min fee %.3f sat/vB)",
+				reason, float64(fee)/float64(vsize), float64(minFee)/1000)
+		}
+	}
+	return false, reason, nil
+}
+
+// txFee sums tx's prevout values via gettxout (mempool included) and returns
+// the fee. known is false when any prevout can't be found.
+func (r *Regtest) txFee(ctx context.Context, tx *wire.MsgTx) (fee btcutil.Amount, known bool, err error) {
+	var in btcutil.Amount
+	for _, txIn := range tx.TxIn {
+		op := txIn.PreviousOutPoint
+		out, err := r.GetTxOutContext(ctx, &op.Hash, op.Index, true)
+		if err != nil {
+			return 0, false, err
+		}
+		if out == nil {
+			return 0, false, nil
+		}
+		amt, err := btcutil.NewAmount(out.Value)
+		if err != nil {
+			return 0, false, fmt.Errorf("converting prevout value %v: %w", out.Value, err)
+		}
+		in += amt
+	}
+	var outs int64
+	for _, txOut := range tx.TxOut {
+		outs += txOut.Value
+	}
+	return in - btcutil.Amount(outs), true, nil
+}
+
+// txVirtualSize is VirtualSize as an int64, matching the mempool types.
+func txVirtualSize(tx *wire.MsgTx) int64 {
+	return int64(VirtualSize(tx))
+}
+
+// SaveMempool dumps the mempool to mempool.dat in the node's data directory
+// via savemempool. The file format follows Config.PersistMempoolV1: the
+// obfuscated v2 format by default on Core 26+, v1 when the flag is set.
+//
+// Returns:
+//   - string: absolute path of the written file (empty on nodes older than
+//     Core 23, which don't report it)
+//   - error: errNotConnected before Start; otherwise wrapped RPC error.
+//
+// Example:
+//
+//	path, err := rt.SaveMempool()
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Println("mempool saved to", path)
+func (r *Regtest) SaveMempool() (string, error) {
+	return r.SaveMempoolContext(context.Background())
+}
+
+// SaveMempoolContext is the context-aware variant of SaveMempool.
+func (r *Regtest) SaveMempoolContext(ctx context.Context) (string, error) {
+	resp, err := r.rawRPC(ctx, "savemempool")
+	if err != nil {
+		return "", fmt.Errorf("savemempool: %w", err)
+	}
+	var res struct {
+		Filename string `json:"filename"`
+	}
+	// Pre-23 nodes return null, which leaves Filename empty.
+	if err := json.Unmarshal(resp, &res); err != nil {
+		return "", fmt.Errorf("failed to unmarshal savemempool: %w", err)
+	}
+	return res.Filename, nil
+}
+
+// LoadMempool imports the transactions from a mempool.dat file — e.g. one
+// written by SaveMempool on this or another node — via importmempool. Both
+// the v1 and v2 formats are accepted regardless of Config.PersistMempoolV1.
+// Transactions that are invalid against the current chain are skipped by
+// bitcoind, as on a normal startup load.
+//
+// importmempool exists from Bitcoin Core 27; on older nodes this returns an
+// error wrapping ErrUnsupportedVersion without calling it.
+//
+// Parameters:
+//   - path: mempool.dat to import (must be non-empty; absolute, or
+//     relative to bitcoind's working directory)
+//
+// Returns:
+//   - error: validation error for an empty path; errNotConnected before
+//     Start; ErrUnsupportedVersion (wrapped) before Core 27; otherwise
+//     wrapped RPC error (e.g. the file can't be read).
+//
+// Example:
+//
+//	path, _ := node1.SaveMempool()
+//	if err := node2.LoadMempool(path); err != nil {
+//	    return err
+//	}
+func (r *Regtest) LoadMempool(path string) error {
+	return r.LoadMempoolContext(context.Background(), path)
+}
+
+// LoadMempoolContext is the context-aware variant of LoadMempool.
+func (r *Regtest) LoadMempoolContext(ctx context.Context, path string) error {
+	if path == "" {
+		return fmt.Errorf("path must not be empty")
+	}
+	if err := r.requireVersion(ctx, 270000, "importmempool"); err != nil {
+		return err
+	}
+	if _, err := r.rawRPC(ctx, "importmempool", path); err != nil {
+		return fmt.Errorf("importmempool %s: %w", path, err)
+	}
+	return nil
+}
+
+// PrioritisedTransaction is one entry of getprioritisedtransactions.
+type PrioritisedTransaction struct {
+	// FeeDelta is the fee delta applied via prioritisetransaction.
+	FeeDelta btcutil.Amount
+	// InMempool reports whether the transaction is currently in the mempool.
+	InMempool bool
+	// ModifiedFee is the base fee plus FeeDelta; zero when the transaction
+	// isn't in the mempool.
+	ModifiedFee btcutil.Amount
+}
+
+// PrioritiseTransaction adds feeDeltaSats to the fee the node's mining and
+// eviction logic credits txid with, via prioritisetransaction. The real fee
+// paid is unchanged; only block-template selection (getblocktemplate, and so
+// Warp) and mempool ordering see the modified fee, so a low-fee transaction
+// bumped this way is mined ahead of better-paying ones. Deltas accumulate
+// across calls and may be negative. txid doesn't have to be in the mempool
+// yet; the delta is applied when it arrives.
+//
+// Parameters:
+//   - txid: transaction to prioritise (must be non-nil)
+//   - feeDeltaSats: fee delta in satoshis (must be non-zero)
+//
+// Returns:
+//   - bool: bitcoind's result (always true on success)
+//   - error: validation error for a nil txid or zero delta; errNotConnected
+//     before Start; otherwise wrapped RPC error.
+//
+// Example:
+//
+//	if _, err := rt.PrioritiseTransaction(lowFeeTxid, 100_000); err != nil {
+//	    return err
+//	}
+//	_ = rt.Warp(1, miner) // lowFeeTxid is now in the block
+func (r *Regtest) PrioritiseTransaction(txid *chainhash.Hash, feeDeltaSats int64) (bool, error) {
+	return r.PrioritiseTransactionContext(context.Background(), txid, feeDeltaSats)
+}
+
+// PrioritiseTransactionContext is the context-aware variant of
+// PrioritiseTransaction.
+func (r *Regtest) PrioritiseTransactionContext(ctx context.Context, txid *chainhash.Hash, feeDeltaSats int64) (bool, error) {
+	if txid == nil {
+		return false, fmt.Errorf("txid must not be nil")
+	}
+	if feeDeltaSats == 0 {
+		return false, fmt.Errorf("fee delta must be non-zero")
+	}
+	// The second argument is a dummy that must be 0 (or null).
+	resp, err := r.rawRPC(ctx, "prioritisetransaction", txid.String(), 0, feeDeltaSats)
+	if err != nil {
+		return false, fmt.Errorf("prioritisetransaction %s: %w", txid, err)
+	}
+	var ok bool
+	if err := json.Unmarshal(resp, &ok); err != nil {
+		return false, fmt.Errorf("failed to unmarshal prioritisetransaction: %w", err)
+	}
+	return ok, nil
+}
+
+// GetPrioritisedTransactions returns every fee delta set with
+// PrioritiseTransaction, keyed by txid, via getprioritisedtransactions.
+// Entries persist until the transaction is mined or the delta is cancelled
+// out.
+//
+// getprioritisedtransactions exists from Bitcoin Core 26; on older nodes
+// this returns an error wrapping ErrUnsupportedVersion without calling it.
+//
+// Returns:
+//   - map[string]PrioritisedTransaction: deltas keyed by txid (empty, not
+//     nil, when there are none)
+//   - error: errNotConnected before Start; ErrUnsupportedVersion (wrapped)
+//     before Core 26; otherwise wrapped RPC error.
+//
+// Example:
+//
+//	deltas, err := rt.GetPrioritisedTransactions()
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Println("delta:", deltas[txid.String()].FeeDelta)
+func (r *Regtest) GetPrioritisedTransactions() (map[string]PrioritisedTransaction, error) {
+	return r.GetPrioritisedTransactionsContext(context.Background())
+}
+
+// GetPrioritisedTransactionsContext is the context-aware variant of
+// GetPrioritisedTransactions.
+func (r *Regtest) GetPrioritisedTransactionsContext(ctx context.Context) (map[string]PrioritisedTransaction, error) {
+	if err := r.requireVersion(ctx, 260000, "getprioritisedtransactions"); err != nil {
+		return nil, err
+	}
+	resp, err := r.rawRPC(ctx, "getprioritisedtransactions")
+	if err != nil {
+		return nil, fmt.Errorf("getprioritisedtransactions: %w", err)
+	}
+	var raw map[string]struct {
+		FeeDelta    int64 `json:"fee_delta"`
+		InMempool   bool  `json:"in_mempool"`
+		ModifiedFee int64 `json:"modified_fee"`
+	}
+	if err := json.Unmarshal(resp, &raw); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal getprioritisedtransactions: %w", err)
+	}
+	out := make(map[string]PrioritisedTransaction, len(raw))
+	for txid, e := range raw {
+		out[txid] = PrioritisedTransaction{
+			FeeDelta:    btcutil.Amount(e.FeeDelta),
+			InMempool:   e.InMempool,
+			ModifiedFee: btcutil.Amount(e.ModifiedFee),
+		}
+	}
+	return out, nil
+}
@@ -1,12 +1,21 @@
 package regtest
 
 import (
+	"bytes"
 	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
+	"github.com/btcsuite/btcd/btcjson"
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
 )
 
 // Warp advances the blockchain by mining the specified number of blocks.
@@ -18,7 +27,8 @@ import (
 //   - miner: Bitcoin address to receive the block rewards (must be valid)
 //
 // Returns:
-//   - error: Error if parameters are invalid or mining fails
+//   - error: Error if parameters are invalid or mining fails;
+//     ErrChainFrozen while the chain is frozen (see FreezeChain)
 //
 // This function is useful for:
 //   - Testing applications that depend on block confirmations
@@ -40,30 +50,252 @@ func (r *Regtest) Warp(blocks int64, miner string) error {
 
 // WarpContext is the context-aware variant of Warp.
 func (r *Regtest) WarpContext(ctx context.Context, blocks int64, miner string) error {
+	_, err := r.warp(ctx, blocks, miner)
+	return err
+}
+
+// WarpBlocks is Warp that also returns the hashes of the mined blocks, in
+// mining order, for reorg and confirmation tests that need to refer back to
+// specific blocks (InvalidateBlock, GetBlock, ...).
+//
+// Parameters:
+//   - blocks: number of blocks to mine (must be > 0)
+//   - miner: address receiving the block rewards (must be non-empty)
+//
+// Returns:
+//   - []*chainhash.Hash: one hash per mined block; the last is the new tip
+//   - error: as for Warp.
+//
+// Example:
+//
+//	hashes, err := rt.WarpBlocks(3, addr)
+//	if err != nil {
+//	    return err
+//	}
+//	// Orphan the last two blocks.
+//	err = rt.InvalidateBlock(hashes[1])
+func (r *Regtest) WarpBlocks(blocks int64, miner string) ([]*chainhash.Hash, error) {
+	return r.WarpBlocksContext(context.Background(), blocks, miner)
+}
+
+// WarpBlocksContext is the context-aware variant of WarpBlocks.
+func (r *Regtest) WarpBlocksContext(ctx context.Context, blocks int64, miner string) ([]*chainhash.Hash, error) {
+	return r.warp(ctx, blocks, miner)
+}
+
+// WarpToWallet is WarpBlocks paying the block rewards to a fresh address of
+// walletName, which is created or loaded first — the usual "mature coins in
+// wallet X" setup without fetching an address by hand.
+//
+// Parameters:
+//   - blocks: number of blocks to mine (must be > 0)
+//   - walletName: wallet receiving the block rewards (must be non-empty)
+//
+// Returns:
+//   - []*chainhash.Hash: one hash per mined block; the last is the new tip
+//   - error: as for Warp, or failure to load the wallet or get an address.
+//
+// Example:
+//
+//	// 101 blocks: the first coinbase is spendable.
+//	if _, err := rt.WarpToWallet(101, "alice"); err != nil {
+//	    return err
+//	}
+func (r *Regtest) WarpToWallet(blocks int64, walletName string) ([]*chainhash.Hash, error) {
+	return r.WarpToWalletContext(context.Background(), blocks, walletName)
+}
+
+// WarpToWalletContext is the context-aware variant of WarpToWallet.
+func (r *Regtest) WarpToWalletContext(ctx context.Context, blocks int64, walletName string) ([]*chainhash.Hash, error) {
 	if blocks <= 0 {
-		return fmt.Errorf("blocks must be greater than 0, got %d", blocks)
+		return nil, fmt.Errorf("blocks must be greater than 0, got %d", blocks)
+	}
+	if walletName == "" {
+		return nil, fmt.Errorf("wallet name must be provided")
+	}
+	if err := r.EnsureWalletContext(ctx, walletName); err != nil {
+		return nil, err
+	}
+	addr, err := r.walletNewAddress(ctx, walletName)
+	if err != nil {
+		return nil, err
+	}
+	return r.warp(ctx, blocks, addr)
+}
+
+// warp implements WarpContext, returning the mined block hashes in order.
+func (r *Regtest) warp(ctx context.Context, blocks int64, miner string) ([]*chainhash.Hash, error) {
+	if blocks <= 0 {
+		return nil, fmt.Errorf("blocks must be greater than 0, got %d", blocks)
 	}
 	if miner == "" {
-		return fmt.Errorf("miner must be provided")
+		return nil, fmt.Errorf("miner must be provided")
+	}
+	if r.frozen.Load() {
+		return nil, ErrChainFrozen
 	}
 
 	addr, err := btcutil.DecodeAddress(miner, &chaincfg.RegressionNetParams)
 	if err != nil {
-		return fmt.Errorf("failed to decode miner address: %w", err)
+		return nil, fmt.Errorf("failed to decode miner address: %w", err)
 	}
 
 	client, err := r.lockedClient()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = runWithContext(ctx, func() ([]*chainhash.Hash, error) {
-		return client.GenerateToAddress(blocks, addr, nil)
+	// The cache is settled inside fn, so a cancelled call that goes on
+	// mining in the background still counts its blocks when it finishes.
+	r.beginMining()
+	hashes, err := runWithContext(ctx, func() ([]*chainhash.Hash, error) {
+		hashes, err := client.GenerateToAddress(blocks, addr, nil)
+		if err != nil {
+			r.abortMining()
+		} else {
+			r.endMining(int64(len(hashes)))
+		}
+		return hashes, err
 	})
 	if err != nil {
-		return fmt.Errorf("failed to generate blocks: %w", err)
+		// A cancelled call may still mine; the count is unknown.
+		r.InvalidateHeightCache()
+		return nil, fmt.Errorf("failed to generate blocks: %w", err)
 	}
-	return nil
+	return hashes, nil
+}
+
+// CoinbaseOutput is one coinbase output created by WarpDetailed.
+type CoinbaseOutput struct {
+	// BlockHash is the block whose coinbase created the output.
+	BlockHash chainhash.Hash
+	// Height is that block's height.
+	Height int64
+	// OutPoint is the output: the coinbase txid and the index of the
+	// output paying the miner address.
+	OutPoint wire.OutPoint
+	// Amount is the output's value (subsidy plus any fees).
+	Amount btcutil.Amount
+	// Mature reports whether the output had the 100 confirmations needed
+	// to be spent in the next block when WarpDetailed returned.
+	Mature bool
+}
+
+// WarpDetailed is Warp that also reports the coinbase outputs it created,
+// one per mined block in mining order, so funding scenarios don't have to
+// walk the new blocks to find them. An output is mature once it has 100
+// confirmations, i.e. it can be spent in the next block: after a 101-block
+// warp on an empty chain the first two are. Mine further blocks (to any
+// address) to mature the rest.
+//
+// Parameters:
+//   - blocks: number of blocks to mine (must be > 0)
+//   - miner: address receiving the block rewards
+//
+// Returns:
+//   - []CoinbaseOutput: the output paying miner in each mined block
+//   - error: as for Warp; otherwise wrapped block lookup error.
+//
+// Example:
+//
+//	outs, err := rt.WarpDetailed(101, addr)
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Println(outs[0].OutPoint, outs[0].Amount, outs[0].Mature) // ... 50 BTC true
+func (r *Regtest) WarpDetailed(blocks int64, miner string) ([]CoinbaseOutput, error) {
+	return r.WarpDetailedContext(context.Background(), blocks, miner)
+}
+
+// WarpDetailedContext is the context-aware variant of WarpDetailed.
+func (r *Regtest) WarpDetailedContext(ctx context.Context, blocks int64, miner string) ([]CoinbaseOutput, error) {
+	hashes, err := r.warp(ctx, blocks, miner)
+	if err != nil {
+		return nil, err
+	}
+	script, err := ScriptForAddress(miner)
+	if err != nil {
+		return nil, err
+	}
+	outs := make([]CoinbaseOutput, 0, len(hashes))
+	for _, hash := range hashes {
+		out, err := r.coinbaseOutput(ctx, hash, script)
+		if err != nil {
+			return nil, err
+		}
+		outs = append(outs, out)
+	}
+	return outs, nil
+}
+
+// coinbaseOutput describes the output of block hash's coinbase paying
+// script.
+func (r *Regtest) coinbaseOutput(ctx context.Context, hash *chainhash.Hash, script []byte) (CoinbaseOutput, error) {
+	resp, err := r.rawRPC(ctx, "getblockheader", hash.String(), true)
+	if err != nil {
+		return CoinbaseOutput{}, fmt.Errorf("getblockheader %s: %w", hash, err)
+	}
+	var header struct {
+		Height        int64 `json:"height"`
+		Confirmations int64 `json:"confirmations"`
+	}
+	if err := json.Unmarshal(resp, &header); err != nil {
+		return CoinbaseOutput{}, fmt.Errorf("unmarshal getblockheader: %w", err)
+	}
+	block, err := r.GetBlockContext(ctx, hash)
+	if err != nil {
+		return CoinbaseOutput{}, err
+	}
+	coinbase := block.Transactions[0]
+	for i := uint32(0); int(i) < len(coinbase.TxOut); i++ {
+		txOut := coinbase.TxOut[i]
+		if !bytes.Equal(txOut.PkScript, script) {
+			continue
+		}
+		return CoinbaseOutput{
+			BlockHash: *hash,
+			Height:    header.Height,
+			OutPoint:  wire.OutPoint{Hash: coinbase.TxHash(), Index: i},
+			Amount:    btcutil.Amount(txOut.Value),
+			Mature:    header.Confirmations >= coinbaseMaturity,
+		}, nil
+	}
+	return CoinbaseOutput{}, fmt.Errorf("coinbase of block %s has no output to the miner script", hash)
+}
+
+// ErrChainFrozen is returned by the library's block-producing methods
+// (Warp and everything built on it, WarpWithCoinbaseData,
+// WarpWithTransactions, SubmitBlock) while the chain is frozen with
+// FreezeChain.
+var ErrChainFrozen = errors.New("chain is frozen")
+
+// FreezeChain stops this instance from producing blocks: until
+// UnfreezeChain, Warp, the helpers built on it (MineToHeight, StressMine,
+// MineUntilActive, ...), WarpWithCoinbaseData, WarpWithTransactions,
+// SubmitBlock and ReplayRPC return ErrChainFrozen without mining. Use it to hold the
+// height steady across an assertion window while other goroutines share the
+// instance. Blocks mined through Client(), bitcoin-cli or a connected peer
+// are not stopped. The flag survives Stop/Start. Safe for concurrent use.
+//
+// Example:
+//
+//	rt.FreezeChain()
+//	defer rt.UnfreezeChain()
+//	h1, _ := rt.GetBlockCount()
+//	// ... assertions that must see a stable tip ...
+func (r *Regtest) FreezeChain() {
+	r.frozen.Store(true)
+}
+
+// UnfreezeChain lifts a FreezeChain so block production works again. A no-op
+// when the chain isn't frozen.
+func (r *Regtest) UnfreezeChain() {
+	r.frozen.Store(false)
+}
+
+// IsChainFrozen reports whether FreezeChain is in effect.
+func (r *Regtest) IsChainFrozen() bool {
+	return r.frozen.Load()
 }
 
 // MineToHeight advances the chain to a specific block height. It reads the
@@ -105,6 +337,166 @@ func (r *Regtest) MineToHeightContext(ctx context.Context, target int64, miner s
 	return r.WarpContext(ctx, delta, miner)
 }
 
+// MineToMaturity mines coinbaseMaturity+1 (101) blocks to miner in one
+// generatetoaddress call, so the first of them has the 101 confirmations the
+// wallet wants before it will spend a coinbase — the step after which
+// SendToAddress and friends have funds. Earlier blocks don't help: their
+// coinbases pay someone else, so the count is the same at any height.
+//
+// Parameters:
+//   - miner: address receiving the block rewards (must be non-empty)
+//
+// Returns:
+//   - error: as for Warp.
+//
+// Example:
+//
+//	addr, _ := rt.GenerateBech32("miner")
+//	if err := rt.MineToMaturity(addr); err != nil {
+//	    return err
+//	}
+//	// One 50 BTC coinbase is now spendable.
+func (r *Regtest) MineToMaturity(miner string) error {
+	return r.MineToMaturityContext(context.Background(), miner)
+}
+
+// MineToMaturityContext is the context-aware variant of MineToMaturity.
+func (r *Regtest) MineToMaturityContext(ctx context.Context, miner string) error {
+	_, err := r.warp(ctx, coinbaseMaturity+1, miner)
+	return err
+}
+
+// WaitForHeight brings the node to at least height target. With a miner it
+// is active: it mines the shortfall to miner, as MineToHeight does. With
+// miner == "" it is passive: it polls getblockcount at ~100ms intervals
+// until another party (a goroutine calling Warp, a syncing peer, ...)
+// reaches target or ctx expires. The passive poll bypasses
+// Config.CacheHeight, so blocks relayed from peers are seen too.
+//
+// Parameters:
+//   - ctx: bounds the wait or the mining; use context.WithTimeout to cap it.
+//   - target: height to reach (must be >= 0)
+//   - miner: address to mine the shortfall to; "" waits instead
+//
+// Returns:
+//   - error: validation error for a negative target; errNotConnected
+//     before Start; wrapped RPC error; when passive, on expiry an error
+//     wrapping ctx.Err() that names the last height seen.
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+//	defer cancel()
+//	if err := rt2.WaitForHeight(ctx, 110, ""); err != nil { // rt1 mines
+//	    t.Fatalf("rt2 did not sync: %v", err)
+//	}
+func (r *Regtest) WaitForHeight(ctx context.Context, target int64, miner string) error {
+	if target < 0 {
+		return fmt.Errorf("target must be >= 0, got %d", target)
+	}
+	if miner != "" {
+		return r.MineToHeightContext(ctx, target, miner)
+	}
+	const interval = 100 * time.Millisecond
+	for {
+		gen := r.heightGeneration()
+		raw, err := r.rawRPC(ctx, "getblockcount")
+		if err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("waiting for height %d: %w", target, ctx.Err())
+			}
+			return fmt.Errorf("getblockcount: %w", err)
+		}
+		var height int64
+		if err := json.Unmarshal(raw, &height); err != nil {
+			return fmt.Errorf("unmarshal getblockcount: %w", err)
+		}
+		r.storeHeight(height, gen)
+		if height >= target {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for height %d (last seen %d): %w", target, height, ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+}
+
+// StressMine hammers the node with goroutines concurrent Warp calls of
+// blocksEach blocks apiece, then checks the chain grew by exactly
+// goroutines*blocksEach. bitcoind serializes the generatetoaddress calls, so
+// any discrepancy points at lost or double-counted blocks. With
+// Config.CacheHeight on it also checks the cached height kept up with the
+// concurrent Warps before re-reading the height from bitcoind.
+//
+// Parameters:
+//   - ctx: context for cancellation; cancelling mid-run fails with the
+//     height left wherever bitcoind got to
+//   - goroutines: number of concurrent Warp callers (must be > 0)
+//   - blocksEach: blocks mined by each caller (must be > 0)
+//   - miner: Bitcoin address to receive coinbase rewards
+//
+// Returns:
+//   - error: validation error for non-positive counts or empty miner;
+//     errNotConnected before Start; the joined Warp errors if any call
+//     failed; otherwise a descriptive error if the final height is off.
+//
+// Example:
+//
+//	if err := rt.StressMine(ctx, 8, 25, addr); err != nil {
+//	    t.Fatal(err)
+//	}
+func (r *Regtest) StressMine(ctx context.Context, goroutines int, blocksEach int64, miner string) error {
+	if goroutines <= 0 {
+		return fmt.Errorf("goroutines must be greater than 0, got %d", goroutines)
+	}
+	if blocksEach <= 0 {
+		return fmt.Errorf("blocksEach must be greater than 0, got %d", blocksEach)
+	}
+	if miner == "" {
+		return fmt.Errorf("miner must be provided")
+	}
+
+	r.InvalidateHeightCache()
+	start, err := r.GetBlockCountContext(ctx)
+	if err != nil {
+		return fmt.Errorf("get starting height: %w", err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, goroutines)
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := r.WarpContext(ctx, blocksEach, miner); err != nil {
+				errs[i] = fmt.Errorf("goroutine %d: %w", i, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	want := start + int64(goroutines)*blocksEach
+	if h, ok := r.cachedHeight(); ok && h != want {
+		return fmt.Errorf("cached height %d after %d×%d blocks from %d, want %d",
+			h, goroutines, blocksEach, start, want)
+	}
+	r.InvalidateHeightCache()
+	got, err := r.GetBlockCountContext(ctx)
+	if err != nil {
+		return fmt.Errorf("get final height: %w", err)
+	}
+	if got != want {
+		return fmt.Errorf("height %d after %d×%d blocks from %d, want %d",
+			got, goroutines, blocksEach, start, want)
+	}
+	return nil
+}
+
 // MineUntilActive mines blocks one retarget window at a time until the
 // named BIP9 deployment reaches SoftForkActive. Returns the number of
 // blocks mined. Polls DeploymentStatus after each window so the BIP9
@@ -195,3 +587,161 @@ func (r *Regtest) MineUntilActiveContext(ctx context.Context, deployment, miner
 	}
 	return mined, fmt.Errorf("deployment %q did not reach Active within %d blocks (final status: %s)", deployment, maxBlocks, final)
 }
+
+// WarpWithCoinbaseData mines a single block paying the subsidy and fees to
+// miner, with data embedded in the coinbase scriptSig right after the BIP34
+// height push. Unlike Warp, which leaves coinbase construction to
+// generatetoaddress, the block is assembled locally from getblocktemplate
+// (including the template's mempool transactions) and handed to
+// submitblock — the path needed for tests asserting pool tags or other
+// coinbase commitments.
+//
+// Parameters:
+//   - miner: address receiving the coinbase output (must be valid)
+//   - data: bytes to push into the coinbase scriptSig. The whole scriptSig
+//     (height push + data push) must fit the 100-byte consensus limit, which
+//     leaves room for 95 bytes of data at typical regtest heights. Checked
+//     against the template height before anything is submitted.
+//
+// Returns:
+//   - *chainhash.Hash: hash of the mined block
+//   - error: validation error for a bad address or oversized data;
+//     errNotConnected before Start; otherwise wrapped RPC error, including
+//     bitcoind's submitblock reject reason.
+//
+// Example:
+//
+//	hash, err := rt.WarpWithCoinbaseData(miner, []byte("/my-pool/"))
+//	if err != nil {
+//	    return err
+//	}
+//	block, _ := rt.GetBlock(hash)
+//	fmt.Printf("%x\n", block.Transactions[0].TxIn[0].SignatureScript)
+func (r *Regtest) WarpWithCoinbaseData(miner string, data []byte) (*chainhash.Hash, error) {
+	return r.WarpWithCoinbaseDataContext(context.Background(), miner, data)
+}
+
+// WarpWithCoinbaseDataContext is the context-aware variant of
+// WarpWithCoinbaseData.
+func (r *Regtest) WarpWithCoinbaseDataContext(ctx context.Context, miner string, data []byte) (*chainhash.Hash, error) {
+	if miner == "" {
+		return nil, fmt.Errorf("miner must be provided")
+	}
+	if r.frozen.Load() {
+		return nil, ErrChainFrozen
+	}
+	addr, err := btcutil.DecodeAddress(miner, &chaincfg.RegressionNetParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode miner address: %w", err)
+	}
+	payout, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build miner script: %w", err)
+	}
+	tmpl, err := r.GetBlockTemplateContext(ctx, &btcjson.TemplateRequest{
+		Mode:  "template",
+		Rules: []string{"segwit"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if tmpl.CoinbaseValue == nil {
+		return nil, fmt.Errorf("getblocktemplate: missing coinbasevalue")
+	}
+	scriptSig, err := coinbaseScriptSig(tmpl.Height, data)
+	if err != nil {
+		return nil, err
+	}
+
+	txs := make([]*wire.MsgTx, 0, len(tmpl.Transactions))
+	for i, t := range tmpl.Transactions {
+		raw, err := hex.DecodeString(t.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode template tx %d: %w", i, err)
+		}
+		var tx wire.MsgTx
+		if err := tx.Deserialize(bytes.NewReader(raw)); err != nil {
+			return nil, fmt.Errorf("failed to deserialize template tx %d: %w", i, err)
+		}
+		txs = append(txs, &tx)
+	}
+
+	block, err := assembleBlock(tmpl, scriptSig, payout, *tmpl.CoinbaseValue, txs)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.SubmitBlockContext(ctx, block); err != nil {
+		return nil, err
+	}
+	hash := block.BlockHash()
+	return &hash, nil
+}
+
+// WarpWithTransactions mines a single block to miner containing exactly txs,
+// in order, via generateblock. The transactions skip mempool acceptance
+// entirely, so a transaction that is consensus-valid but policy-rejected
+// (non-standard script, dust, too-low fee, ...) still gets mined, while a
+// consensus-invalid one makes the block fail validation. That separates
+// "wouldn't relay" from "block invalid", which BroadcastTransaction + Warp
+// can't express. Mempool transactions are not included.
+//
+// Parameters:
+//   - miner: address receiving the coinbase output (must be valid)
+//   - txs: transactions to include after the coinbase; none may be nil. An
+//     empty slice mines an empty block.
+//
+// Returns:
+//   - *chainhash.Hash: hash of the mined block
+//   - error: validation error for a bad address or nil tx; ErrChainFrozen
+//     while the chain is frozen; errNotConnected before Start; otherwise
+//     wrapped RPC error, including bitcoind's block-validity reject reason.
+//
+// Example:
+//
+//	// nonStd is signed and consensus-valid but fails IsStandard.
+//	hash, err := rt.WarpWithTransactions(miner, []*wire.MsgTx{nonStd})
+//	if err != nil {
+//	    return fmt.Errorf("consensus rejected: %w", err)
+//	}
+func (r *Regtest) WarpWithTransactions(miner string, txs []*wire.MsgTx) (*chainhash.Hash, error) {
+	return r.WarpWithTransactionsContext(context.Background(), miner, txs)
+}
+
+// WarpWithTransactionsContext is the context-aware variant of
+// WarpWithTransactions.
+func (r *Regtest) WarpWithTransactionsContext(ctx context.Context, miner string, txs []*wire.MsgTx) (*chainhash.Hash, error) {
+	if miner == "" {
+		return nil, fmt.Errorf("miner must be provided")
+	}
+	if _, err := btcutil.DecodeAddress(miner, &chaincfg.RegressionNetParams); err != nil {
+		return nil, fmt.Errorf("failed to decode miner address: %w", err)
+	}
+	rawTxs := make([]string, 0, len(txs))
+	for i, tx := range txs {
+		if tx == nil {
+			return nil, fmt.Errorf("txs[%d] must not be nil", i)
+		}
+		var buf bytes.Buffer
+		if err := tx.Serialize(&buf); err != nil {
+			return nil, fmt.Errorf("failed to serialize txs[%d]: %w", i, err)
+		}
+		rawTxs = append(rawTxs, hex.EncodeToString(buf.Bytes()))
+	}
+	if r.frozen.Load() {
+		return nil, ErrChainFrozen
+	}
+
+	r.beginMining()
+	resp, err := r.rawRPC(ctx, "generateblock", miner, rawTxs)
+	r.abortMining()
+	if err != nil {
+		return nil, fmt.Errorf("generateblock: %w", err)
+	}
+	var res struct {
+		Hash string `json:"hash"`
+	}
+	if err := json.Unmarshal(resp, &res); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal generateblock: %w", err)
+	}
+	return chainhash.NewHashFromStr(res.Hash)
+}
@@ -0,0 +1,105 @@
+package regtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/btcsuite/btcd/wire"
+)
+
+// CreateMultisigResult is the result of createmultisig. Unlike
+// btcjson.CreateMultiSigResult it carries the output descriptor that Bitcoin
+// Core 0.20+ reports.
+type CreateMultisigResult struct {
+	// Address is the multisig address.
+	Address string `json:"address"`
+	// RedeemScript is the hex-encoded multisig script: the P2SH redeem
+	// script, or the P2WSH witness script for bech32 and p2sh-segwit.
+	RedeemScript string `json:"redeemScript"`
+	// Descriptor is the output descriptor for the address, with checksum.
+	Descriptor string `json:"descriptor"`
+}
+
+// CreateMultisig builds an nRequired-of-len(pubKeys) multisig address via
+// createmultisig. No wallet is involved: the keys only need to be valid
+// hex public keys, so a test can hold the private keys itself and sign with
+// SignRawTransactionWithKey.
+//
+// Parameters:
+//   - nRequired: signatures needed to spend (1 <= nRequired <= len(pubKeys))
+//   - pubKeys: hex-encoded public keys (at least one)
+//   - addrType: "legacy", "p2sh-segwit" or "bech32"; empty for bitcoind's
+//     default (legacy)
+//
+// Returns:
+//   - *CreateMultisigResult: address, redeem/witness script and descriptor
+//   - error: validation error for bad nRequired or no keys; errNotConnected
+//     before Start; otherwise wrapped RPC error (e.g. invalid public key).
+//
+// Example:
+//
+//	ms, err := rt.CreateMultisig(2, []string{pubA, pubB, pubC}, "bech32")
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Println(ms.Address, ms.Descriptor)
+func (r *Regtest) CreateMultisig(nRequired int, pubKeys []string, addrType string) (*CreateMultisigResult, error) {
+	return r.CreateMultisigContext(context.Background(), nRequired, pubKeys, addrType)
+}
+
+// CreateMultisigContext is the context-aware variant of CreateMultisig.
+func (r *Regtest) CreateMultisigContext(ctx context.Context, nRequired int, pubKeys []string, addrType string) (*CreateMultisigResult, error) {
+	if len(pubKeys) == 0 {
+		return nil, fmt.Errorf("at least one public key required")
+	}
+	if nRequired < 1 || nRequired > len(pubKeys) {
+		return nil, fmt.Errorf("nRequired must be between 1 and %d, got %d", len(pubKeys), nRequired)
+	}
+	args := []any{nRequired, pubKeys}
+	if addrType != "" {
+		args = append(args, addrType)
+	}
+	raw, err := r.rawRPC(ctx, "createmultisig", args...)
+	if err != nil {
+		return nil, fmt.Errorf("createmultisig: %w", err)
+	}
+	var res CreateMultisigResult
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal createmultisig: %w", err)
+	}
+	return &res, nil
+}
+
+// FundMultisig sends sats from the loaded wallet to the multisig address,
+// mines one block to miner to confirm it, and returns the funded outpoint —
+// ready to be spent in a multisig signing test.
+//
+// Parameters:
+//   - ms: multisig from CreateMultisig (must be non-nil)
+//   - sats: amount to send in satoshis (must be > 0)
+//   - miner: address receiving the confirming block's reward
+//
+// Returns:
+//   - *wire.OutPoint: the confirmed output paying the multisig
+//   - error: validation error for nil ms or non-positive sats;
+//     ErrChainFrozen while the chain is frozen; errNotConnected before
+//     Start; otherwise wrapped send, mining or lookup error.
+//
+// Example:
+//
+//	op, err := rt.FundMultisig(ms, 1_000_000, minerAddr)
+//	if err != nil {
+//	    return err
+//	}
+func (r *Regtest) FundMultisig(ms *CreateMultisigResult, sats int64, miner string) (*wire.OutPoint, error) {
+	return r.FundMultisigContext(context.Background(), ms, sats, miner)
+}
+
+// FundMultisigContext is the context-aware variant of FundMultisig.
+func (r *Regtest) FundMultisigContext(ctx context.Context, ms *CreateMultisigResult, sats int64, miner string) (*wire.OutPoint, error) {
+	if ms == nil {
+		return nil, fmt.Errorf("multisig must not be nil")
+	}
+	return r.sendAndConfirm(ctx, ms.Address, sats, miner)
+}
@@ -0,0 +1,85 @@
+package regtest
+
+import (
+	"context"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// Node is the core method set of *Regtest as an interface, so code that
+// drives a regtest node can accept a Node and be unit-tested against a mock
+// without a bitcoind binary. It covers lifecycle, chain queries, mining,
+// wallets and transaction handling; the long tail of specialised helpers
+// (soft-fork registry, PSBT, multisig, peers, ...) stays on the concrete
+// type. Code that needs those can type-assert to *Regtest or define a
+// narrower interface of its own.
+//
+// Methods may be added to Node in minor releases as the core surface grows,
+// so mocks should embed Node (leaving unneeded methods nil) rather than
+// implement it exhaustively.
+//
+// Example:
+//
+//	type fakeNode struct {
+//	    regtest.Node
+//	    height int64
+//	}
+//
+//	func (f *fakeNode) GetBlockCount() (int64, error) { return f.height, nil }
+//
+//	func TestMyCode(t *testing.T) {
+//	    if err := myCode(&fakeNode{height: 101}); err != nil {
+//	        t.Fatal(err)
+//	    }
+//	}
+type Node interface {
+	Start() error
+	StartContext(ctx context.Context) error
+	Stop() error
+	IsRunning() (bool, error)
+	HealthCheck() error
+
+	GetBlockCount() (int64, error)
+	GetBlockCountContext(ctx context.Context) (int64, error)
+	GetBestBlockHash() (*chainhash.Hash, error)
+	GetBestBlockHashContext(ctx context.Context) (*chainhash.Hash, error)
+	GetBlockHash(height int64) (*chainhash.Hash, error)
+	GetBlockHashContext(ctx context.Context, height int64) (*chainhash.Hash, error)
+	GetBlock(hash *chainhash.Hash) (*wire.MsgBlock, error)
+	GetBlockContext(ctx context.Context, hash *chainhash.Hash) (*wire.MsgBlock, error)
+	GetBlockChainInfo() (*BlockChainInfo, error)
+	GetBlockChainInfoContext(ctx context.Context) (*BlockChainInfo, error)
+	GetMedianTimePast() (int64, error)
+	GetMedianTimePastContext(ctx context.Context) (int64, error)
+
+	Warp(blocks int64, miner string) error
+	WarpContext(ctx context.Context, blocks int64, miner string) error
+
+	EnsureWallet(walletName string) error
+	EnsureWalletContext(ctx context.Context, walletName string) error
+	UnloadWallet(walletName string) error
+	UnloadWalletContext(ctx context.Context, walletName string) error
+	GenerateBech32(label string) (string, error)
+	GenerateBech32Context(ctx context.Context, label string) (string, error)
+
+	SendToAddress(address string, sats int64) (*chainhash.Hash, error)
+	SendToAddressContext(ctx context.Context, address string, sats int64) (*chainhash.Hash, error)
+	GetTxOut(txid *chainhash.Hash, vout uint32, includeMempool bool) (*btcjson.GetTxOutResult, error)
+	GetTxOutContext(ctx context.Context, txid *chainhash.Hash, vout uint32, includeMempool bool) (*btcjson.GetTxOutResult, error)
+	SignRawTransactionWithWallet(tx *wire.MsgTx) (*wire.MsgTx, error)
+	SignRawTransactionWithWalletContext(ctx context.Context, tx *wire.MsgTx) (*wire.MsgTx, error)
+	BroadcastTransaction(tx *wire.MsgTx) (*chainhash.Hash, error)
+	BroadcastTransactionContext(ctx context.Context, tx *wire.MsgTx) (*chainhash.Hash, error)
+	TestMempoolAccept(txs ...*wire.MsgTx) ([]MempoolAcceptResult, error)
+	TestMempoolAcceptContext(ctx context.Context, txs ...*wire.MsgTx) ([]MempoolAcceptResult, error)
+	GetMempoolInfo() (*btcjson.GetMempoolInfoResult, error)
+	GetMempoolInfoContext(ctx context.Context) (*btcjson.GetMempoolInfoResult, error)
+	GetRawMempool() ([]*chainhash.Hash, error)
+	GetRawMempoolContext(ctx context.Context) ([]*chainhash.Hash, error)
+	GetRawMempoolVerbose() (map[string]MempoolEntry, error)
+	GetRawMempoolVerboseContext(ctx context.Context) (map[string]MempoolEntry, error)
+}
+
+var _ Node = (*Regtest)(nil)
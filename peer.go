@@ -2,10 +2,15 @@ package regtest
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net"
 	"strconv"
 	"strings"
+	"sync"
 
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/rpcclient"
 )
 
@@ -27,8 +32,76 @@ func extractP2PPort(host string) string {
 	return strconv.Itoa(rpc + 1)
 }
 
-// peerAddress builds the "host:p2p_port" address other should be reached at,
-// derived from its Config().Host using the script's RPC+1 convention.
+// P2PPort returns the port this instance's bitcoind listens on for P2P
+// connections: Config.P2PPort when set, otherwise the RPC port from
+// Config.Host plus one, the convention scripts/bitcoind_manager.sh follows
+// (18444 when Host has no port). Returns 0 when Host's port isn't numeric.
+//
+// Example:
+//
+//	addr := fmt.Sprintf("127.0.0.1:%d", rt2.P2PPort())
+//	err := rt1.AddNode(addr)
+func (r *Regtest) P2PPort() int {
+	if r.config.P2PPort > 0 {
+		return r.config.P2PPort
+	}
+	port, err := strconv.Atoi(extractP2PPort("host:" + r.extractPort()))
+	if err != nil {
+		return 0
+	}
+	return port
+}
+
+// localNodes maps the P2P port of every started instance in this process to
+// the instance, so AddNode can mark the node at the other end as peered the
+// way Connect does.
+var (
+	localNodesMu sync.Mutex
+	localNodes   = map[int]*Regtest{}
+)
+
+// registerLocalNode records r under its P2P port. Called once its RPC
+// client is up.
+func registerLocalNode(r *Regtest) {
+	if port := r.P2PPort(); port > 0 {
+		localNodesMu.Lock()
+		localNodes[port] = r
+		localNodesMu.Unlock()
+	}
+}
+
+// unregisterLocalNode removes r, if it is still the instance registered
+// under its P2P port. Called on Stop.
+func unregisterLocalNode(r *Regtest) {
+	localNodesMu.Lock()
+	defer localNodesMu.Unlock()
+	if port := r.P2PPort(); localNodes[port] == r {
+		delete(localNodes, port)
+	}
+}
+
+// localNodeAt returns the started instance listening at the loopback
+// address host ("127.0.0.1:18444", "localhost:18444", ...), or nil when host
+// is remote or no instance in this process owns the port.
+func localNodeAt(host string) *Regtest {
+	h, portStr, err := net.SplitHostPort(host)
+	if err != nil {
+		return nil
+	}
+	if ip := net.ParseIP(h); h != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return nil
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return nil
+	}
+	localNodesMu.Lock()
+	defer localNodesMu.Unlock()
+	return localNodes[port]
+}
+
+// peerAddress builds the "host:p2p_port" address other should be reached at:
+// the host part of its Config().Host and its P2PPort.
 func peerAddress(other *Regtest) (string, error) {
 	if other == nil {
 		return "", fmt.Errorf("peer must not be nil")
@@ -38,16 +111,15 @@ func peerAddress(other *Regtest) (string, error) {
 	if idx < 0 {
 		return "", fmt.Errorf("peer host %q has no port", host)
 	}
-	p2p := extractP2PPort(host)
-	if p2p == "" {
+	p2p := other.P2PPort()
+	if p2p == 0 {
 		return "", fmt.Errorf("peer host %q: cannot derive P2P port", host)
 	}
-	return host[:idx] + ":" + p2p, nil
+	return host[:idx] + ":" + strconv.Itoa(p2p), nil
 }
 
 // Connect tells this node to add the other regtest instance as a persistent
-// peer. The peer's P2P port is derived from its Config().Host using the
-// scripts/bitcoind_manager.sh convention (P2P = RPC + 1).
+// peer, reached at the host of its Config().Host and its P2PPort.
 //
 // Connect is asynchronous: bitcoind queues the addnode request and the
 // handshake completes shortly after the call returns. Tests that depend on
@@ -95,6 +167,9 @@ func (r *Regtest) ConnectContext(ctx context.Context, other *Regtest) error {
 	if err != nil {
 		return fmt.Errorf("connect %s: %w", addr, err)
 	}
+	// Both ends can now receive blocks over P2P.
+	r.markPeered()
+	other.markPeered()
 	return nil
 }
 
@@ -143,7 +218,9 @@ func (r *Regtest) DisconnectContext(ctx context.Context, other *Regtest) error {
 
 // AddNode is the lower-level escape hatch for connecting to a host bitcoind
 // reachable at an arbitrary "host:p2p_port" address. Prefer Connect when both
-// nodes are *Regtest instances managed by this library.
+// nodes are *Regtest instances managed by this library. When host is the
+// loopback address of another started instance in this process, that
+// instance's height cache is disabled too, as Connect does for both ends.
 //
 // Parameters:
 //   - host: peer address ("host:port"). Must be non-empty.
@@ -174,6 +251,12 @@ func (r *Regtest) AddNodeContext(ctx context.Context, host string) error {
 	if err != nil {
 		return fmt.Errorf("addnode %s: %w", host, err)
 	}
+	// As with Connect, both ends can now receive blocks over P2P; the far
+	// end is only known when it is an instance in this process.
+	r.markPeered()
+	if other := localNodeAt(host); other != nil {
+		other.markPeered()
+	}
 	return nil
 }
 
@@ -205,3 +288,149 @@ func (r *Regtest) GetConnectionCountContext(ctx context.Context) (int64, error)
 	}
 	return n, nil
 }
+
+// NetworkInfo is a curated subset of bitcoind's getnetworkinfo response:
+// fields stable across Bitcoin Core versions (warnings, for one, changed
+// from a string to an array in Core 28 and is omitted).
+type NetworkInfo struct {
+	// Version is the numeric bitcoind version, e.g. 270000.
+	Version int `json:"version"`
+	// Subversion is the user agent, e.g. "/Satoshi:27.0.0/".
+	Subversion string `json:"subversion"`
+	// ProtocolVersion is the P2P protocol version.
+	ProtocolVersion int `json:"protocolversion"`
+	// NetworkActive reports whether P2P networking is enabled.
+	NetworkActive bool `json:"networkactive"`
+	// Connections is the total number of peer connections.
+	Connections int `json:"connections"`
+	// ConnectionsIn is the number of inbound peers.
+	ConnectionsIn int `json:"connections_in"`
+	// ConnectionsOut is the number of outbound peers.
+	ConnectionsOut int `json:"connections_out"`
+	// RelayFee is the minimum relay fee rate in BTC/kvB.
+	RelayFee float64 `json:"relayfee"`
+	// IncrementalFee is the minimum fee rate increment for replacement and
+	// mempool limiting, in BTC/kvB.
+	IncrementalFee float64 `json:"incrementalfee"`
+}
+
+// GetNetworkInfo returns curated P2P state from getnetworkinfo: version,
+// peer counts split by direction, and relay fee rates. bitcoind doesn't
+// report its connection limit; pair the counts with Config().MaxConnections
+// when testing that a capped node refuses further peers.
+//
+// Returns:
+//   - *NetworkInfo: version, connection counts and relay fees
+//   - error: errNotConnected before Start; otherwise wrapped RPC or
+//     unmarshal error.
+//
+// Example:
+//
+//	info, err := rt.GetNetworkInfo()
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Printf("%d in / %d out\n", info.ConnectionsIn, info.ConnectionsOut)
+func (r *Regtest) GetNetworkInfo() (*NetworkInfo, error) {
+	return r.GetNetworkInfoContext(context.Background())
+}
+
+// GetNetworkInfoContext is the context-aware variant of GetNetworkInfo.
+func (r *Regtest) GetNetworkInfoContext(ctx context.Context) (*NetworkInfo, error) {
+	raw, err := r.rawRPC(ctx, "getnetworkinfo")
+	if err != nil {
+		return nil, fmt.Errorf("getnetworkinfo: %w", err)
+	}
+	var info NetworkInfo
+	if err := json.Unmarshal(raw, &info); err != nil {
+		return nil, fmt.Errorf("unmarshal getnetworkinfo: %w", err)
+	}
+	return &info, nil
+}
+
+// GetNodeAddresses returns entries from the node's address manager (addrman)
+// — the peer addresses it has learned, with their advertised services and
+// last-seen time. A fresh regtest node starts with an empty addrman; entries
+// arrive via addr gossip from connected peers or the addpeeraddress RPC.
+//
+// Parameters:
+//   - count: maximum number of addresses to return; 0 returns all of them.
+//     Must not be negative.
+//
+// Returns:
+//   - []btcjson.GetNodeAddressesResult: known addresses (empty when addrman is)
+//   - error: validation error for a negative count; errNotConnected before
+//     Start; otherwise wrapped RPC error.
+//
+// Example:
+//
+//	addrs, err := rt.GetNodeAddresses(0)
+//	if err != nil { return err }
+//	for _, a := range addrs {
+//	    fmt.Printf("%s:%d services=%x\n", a.Address, a.Port, a.Services)
+//	}
+func (r *Regtest) GetNodeAddresses(count int) ([]btcjson.GetNodeAddressesResult, error) {
+	return r.GetNodeAddressesContext(context.Background(), count)
+}
+
+// GetNodeAddressesContext is the context-aware variant of GetNodeAddresses.
+func (r *Regtest) GetNodeAddressesContext(ctx context.Context, count int) ([]btcjson.GetNodeAddressesResult, error) {
+	if count < 0 {
+		return nil, fmt.Errorf("count must be >= 0, got %d", count)
+	}
+	raw, err := r.rawRPC(ctx, "getnodeaddresses", count)
+	if err != nil {
+		return nil, fmt.Errorf("getnodeaddresses: %w", err)
+	}
+	var addrs []btcjson.GetNodeAddressesResult
+	if err := json.Unmarshal(raw, &addrs); err != nil {
+		return nil, fmt.Errorf("unmarshal getnodeaddresses: %w", err)
+	}
+	return addrs, nil
+}
+
+// GetBlockFromPeer asks the node to fetch block hash from one specific peer
+// via getblockfrompeer. The node must already have the block's header (from
+// headers sync or submitheader) but not the block itself — the typical case
+// is a stale fork a less-worked peer announced. The request is asynchronous:
+// poll GetBlock until the block arrives.
+//
+// getblockfrompeer was added in Bitcoin Core 23.0; older nodes get an error
+// wrapping ErrUnsupportedVersion.
+//
+// Parameters:
+//   - hash: block to fetch (must be non-nil)
+//   - peerID: the peer's id as reported by getpeerinfo (must be >= 0)
+//
+// Returns:
+//   - error: validation error for nil hash or negative peerID;
+//     ErrUnsupportedVersion on nodes older than 23.0; errNotConnected
+//     before Start; otherwise wrapped RPC error (unknown header, block
+//     already downloaded, unknown peer, ...).
+//
+// Example:
+//
+//	if err := rt1.GetBlockFromPeer(staleHash, peerID); err != nil {
+//	    return err
+//	}
+//	// ... poll rt1.GetBlock(staleHash) until it succeeds
+func (r *Regtest) GetBlockFromPeer(hash *chainhash.Hash, peerID int) error {
+	return r.GetBlockFromPeerContext(context.Background(), hash, peerID)
+}
+
+// GetBlockFromPeerContext is the context-aware variant of GetBlockFromPeer.
+func (r *Regtest) GetBlockFromPeerContext(ctx context.Context, hash *chainhash.Hash, peerID int) error {
+	if hash == nil {
+		return fmt.Errorf("hash must not be nil")
+	}
+	if peerID < 0 {
+		return fmt.Errorf("peerID must be >= 0, got %d", peerID)
+	}
+	if err := r.requireVersion(ctx, 230000, "getblockfrompeer"); err != nil {
+		return err
+	}
+	if _, err := r.rawRPC(ctx, "getblockfrompeer", hash.String(), peerID); err != nil {
+		return fmt.Errorf("getblockfrompeer %s from peer %d: %w", hash, peerID, err)
+	}
+	return nil
+}
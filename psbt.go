@@ -0,0 +1,314 @@
+package regtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/btcsuite/btcd/btcutil"
+)
+
+// FundOptions are the wallet funding options accepted by CreateFundedPSBT
+// (the "options" object of walletcreatefundedpsbt). Zero values leave the
+// corresponding option to the wallet's default.
+type FundOptions struct {
+	// ChangeAddress pays change to this address instead of a fresh one.
+	ChangeAddress string `json:"changeAddress,omitempty"`
+	// ChangePosition fixes the change output index; nil picks it randomly.
+	ChangePosition *int `json:"changePosition,omitempty"`
+	// ChangeType selects the change output type ("legacy", "p2sh-segwit",
+	// "bech32", "bech32m"). Ignored when ChangeAddress is set.
+	ChangeType string `json:"change_type,omitempty"`
+	// IncludeWatching also selects watch-only inputs.
+	IncludeWatching bool `json:"includeWatching,omitempty"`
+	// LockUnspents locks the selected inputs so later funding calls don't
+	// pick them again.
+	LockUnspents bool `json:"lockUnspents,omitempty"`
+	// FeeRate is an explicit fee rate in sat/vB. 0 uses the wallet's fee
+	// estimation (or -fallbackfee on a fresh regtest chain).
+	FeeRate float64 `json:"fee_rate,omitempty"`
+	// SubtractFeeFromOutputs lists output indexes (in the order outputs are
+	// sent — sorted by address for CreateFundedPSBT) that pay the fee.
+	SubtractFeeFromOutputs []int `json:"subtractFeeFromOutputs,omitempty"`
+	// Replaceable overrides the wallet's -walletrbf default; nil keeps it.
+	Replaceable *bool `json:"replaceable,omitempty"`
+}
+
+// CreateFundedPSBT builds a PSBT paying outputs, funded by coin selection
+// from the loaded wallet, via walletcreatefundedpsbt. The result is
+// unsigned but carries the UTXO and BIP32 derivation data a signer needs —
+// the "build a spend, hand it to an offline signer" primitive.
+//
+// Outputs are sent to bitcoind sorted by address, so output order (and the
+// indexes used by FundOptions.SubtractFeeFromOutputs) is deterministic
+// apart from the change position.
+//
+// Parameters:
+//   - outputs: address → amount in satoshis (must be non-empty, amounts > 0)
+//   - opts: funding options; nil for wallet defaults
+//
+// Returns:
+//   - psbt: base64-encoded PSBT
+//   - fee: fee paid, in satoshis
+//   - changePos: index of the change output, or -1 when there is none
+//   - err: validation error for empty or non-positive outputs;
+//     errNotConnected before Start; otherwise wrapped RPC error (e.g.
+//     "Insufficient funds").
+//
+// Example:
+//
+//	psbt, fee, _, err := rt.CreateFundedPSBT(map[string]int64{dest: 50_000}, nil)
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Printf("fee %d sat, psbt %s\n", fee, psbt)
+func (r *Regtest) CreateFundedPSBT(outputs map[string]int64, opts *FundOptions) (psbt string, fee int64, changePos int, err error) {
+	return r.CreateFundedPSBTContext(context.Background(), outputs, opts)
+}
+
+// CreateFundedPSBTContext is the context-aware variant of CreateFundedPSBT.
+func (r *Regtest) CreateFundedPSBTContext(ctx context.Context, outputs map[string]int64, opts *FundOptions) (psbt string, fee int64, changePos int, err error) {
+	if len(outputs) == 0 {
+		return "", 0, 0, fmt.Errorf("at least one output required")
+	}
+	addrs := make([]string, 0, len(outputs))
+	for addr, sats := range outputs {
+		if sats <= 0 {
+			return "", 0, 0, fmt.Errorf("output %s: amount must be greater than 0, got %d", addr, sats)
+		}
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+	outs := make([]map[string]float64, len(addrs))
+	for i, addr := range addrs {
+		outs[i] = map[string]float64{addr: btcutil.Amount(outputs[addr]).ToBTC()}
+	}
+
+	o := FundOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	raw, err := r.rawRPC(ctx, "walletcreatefundedpsbt", []any{}, outs, 0, o, true)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("walletcreatefundedpsbt: %w", err)
+	}
+	var res struct {
+		PSBT      string  `json:"psbt"`
+		Fee       float64 `json:"fee"`
+		ChangePos int     `json:"changepos"`
+	}
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return "", 0, 0, fmt.Errorf("failed to unmarshal walletcreatefundedpsbt: %w", err)
+	}
+	amt, err := btcutil.NewAmount(res.Fee)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("converting fee %v: %w", res.Fee, err)
+	}
+	return res.PSBT, int64(amt), res.ChangePos, nil
+}
+
+// CombinePSBT merges copies of the same PSBT — typically each processed by a
+// different signer — into one carrying the union of their signatures and
+// metadata, via combinepsbt. This is the step between "each cosigner signs
+// their copy" and finalizepsbt.
+//
+// Parameters:
+//   - psbts: base64 PSBTs for the same unsigned transaction (at least one,
+//     none empty)
+//
+// Returns:
+//   - string: the combined base64 PSBT
+//   - error: validation error for empty input; errNotConnected before
+//     Start; otherwise wrapped RPC error — bitcoind reports "PSBTs not
+//     compatible (different transactions)" when the copies disagree.
+//
+// Example:
+//
+//	combined, err := rt.CombinePSBT([]string{signedByAlice, signedByBob})
+//	if err != nil {
+//	    return err
+//	}
+func (r *Regtest) CombinePSBT(psbts []string) (string, error) {
+	return r.CombinePSBTContext(context.Background(), psbts)
+}
+
+// CombinePSBTContext is the context-aware variant of CombinePSBT.
+func (r *Regtest) CombinePSBTContext(ctx context.Context, psbts []string) (string, error) {
+	if err := validatePSBTList(psbts, 1); err != nil {
+		return "", err
+	}
+	return r.psbtListRPC(ctx, "combinepsbt", psbts)
+}
+
+// JoinPSBTs concatenates the inputs and outputs of distinct PSBTs into a
+// single PSBT via joinpsbts — the coinjoin-style merge, as opposed to
+// CombinePSBT's merge of copies of one transaction. Input and output order
+// is shuffled by bitcoind.
+//
+// Parameters:
+//   - psbts: base64 PSBTs to join (at least two, none empty)
+//
+// Returns:
+//   - string: the joined base64 PSBT
+//   - error: validation error for fewer than two PSBTs; errNotConnected
+//     before Start; otherwise wrapped RPC error (e.g. when two PSBTs spend
+//     the same input).
+//
+// Example:
+//
+//	joined, err := rt.JoinPSBTs([]string{alicePSBT, bobPSBT})
+//	if err != nil {
+//	    return err
+//	}
+func (r *Regtest) JoinPSBTs(psbts []string) (string, error) {
+	return r.JoinPSBTsContext(context.Background(), psbts)
+}
+
+// JoinPSBTsContext is the context-aware variant of JoinPSBTs.
+func (r *Regtest) JoinPSBTsContext(ctx context.Context, psbts []string) (string, error) {
+	if err := validatePSBTList(psbts, 2); err != nil {
+		return "", err
+	}
+	return r.psbtListRPC(ctx, "joinpsbts", psbts)
+}
+
+// validatePSBTList checks psbts has at least minCount entries and none are empty.
+func validatePSBTList(psbts []string, minCount int) error {
+	if len(psbts) < minCount {
+		return fmt.Errorf("at least %d PSBT(s) required, got %d", minCount, len(psbts))
+	}
+	for i, p := range psbts {
+		if p == "" {
+			return fmt.Errorf("psbts[%d] is empty", i)
+		}
+	}
+	return nil
+}
+
+// psbtListRPC issues an RPC taking a list of base64 PSBTs and returning one.
+func (r *Regtest) psbtListRPC(ctx context.Context, method string, psbts []string) (string, error) {
+	raw, err := r.rawRPC(ctx, method, psbts)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", method, err)
+	}
+	var out string
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return "", fmt.Errorf("failed to unmarshal %s: %w", method, err)
+	}
+	return out, nil
+}
+
+// PSBTAnalysis is the result of AnalyzePSBT: what each input still needs
+// and which role should act on the PSBT next. btcjson has no type for
+// analyzepsbt, so this mirrors bitcoind's fields directly.
+type PSBTAnalysis struct {
+	// Inputs holds one entry per PSBT input, in input order.
+	Inputs []PSBTInputAnalysis `json:"inputs"`
+	// EstimatedVSize is the estimated vsize of the final signed tx, in
+	// vbytes; 0 when bitcoind can't estimate it yet (missing UTXO data).
+	EstimatedVSize int64 `json:"estimated_vsize"`
+	// EstimatedFeeRate is the estimated fee rate of the final signed tx, in
+	// sat/vB; 0 when unknown.
+	EstimatedFeeRate float64 `json:"-"`
+	// Fee is the transaction fee; 0 when any input lacks UTXO data.
+	Fee btcutil.Amount `json:"-"`
+	// Next is the role that must process the PSBT next: "creator",
+	// "updater", "signer", "finalizer" or "extractor".
+	Next string `json:"next"`
+	// Error is set when the PSBT is invalid (e.g. inputs spend more than
+	// they carry), empty otherwise.
+	Error string `json:"error"`
+}
+
+// PSBTInputAnalysis is the per-input part of PSBTAnalysis.
+type PSBTInputAnalysis struct {
+	// HasUTXO reports whether the input carries its previous output.
+	HasUTXO bool `json:"has_utxo"`
+	// IsFinal reports whether the input has a final scriptSig/witness.
+	IsFinal bool `json:"is_final"`
+	// Missing lists what the input still lacks before it can be finalized;
+	// all fields empty when nothing is missing.
+	Missing PSBTMissing `json:"missing"`
+	// Next is the role that must process this input next.
+	Next string `json:"next"`
+}
+
+// PSBTMissing lists the data an input still needs to be signed.
+type PSBTMissing struct {
+	// Pubkeys are the hash160s of public keys whose BIP32 derivation paths
+	// are missing.
+	Pubkeys []string `json:"pubkeys"`
+	// Signatures are the hash160s of public keys whose signatures are
+	// missing.
+	Signatures []string `json:"signatures"`
+	// RedeemScript is the hash160 of the missing redeem script.
+	RedeemScript string `json:"redeemscript"`
+	// WitnessScript is the SHA256 of the missing witness script.
+	WitnessScript string `json:"witnessscript"`
+}
+
+// AnalyzePSBT reports the signing state of a PSBT via analyzepsbt: per-input
+// missing pubkeys, signatures and scripts, the next role needed, and the
+// estimated fee and size. The diagnostic to reach for when finalizepsbt
+// reports the PSBT incomplete.
+//
+// Parameters:
+//   - psbt: base64-encoded PSBT (must be non-empty)
+//
+// Returns:
+//   - *PSBTAnalysis: the analysis; a malformed-but-decodable PSBT is reported
+//     through PSBTAnalysis.Error rather than as an error
+//   - error: validation error for empty psbt; errNotConnected before Start;
+//     otherwise wrapped RPC error (e.g. "TX decode failed").
+//
+// Example:
+//
+//	a, err := rt.AnalyzePSBT(encoded)
+//	if err != nil {
+//	    return err
+//	}
+//	for i, in := range a.Inputs {
+//	    fmt.Printf("input %d: next=%s missing sigs=%v\n", i, in.Next, in.Missing.Signatures)
+//	}
+func (r *Regtest) AnalyzePSBT(psbt string) (*PSBTAnalysis, error) {
+	return r.AnalyzePSBTContext(context.Background(), psbt)
+}
+
+// AnalyzePSBTContext is the context-aware variant of AnalyzePSBT.
+func (r *Regtest) AnalyzePSBTContext(ctx context.Context, psbt string) (*PSBTAnalysis, error) {
+	if psbt == "" {
+		return nil, fmt.Errorf("psbt must not be empty")
+	}
+	raw, err := r.rawRPC(ctx, "analyzepsbt", psbt)
+	if err != nil {
+		return nil, fmt.Errorf("analyzepsbt: %w", err)
+	}
+	return decodePSBTAnalysis(raw)
+}
+
+// decodePSBTAnalysis unmarshals an analyzepsbt response, converting the BTC
+// fee and BTC/kvB fee rate into satoshis and sat/vB.
+func decodePSBTAnalysis(raw []byte) (*PSBTAnalysis, error) {
+	var res struct {
+		PSBTAnalysis
+		EstimatedFeeRate float64 `json:"estimated_feerate"`
+		Fee              float64 `json:"fee"`
+	}
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal analyzepsbt: %w", err)
+	}
+	fee, err := btcutil.NewAmount(res.Fee)
+	if err != nil {
+		return nil, fmt.Errorf("converting fee %v: %w", res.Fee, err)
+	}
+	feeRate, err := btcutil.NewAmount(res.EstimatedFeeRate)
+	if err != nil {
+		return nil, fmt.Errorf("converting fee rate %v: %w", res.EstimatedFeeRate, err)
+	}
+	out := res.PSBTAnalysis
+	out.Fee = fee
+	// BTC/kvB → sat/vB: sat per 1000 vB.
+	out.EstimatedFeeRate = float64(feeRate) / 1000
+	return &out, nil
+}
@@ -0,0 +1,308 @@
+package regtest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/rpcclient"
+)
+
+// RecordedCall is one JSON-RPC call captured by StartRecording, written as a
+// single JSON line. ReplayRPC reads the same format.
+type RecordedCall struct {
+	// Wallet is the /wallet/<name> endpoint the call targeted; empty for
+	// the node-wide endpoint.
+	Wallet string `json:"wallet,omitempty"`
+	// Method and Params are the request as sent.
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+	// Result is the response result; Error is set when bitcoind rejected
+	// the call.
+	Result json.RawMessage   `json:"result,omitempty"`
+	Error  *btcjson.RPCError `json:"error,omitempty"`
+}
+
+// rpcRecorder is a local HTTP proxy in front of bitcoind's RPC port that
+// logs every request/response pair passing through it.
+type rpcRecorder struct {
+	target string // bitcoind RPC host:port
+	ln     net.Listener
+	srv    *http.Server
+
+	mu  sync.Mutex // serializes writes to enc
+	enc *json.Encoder
+}
+
+// ServeHTTP forwards req to bitcoind, relays the response, and logs the
+// call(s) it carried.
+func (rec *rpcRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	out, err := http.NewRequestWithContext(req.Context(), req.Method,
+		"http://"+rec.target+req.URL.RequestURI(), bytes.NewReader(body))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	out.Header = req.Header.Clone()
+	resp, err := http.DefaultClient.Do(out)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer func() { _ = resp.Body.Close() }()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	wallet := strings.TrimPrefix(req.URL.Path, "/wallet/")
+	if wallet == req.URL.Path {
+		wallet = ""
+	}
+	rec.log(wallet, body, respBody)
+
+	for k, v := range resp.Header {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = w.Write(respBody)
+}
+
+// log writes one RecordedCall line per call in a single or batched
+// request. Bodies that don't parse as JSON-RPC are skipped.
+func (rec *rpcRecorder) log(wallet string, reqBody, respBody []byte) {
+	type request struct {
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	type response struct {
+		Result json.RawMessage   `json:"result"`
+		Error  *btcjson.RPCError `json:"error"`
+	}
+	var reqs []request
+	var resps []response
+	if trimmed := bytes.TrimSpace(reqBody); len(trimmed) > 0 && trimmed[0] == '[' {
+		if json.Unmarshal(reqBody, &reqs) != nil || json.Unmarshal(respBody, &resps) != nil {
+			return
+		}
+	} else {
+		var one request
+		var oneResp response
+		if json.Unmarshal(reqBody, &one) != nil || json.Unmarshal(respBody, &oneResp) != nil {
+			return
+		}
+		reqs, resps = []request{one}, []response{oneResp}
+	}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	for i, q := range reqs {
+		call := RecordedCall{Wallet: wallet, Method: q.Method, Params: q.Params}
+		if i < len(resps) {
+			call.Result, call.Error = resps[i].Result, resps[i].Error
+		}
+		if call.Params == nil {
+			call.Params = []json.RawMessage{}
+		}
+		_ = rec.enc.Encode(call)
+	}
+}
+
+// dialConfig is the connection configuration the library's own RPC clients
+// use: RPCConfig, pointed at the recording proxy while StartRecording is in
+// effect.
+func (r *Regtest) dialConfig() *rpcclient.ConnConfig {
+	cfg := r.RPCConfig()
+	r.recMu.Lock()
+	defer r.recMu.Unlock()
+	if r.recorder != nil {
+		cfg.Host = r.recorder.ln.Addr().String()
+	}
+	return cfg
+}
+
+// reconnectClient replaces a live RPC client with one built from the
+// current dialConfig. A no-op before Start.
+func (r *Regtest) reconnectClient() error {
+	r.clientMu.Lock()
+	defer r.clientMu.Unlock()
+	if r.client == nil {
+		return nil
+	}
+	client, err := rpcclient.New(r.dialConfig(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create RPC client: %w", err)
+	}
+	r.client.Shutdown()
+	r.client = client
+	return nil
+}
+
+// StartRecording logs every JSON-RPC call this instance makes from now on —
+// typed wrappers, raw calls and per-wallet calls alike — to w as JSON lines
+// of RecordedCall (wallet, method, params, result, error), in the order
+// bitcoind answered them. Save the trace of a failing run and feed it to
+// ReplayRPC to reproduce it. Recording continues across Stop and Start
+// until StopRecording or Cleanup.
+//
+// Calls are captured by a local HTTP proxy the RPC client is re-pointed
+// at, so calls made through RPCConfig or Client() obtained before
+// StartRecording, and bitcoin-cli calls by the manager script, are not
+// recorded. Don't start or stop recording while other RPCs are in flight:
+// the client is swapped underneath them.
+//
+// Parameters:
+//   - w: destination for the trace; writes are serialized
+//
+// Returns:
+//   - error: when already recording, w is nil, or the proxy can't listen.
+//
+// Example:
+//
+//	var trace bytes.Buffer
+//	if err := rt.StartRecording(&trace); err != nil {
+//	    t.Fatal(err)
+//	}
+//	defer rt.StopRecording()
+//	// ... test body ...
+//	if t.Failed() {
+//	    _ = os.WriteFile("trace.jsonl", trace.Bytes(), 0o600)
+//	}
+func (r *Regtest) StartRecording(w io.Writer) error {
+	if w == nil {
+		return fmt.Errorf("StartRecording: writer must not be nil")
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("StartRecording: failed to listen: %w", err)
+	}
+	rec := &rpcRecorder{target: r.config.Host, ln: ln, enc: json.NewEncoder(w)}
+	rec.srv = &http.Server{Handler: rec, ReadHeaderTimeout: 10 * time.Second}
+
+	r.recMu.Lock()
+	if r.recorder != nil {
+		r.recMu.Unlock()
+		_ = ln.Close()
+		return fmt.Errorf("StartRecording: already recording")
+	}
+	r.recorder = rec
+	r.recMu.Unlock()
+
+	go func() { _ = rec.srv.Serve(ln) }()
+	return r.reconnectClient()
+}
+
+// StopRecording ends a StartRecording, pointing the RPC client straight at
+// bitcoind again and shutting the proxy down. A no-op when not recording.
+//
+// Returns:
+//   - error: failure to rebuild the RPC client or to close the proxy.
+//
+// Example:
+//
+//	if err := rt.StopRecording(); err != nil {
+//	    return err
+//	}
+func (r *Regtest) StopRecording() error {
+	r.recMu.Lock()
+	rec := r.recorder
+	r.recorder = nil
+	r.recMu.Unlock()
+	if rec == nil {
+		return nil
+	}
+	return errors.Join(r.reconnectClient(), rec.srv.Close())
+}
+
+// blockProducingRPCs are the methods ReplayRPC refuses with ErrChainFrozen
+// while FreezeChain is in effect.
+var blockProducingRPCs = map[string]bool{
+	"generate":             true,
+	"generateblock":        true,
+	"generatetoaddress":    true,
+	"generatetodescriptor": true,
+	"submitblock":          true,
+}
+
+// ReplayRPC re-issues the calls of a StartRecording trace, in order, against
+// this node. Results are not compared — txids, addresses and block hashes
+// differ between runs — so a replay reproduces a run only as far as its
+// calls are deterministic (e.g. fixed mocktime, no fresh wallet keys in
+// later params). Calls that failed in the recording are re-issued and their
+// errors ignored; the first call that fails now but succeeded then stops
+// the replay. While the chain is frozen (FreezeChain), a block-producing
+// call (generatetoaddress, generateblock, submitblock, ...) stops the
+// replay with ErrChainFrozen instead of being issued. The replay may move
+// the tip, so the Config.CacheHeight cache is dropped when it returns.
+//
+// Parameters:
+//   - rd: a trace in StartRecording's JSON-lines format
+//
+// Returns:
+//   - error: decode error naming the call index; errNotConnected before
+//     Start; otherwise the first newly failing call's error, or
+//     ErrChainFrozen, naming its index and method.
+//
+// Example:
+//
+//	f, err := os.Open("trace.jsonl")
+//	if err != nil {
+//	    return err
+//	}
+//	defer f.Close()
+//	if err := fresh.ReplayRPC(f); err != nil {
+//	    t.Fatalf("replay diverged: %v", err)
+//	}
+func (r *Regtest) ReplayRPC(rd io.Reader) error {
+	return r.ReplayRPCContext(context.Background(), rd)
+}
+
+// ReplayRPCContext is the context-aware variant of ReplayRPC.
+func (r *Regtest) ReplayRPCContext(ctx context.Context, rd io.Reader) error {
+	if _, err := r.lockedClient(); err != nil {
+		return err
+	}
+	defer r.InvalidateHeightCache()
+	dec := json.NewDecoder(rd)
+	for i := 0; ; i++ {
+		var call RecordedCall
+		if err := dec.Decode(&call); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("replay: decode call %d: %w", i, err)
+		}
+		if blockProducingRPCs[call.Method] && r.frozen.Load() {
+			return fmt.Errorf("replay: call %d (%s): %w", i, call.Method, ErrChainFrozen)
+		}
+		args := make([]any, len(call.Params))
+		for j, p := range call.Params {
+			args[j] = p
+		}
+		if blockProducingRPCs[call.Method] {
+			r.beginMining()
+		}
+		_, err := r.walletRawRPC(ctx, call.Wallet, call.Method, args...)
+		if blockProducingRPCs[call.Method] {
+			r.abortMining()
+		}
+		if err != nil && call.Error == nil {
+			return fmt.Errorf("replay: call %d (%s): %w", i, call.Method, err)
+		}
+	}
+}
@@ -5,21 +5,38 @@ package regtest
 import (
 	"context"
 	_ "embed"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
+	"math"
+	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/rpcclient"
 )
 
 // errNotConnected is returned by RPC methods called before Start() or after Stop().
 var errNotConnected = errors.New("RPC client not connected")
 
+// ErrBitcoindNotFound is wrapped by the error New (or, for instances built
+// with NewMaybe, Start) returns when no bitcoind binary can be resolved:
+// Config.BinaryPath doesn't exist, or neither bitcoind-inquisition nor
+// bitcoind is on PATH.
+var ErrBitcoindNotFound = errors.New("bitcoind not found")
+
 //go:embed scripts/bitcoind_manager.sh
 var bitcoindManagerScript string
 
@@ -60,6 +77,209 @@ type Config struct {
 	// PATH (e.g. "bitcoind-inquisition"). The bitcoin-cli companion is
 	// derived from the same directory, falling back to bitcoin-cli on PATH.
 	BinaryPath string
+
+	// ExternalSigner maps to -signer=<cmd> when non-empty. The command is
+	// invoked by bitcoind for enumeratesigners and for signing PSBTs in
+	// wallets created with external_signer=true (HWI-compatible protocol).
+	// Point it at a mock signer script to exercise hardware-wallet flows
+	// without a device. Requires a bitcoind built with external signer
+	// support. Default empty (no signer).
+	ExternalSigner string
+
+	// CacheHeight lets GetBlockCount serve the chain height from a local
+	// cache instead of issuing getblockcount. The cache is primed by the
+	// first GetBlockCount, advanced by Warp (which knows how many blocks it
+	// mined), and dropped by any operation that can move the tip in ways the
+	// library can't count (InvalidateBlock, ReconsiderBlock, PreciousBlock,
+	// SubmitBlock, Start, Stop). Once the node has been peered via Connect or
+	// AddNode — on either end, when both are instances in this process —
+	// blocks may arrive over P2P at any time, so GetBlockCount always polls
+	// bitcoind from then on. Call InvalidateHeightCache after mining through
+	// Client() or any other path this library doesn't see, including a peer
+	// added from outside this process.
+	// Default false.
+	CacheHeight bool
+
+	// UAComment maps to -uacomment=<comment> when non-empty. bitcoind appends
+	// it to its user agent, so getnetworkinfo reports e.g.
+	// "/Satoshi:27.0.0(alice)/" and peers see the same string in
+	// getpeerinfo's subver — handy for telling nodes apart in a multi-node
+	// test. bitcoind refuses to start if the comment contains characters
+	// outside its safe set (letters, digits, space and .,;-_?@). Avoid the
+	// word "inquisition", which Variant keys on. Default empty.
+	UAComment string
+
+	// DebugCategories renders one -debug=<category> per entry (e.g.
+	// "mempool", "net", "validation"), turning on bitcoind's debug logging
+	// for just those subsystems in <DataDir>/regtest/debug.log. Entries are
+	// checked against the categories Bitcoin Core knows; unknown ones are
+	// logged as a warning by New but still forwarded, since newer or patched
+	// builds may add categories. Default nil (no debug logging).
+	DebugCategories []string
+
+	// BlocksOnly maps to -blocksonly=1 when true. The node then ignores
+	// loose transactions announced by P2P peers while still accepting ones
+	// submitted locally over RPC (SendToAddress, BroadcastTransaction, ...).
+	// Peers whitelisted with the "relay" permission (e.g. ExtraArgs
+	// "-whitelist=relay@127.0.0.1") can still relay transactions to it,
+	// which makes the pair useful for relay-policy tests. Default false.
+	BlocksOnly bool
+
+	// LimitAncestorCount maps to -limitancestorcount=<n> when > 0: the
+	// maximum number of in-mempool ancestors (including the tx itself) a
+	// transaction may have. Lower it to hit "too-long-mempool-chain" with a
+	// short CPFP chain. 0 keeps bitcoind's default of 25; negative values
+	// are rejected by New.
+	LimitAncestorCount int
+
+	// LimitDescendantCount maps to -limitdescendantcount=<n> when > 0: the
+	// maximum number of in-mempool descendants (including the tx itself) any
+	// ancestor may have. 0 keeps bitcoind's default of 25; negative values
+	// are rejected by New.
+	LimitDescendantCount int
+
+	// LimitAncestorSize maps to -limitancestorsize=<kvB> when > 0: the
+	// maximum combined vsize, in kilo-vbytes, of a transaction and its
+	// in-mempool ancestors. 0 keeps bitcoind's default of 101; negative
+	// values are rejected by New.
+	LimitAncestorSize int
+
+	// LimitDescendantSize maps to -limitdescendantsize=<kvB> when > 0: the
+	// maximum combined vsize, in kilo-vbytes, of a transaction and its
+	// in-mempool descendants. 0 keeps bitcoind's default of 101; negative
+	// values are rejected by New.
+	LimitDescendantSize int
+
+	// ConfFile maps to -conf=<path> when non-empty, pointing bitcoind at an
+	// existing bitcoin.conf. New resolves a relative path against the
+	// working directory (bitcoind would otherwise resolve it against the
+	// datadir, which Start wipes) and rejects a path that doesn't exist.
+	// Command-line flags take precedence over the file in bitcoind, so the
+	// library's required flags (datadir, RPC credentials and ports, ...),
+	// ExtraArgs and every typed Config field win over conflicting keys in
+	// the file. Network-scoped keys such as port, rpcport and bind only
+	// apply from a [regtest] section. Default empty (no config file).
+	ConfFile string
+
+	// PersistMempoolV1 maps to -persistmempoolv1=1 when true, making
+	// SaveMempool (and the mempool.dat written at shutdown) use the
+	// unobfuscated v1 format that Bitcoin Core 25 and older can read. Core
+	// 26+ only; older nodes refuse to start with the flag, which Start
+	// reports as an error wrapping ErrUnsupportedVersion. Default false.
+	PersistMempoolV1 bool
+
+	// ChangeType maps to -changetype=<type> when non-empty, fixing the
+	// address type of change outputs the wallet creates (SendToAddress,
+	// FundRawTransaction, CreateFundedPSBT, ...). One of "legacy",
+	// "p2sh-segwit", "bech32" or "bech32m"; New rejects anything else.
+	// Default empty (bitcoind matches the change type to the outputs).
+	ChangeType string
+
+	// ReuseDataDir keeps DataDir across the instance's lifecycle: Start
+	// skips wiping it and launches bitcoind on the existing chain state,
+	// and Stop shuts bitcoind down without deleting it. Build an expensive
+	// chain once, Stop, and a later New+Start with the same DataDir (in this
+	// or another test binary) resumes it without re-mining. Default false
+	// (every Start begins from an empty datadir and Stop removes it).
+	ReuseDataDir bool
+
+	// AssumeValid maps to -assumevalid=<hash> when non-empty: bitcoind skips
+	// script verification for ancestors of that block. "0" disables the
+	// shortcut so every signature in every block is checked, which consensus
+	// tests need to be sure scripts actually run. On regtest there is no
+	// built-in assumevalid block, so this mostly selects the validation code
+	// path rather than affecting sync speed. New rejects anything other than
+	// "0" or a 64-character hex block hash. Default empty (bitcoind default).
+	AssumeValid string
+
+	// MaxTxFee maps to -maxtxfee=<BTC> when > 0: the wallet's ceiling on the
+	// absolute fee of a single transaction it creates (SendToAddress,
+	// FundRawTransaction, bumpfee, ...). Raise it for high-fee tests, lower
+	// it to exercise the "Fee exceeds maximum configured by user" path. It
+	// is separate from sendrawtransaction's per-call maxfeerate. bitcoind
+	// can't disable the check and refuses to start when the value is below
+	// the minimum relay fee for 1 kvB; 0 keeps bitcoind's default of 0.1
+	// BTC, and negative values are rejected by New.
+	MaxTxFee float64
+
+	// BlockMinTxFee maps to -blockmintxfee=<BTC/kvB> when > 0: the lowest
+	// fee rate a transaction needs to go into blocks this node mines,
+	// generatetoaddress included. Transactions paying at least the minimum
+	// relay fee (1 sat/vB) but less than this are still accepted into the
+	// mempool, so they stay unconfirmed however many blocks are mined —
+	// the setup CreateStuckTransaction needs. 0 keeps bitcoind's default
+	// of 0.00001 (1 sat/vB); negative values are rejected by New.
+	BlockMinTxFee float64
+
+	// MaxConnections maps to -maxconnections=<n> when > 0, capping the
+	// node's automatic and inbound peer connections; manual connections
+	// (Connect, AddNode) are limited separately by bitcoind. bitcoind
+	// reserves up to 11 slots for automatic outbound peers and admits
+	// inbound peers only into what remains, so values of 11 or less refuse
+	// every inbound peer. The live counts are reported by GetNetworkInfo;
+	// bitcoind doesn't report the limit itself. 0 keeps bitcoind's default
+	// of 125; negative values are rejected by New.
+	MaxConnections int
+
+	// Env holds extra "KEY=value" entries for the manager script's
+	// environment, which bitcoind and bitcoin-cli inherit. They are merged
+	// over os.Environ(), so an entry replaces an inherited variable of the
+	// same key (e.g. LC_ALL=C). The variables the library sets for the
+	// script itself (BITCOIND_BIN, BITCOIN_CLI_BIN, ...) still win. New
+	// rejects entries without a key.
+	Env []string
+
+	// FastPrune renders -fastprune=1, a regtest-only debug option that
+	// shrinks block files (blk*.dat) to 64 KiB so pruning frees files after
+	// a few hundred blocks instead of 128 MiB of chain. It only matters with
+	// pruning enabled, which also needs txindex off, e.g. ExtraArgs
+	// {"-prune=1", "-txindex=0"} for manual pruneblockchain. bitcoind still
+	// keeps the last 288 blocks.
+	FastPrune bool
+
+	// MinimumChainWork maps to -minimumchainwork=<hex> when non-empty: the
+	// total work a chain needs before the node leaves initial block
+	// download, relays and serves headers freely, and treats peers'
+	// headers chains as worth syncing. Use it to gate header-sync tests.
+	// Regtest's trivial difficulty adds just 2 units of work per block
+	// (the genesis block included), so the node reaches 2*(h+1) at height
+	// h — e.g. "0x100" holds the node in IBD until height 127. Leading
+	// "0x" is optional; New rejects non-hex values and ones longer than 64
+	// digits.
+	MinimumChainWork string
+
+	// RPCSerialVersion maps to -rpcserialversion=<n> when non-nil,
+	// selecting how RPCs such as getrawtransaction and getblock serialize
+	// transactions: 0 strips witness data (the pre-segwit format), 1 keeps
+	// it (bitcoind's default). Use 0 to exercise legacy-serialization
+	// parsing. Core 26 and 27 deprecate 0 behind -deprecatedrpc, which is
+	// rendered alongside it; Core 28 removed the flag, and such nodes
+	// refuse to start with it, which Start reports as an error wrapping
+	// ErrUnsupportedVersion. New rejects values other than 0 and 1.
+	// Default nil (flag omitted).
+	RPCSerialVersion *int
+
+	// P2PPort sets the port bitcoind listens on for P2P connections
+	// (-port=<n>) when > 0, for RPC ports whose successor is taken. P2PPort
+	// reports the effective port, and Connect and Disconnect dial it. 0
+	// keeps the manager script's default of the RPC port plus one; New
+	// rejects values outside 0-65535 and a value equal to the RPC port.
+	P2PPort int
+
+	// Wallets names wallets StartContext creates (or loads, when they
+	// already exist in a reused DataDir) once RPC is answering, so the
+	// common start-then-EnsureWallet setup needs no code. With several
+	// wallets loaded, wallet RPCs must name one (e.g. SpendableOutPoints'
+	// walletName); the default-wallet helpers need exactly one. New rejects
+	// empty and duplicate names. Default nil (no wallets).
+	Wallets []string
+
+	// MineToMaturity makes StartContext, after creating Wallets, mine to
+	// height 101 paying a fresh bech32 address of the first wallet, so it
+	// holds one spendable 50 BTC coinbase output. A reused chain already at
+	// that height is left alone. New rejects it without Wallets. Default
+	// false.
+	MineToMaturity bool
 }
 
 // Regtest manages a Bitcoin regtest node instance.
@@ -81,6 +301,39 @@ type Regtest struct {
 	variantMu     sync.Mutex
 	variantCached bool
 	variant       Variant
+
+	// heightMu guards the Config.CacheHeight state. heightValid is false
+	// until GetBlockCount primes the cache; peered disables the cache for
+	// the rest of the node's lifetime once P2P peers may deliver blocks.
+	// heightGen counts tip changes and invalidations, and mining counts
+	// block-producing RPCs in flight, so a getblockcount reading that raced
+	// one is not stored.
+	heightMu    sync.Mutex
+	heightValid bool
+	height      int64
+	peered      bool
+	heightGen   uint64
+	mining      int
+
+	// mockMu guards mockTime, the last mocktime set through this instance
+	// (0 when none). bitcoind can't report mocktime, so SkewMockTime reads
+	// it from here.
+	mockMu   sync.Mutex
+	mockTime int64
+
+	// startCmdMu guards startCmd, the bitcoind argv recorded by the most
+	// recent StartContext (nil before the first Start).
+	startCmdMu sync.Mutex
+	startCmd   []string
+
+	// frozen is set by FreezeChain; block-producing methods refuse to mine
+	// while it is true.
+	frozen atomic.Bool
+
+	// recMu guards recorder, the proxy StartRecording routes RPCs through
+	// (nil when not recording). Never acquire clientMu while holding it.
+	recMu    sync.Mutex
+	recorder *rpcRecorder
 }
 
 // New creates a new Regtest instance with the provided configuration.
@@ -98,7 +351,8 @@ type Regtest struct {
 //
 // Returns:
 //   - *Regtest: A new Regtest instance
-//   - error: Detailed error if initialization fails
+//   - error: Detailed error if initialization fails; wraps
+//     ErrBitcoindNotFound when no bitcoind can be resolved (see NewMaybe)
 //
 // Example:
 //
@@ -109,6 +363,41 @@ type Regtest struct {
 //	defer rt.Stop()
 //	err = rt.Start()
 func New(config *Config) (*Regtest, error) {
+	return newRegtest(config, false)
+}
+
+// NewMaybe is New without the hard dependency on an installed bitcoind: when
+// the binary can't be resolved, construction still succeeds and the lookup is
+// retried by Start, which returns an error wrapping ErrBitcoindNotFound if it
+// still fails. Invalid Config values are rejected exactly as by New. Use it
+// so test files build and skip cleanly on machines without Bitcoin Core.
+//
+// Parameters:
+//   - config: Configuration for the regtest node (nil for defaults)
+//
+// Returns:
+//   - *Regtest: A new Regtest instance
+//   - error: Config validation or temp-file errors; never ErrBitcoindNotFound
+//
+// Example:
+//
+//	rt, err := regtest.NewMaybe(nil)
+//	if err != nil {
+//	    t.Fatal(err)
+//	}
+//	if err := rt.Start(); errors.Is(err, regtest.ErrBitcoindNotFound) {
+//	    t.Skip(err)
+//	} else if err != nil {
+//	    t.Fatal(err)
+//	}
+//	defer rt.Stop()
+func NewMaybe(config *Config) (*Regtest, error) {
+	return newRegtest(config, true)
+}
+
+// newRegtest implements New and NewMaybe; lazy defers a failed binary lookup
+// to Start.
+func newRegtest(config *Config, lazy bool) (*Regtest, error) {
 	rt := &Regtest{}
 
 	// Use default config if none provided
@@ -116,28 +405,25 @@ func New(config *Config) (*Regtest, error) {
 		rt.config = DefaultConfig()
 	} else {
 		// Store a copy to prevent external modifications
-		rt.config = &Config{
-			Host:            config.Host,
-			User:            config.User,
-			Pass:            config.Pass,
-			DataDir:         config.DataDir,
-			ExtraArgs:       append([]string(nil), config.ExtraArgs...),
-			VBParams:        append([]VBParam(nil), config.VBParams...),
-			AcceptNonstdTxn: config.AcceptNonstdTxn,
-			BinaryPath:      config.BinaryPath,
-		}
+		rt.config = config.clone()
 	}
 
-	// Validate VBParams: empty Deployment is a configuration mistake we
-	// catch eagerly rather than letting bitcoind silently ignore the flag.
-	for i, vb := range rt.config.VBParams {
-		if vb.Deployment == "" {
-			return nil, fmt.Errorf("VBParams[%d].Deployment must not be empty", i)
+	if err := rt.config.validate(); err != nil {
+		return nil, err
+	}
+	if rt.config.ConfFile != "" {
+		abs, err := filepath.Abs(rt.config.ConfFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve ConfFile: %w", err)
 		}
+		rt.config.ConfFile = abs
+	}
+	for _, cat := range unknownDebugCategories(rt.config.DebugCategories) {
+		log.Printf("regtest: unknown -debug category %q (forwarding to bitcoind anyway)", cat)
 	}
 
 	// Initialize immediately
-	if err := rt.initialize(); err != nil {
+	if err := rt.initialize(lazy); err != nil {
 		return nil, err
 	}
 
@@ -172,18 +458,182 @@ func DefaultConfig() *Config {
 // Returns:
 //   - *Config: A copy of the configuration
 func (r *Regtest) Config() *Config {
+	return r.config.clone()
+}
+
+// validate checks Config fields that bitcoind would otherwise ignore or
+// reject with a less actionable error at Start.
+func (c *Config) validate() error {
+	// Empty Deployment is a configuration mistake we catch eagerly rather
+	// than letting bitcoind silently ignore the flag.
+	for i, vb := range c.VBParams {
+		if vb.Deployment == "" {
+			return fmt.Errorf("VBParams[%d].Deployment must not be empty", i)
+		}
+	}
+	limits := []struct {
+		name  string
+		value int
+	}{
+		{"LimitAncestorCount", c.LimitAncestorCount},
+		{"LimitDescendantCount", c.LimitDescendantCount},
+		{"LimitAncestorSize", c.LimitAncestorSize},
+		{"LimitDescendantSize", c.LimitDescendantSize},
+		{"MaxConnections", c.MaxConnections},
+	}
+	for _, l := range limits {
+		if l.value < 0 {
+			return fmt.Errorf("%s must be >= 0 (0 keeps the bitcoind default), got %d", l.name, l.value)
+		}
+	}
+	if c.ChangeType != "" && !knownAddressTypes[c.ChangeType] {
+		return fmt.Errorf("ChangeType must be one of legacy, p2sh-segwit, bech32, bech32m; got %q", c.ChangeType)
+	}
+	if c.AssumeValid != "" && c.AssumeValid != "0" {
+		if _, err := hex.DecodeString(c.AssumeValid); err != nil || len(c.AssumeValid) != 2*chainhash.HashSize {
+			return fmt.Errorf("AssumeValid must be \"0\" or a 64-character hex block hash, got %q", c.AssumeValid)
+		}
+	}
+	fees := []struct {
+		name  string
+		value float64
+	}{
+		{"MaxTxFee", c.MaxTxFee},
+		{"BlockMinTxFee", c.BlockMinTxFee},
+	}
+	for _, f := range fees {
+		if f.value < 0 || math.IsNaN(f.value) || math.IsInf(f.value, 0) {
+			return fmt.Errorf("%s must be a finite BTC amount >= 0 (0 keeps the bitcoind default), got %v", f.name, f.value)
+		}
+	}
+	if c.MinimumChainWork != "" && !isHexWork(c.MinimumChainWork) {
+		return fmt.Errorf("MinimumChainWork must be a hex number of at most 64 digits, got %q", c.MinimumChainWork)
+	}
+	if v := c.RPCSerialVersion; v != nil && *v != 0 && *v != 1 {
+		return fmt.Errorf("RPCSerialVersion must be 0 or 1, got %d", *v)
+	}
+	if c.P2PPort < 0 || c.P2PPort > 65535 {
+		return fmt.Errorf("P2PPort must be in 0-65535 (0 uses the RPC port + 1), got %d", c.P2PPort)
+	}
+	if _, rpcPort, _ := strings.Cut(c.Host, ":"); c.P2PPort > 0 && rpcPort == strconv.Itoa(c.P2PPort) {
+		return fmt.Errorf("P2PPort %d collides with the RPC port in Host %q", c.P2PPort, c.Host)
+	}
+	if err := validateWallets(c.Wallets, c.MineToMaturity); err != nil {
+		return err
+	}
+	for i, kv := range c.Env {
+		if key, _, ok := strings.Cut(kv, "="); !ok || key == "" {
+			return fmt.Errorf("Env[%d] must be a KEY=value pair, got %q", i, kv)
+		}
+	}
+	if c.ConfFile != "" {
+		info, err := os.Stat(c.ConfFile)
+		if err != nil {
+			return fmt.Errorf("ConfFile: %w", err)
+		}
+		if info.IsDir() {
+			return fmt.Errorf("ConfFile %s is a directory", c.ConfFile)
+		}
+	}
+	return nil
+}
+
+// isHexWork reports whether s is a chain-work value bitcoind accepts: 1 to
+// 64 hex digits with an optional "0x" prefix.
+func isHexWork(s string) bool {
+	digits := strings.TrimPrefix(s, "0x")
+	if digits == "" || len(digits) > 2*chainhash.HashSize {
+		return false
+	}
+	for _, ch := range digits {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", ch) {
+			return false
+		}
+	}
+	return true
+}
+
+// knownAddressTypes are the wallet address types bitcoind accepts for
+// -changetype and -addresstype.
+var knownAddressTypes = map[string]bool{
+	"legacy": true, "p2sh-segwit": true, "bech32": true, "bech32m": true,
+}
+
+// knownDebugCategories is the union of -debug categories accepted by recent
+// Bitcoin Core releases, plus the "all"/"1" and "none"/"0" switches.
+var knownDebugCategories = map[string]bool{
+	"0": true, "1": true, "all": true, "none": true,
+	"addrman": true, "bench": true, "blockstorage": true, "cmpctblock": true,
+	"coindb": true, "estimatefee": true, "http": true, "i2p": true,
+	"ipc": true, "leveldb": true, "libevent": true, "lock": true,
+	"mempool": true, "mempoolrej": true, "net": true, "prune": true,
+	"proxy": true, "qt": true, "rand": true, "reindex": true, "rpc": true,
+	"scan": true, "selectcoins": true, "tor": true, "txpackages": true,
+	"txreconciliation": true, "util": true, "validation": true,
+	"walletdb": true, "zmq": true,
+}
+
+// unknownDebugCategories returns the entries of cats that bitcoind is not
+// known to accept, in input order.
+func unknownDebugCategories(cats []string) []string {
+	var unknown []string
+	for _, c := range cats {
+		if !knownDebugCategories[c] {
+			unknown = append(unknown, c)
+		}
+	}
+	return unknown
+}
+
+// clone returns a deep copy of c. Slice fields are copied so neither the
+// caller's Config nor the one handed back by Config() aliases the instance's
+// internal state. New Config fields must be added here.
+func (c *Config) clone() *Config {
 	return &Config{
-		Host:            r.config.Host,
-		User:            r.config.User,
-		Pass:            r.config.Pass,
-		DataDir:         r.config.DataDir,
-		ExtraArgs:       append([]string(nil), r.config.ExtraArgs...),
-		VBParams:        append([]VBParam(nil), r.config.VBParams...),
-		AcceptNonstdTxn: r.config.AcceptNonstdTxn,
-		BinaryPath:      r.config.BinaryPath,
+		Host:                 c.Host,
+		User:                 c.User,
+		Pass:                 c.Pass,
+		DataDir:              c.DataDir,
+		ExtraArgs:            append([]string(nil), c.ExtraArgs...),
+		VBParams:             append([]VBParam(nil), c.VBParams...),
+		AcceptNonstdTxn:      c.AcceptNonstdTxn,
+		BinaryPath:           c.BinaryPath,
+		ExternalSigner:       c.ExternalSigner,
+		CacheHeight:          c.CacheHeight,
+		UAComment:            c.UAComment,
+		DebugCategories:      append([]string(nil), c.DebugCategories...),
+		BlocksOnly:           c.BlocksOnly,
+		LimitAncestorCount:   c.LimitAncestorCount,
+		LimitDescendantCount: c.LimitDescendantCount,
+		LimitAncestorSize:    c.LimitAncestorSize,
+		LimitDescendantSize:  c.LimitDescendantSize,
+		ConfFile:             c.ConfFile,
+		PersistMempoolV1:     c.PersistMempoolV1,
+		ChangeType:           c.ChangeType,
+		ReuseDataDir:         c.ReuseDataDir,
+		AssumeValid:          c.AssumeValid,
+		MaxTxFee:             c.MaxTxFee,
+		BlockMinTxFee:        c.BlockMinTxFee,
+		MaxConnections:       c.MaxConnections,
+		Env:                  append([]string(nil), c.Env...),
+		FastPrune:            c.FastPrune,
+		MinimumChainWork:     c.MinimumChainWork,
+		RPCSerialVersion:     cloneIntPtr(c.RPCSerialVersion),
+		P2PPort:              c.P2PPort,
+		Wallets:              append([]string(nil), c.Wallets...),
+		MineToMaturity:       c.MineToMaturity,
 	}
 }
 
+// cloneIntPtr returns a pointer to a copy of *p, or nil when p is nil.
+func cloneIntPtr(p *int) *int {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
 // RPCConfig returns an RPC client configuration for connecting to this regtest node.
 // This uses the configuration provided when creating the Regtest instance.
 //
@@ -230,6 +680,10 @@ func (r *Regtest) Start() error {
 //
 // The function:
 //   - Executes the bitcoind manager script with the "start" command
+//   - Waits (up to connectTimeout) until the RPC client's getblockcount
+//     succeeds, so the node is answering when StartContext returns
+//   - Creates or loads Config.Wallets and, with Config.MineToMaturity, mines
+//     a mature coinbase to the first
 //   - Returns detailed error information if startup fails
 //   - Uses mutex locking to prevent race conditions
 //   - Respects context cancellation
@@ -258,7 +712,63 @@ func (r *Regtest) Start() error {
 func (r *Regtest) StartContext(ctx context.Context) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	return r.startLocked(ctx, r.config.ReuseDataDir)
+}
+
+// EnsureStarted makes sure a node is answering on this instance's RPC
+// endpoint: if one already is — e.g. a shared dev node, or one left running
+// by an earlier process with the same Config — it is adopted and the RPC
+// client (re)connected; otherwise the node is started as by StartContext.
+// The check and the start happen under the lifecycle lock, so concurrent
+// EnsureStarted calls start at most one node.
+//
+// An adopted node is treated like one this instance started: Stop shuts it
+// down. Its height cache and tracked mocktime start out empty, and
+// Config.Wallets and MineToMaturity are applied to it just as after a Start.
+//
+// Parameters:
+//   - ctx: bounds both the liveness probe and a Start, if one is needed
+//
+// Returns:
+//   - error: nil once a node is reachable; the probe's error when the
+//     endpoint answers but rejects the request (e.g. wrong credentials);
+//     otherwise the StartContext error.
+//
+// Example:
+//
+//	if err := rt.EnsureStarted(ctx); err != nil {
+//	    t.Fatal(err)
+//	}
+func (r *Regtest) EnsureStarted(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
+	running, err := r.IsRunningContext(ctx)
+	if err != nil {
+		return fmt.Errorf("check running node: %w", err)
+	}
+	if !running {
+		return r.startLocked(ctx, r.config.ReuseDataDir)
+	}
+	if err := r.attachLocked(ctx); err != nil {
+		return fmt.Errorf("adopted node: %w", err)
+	}
+	return nil
+}
+
+// startLocked is StartContext's body; the caller must hold r.mu. With
+// reuseDataDir the script keeps an existing datadir instead of wiping it.
+func (r *Regtest) startLocked(ctx context.Context, reuseDataDir bool) error {
+	if r.bitcoindPath == "" {
+		// Built by NewMaybe without a bitcoind; it may have been installed
+		// since.
+		bitcoindPath, bitcoinCliPath, err := resolveBinary(r.config.BinaryPath)
+		if err != nil {
+			return err
+		}
+		r.bitcoindPath = bitcoindPath
+		r.bitcoinCliPath = bitcoinCliPath
+	}
 	port := r.extractPort()
 
 	// Pass config parameters to script: start datadir port user pass [extra-args...].
@@ -266,18 +776,114 @@ func (r *Regtest) StartContext(ctx context.Context) error {
 	// -acceptnonstdtxn; the script forwards them verbatim to bitcoind (see
 	// scripts/bitcoind_manager.sh).
 	scriptArgs := append([]string{r.scriptPath, "start", r.config.DataDir, port, r.config.User, r.config.Pass}, r.config.renderExtraArgs()...)
+	cmdFile := filepath.Join(filepath.Dir(r.scriptPath), "start_command")
+	if err := os.Remove(cmdFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to clear recorded start command: %w", err)
+	}
 	cmd := exec.CommandContext(ctx, "bash", scriptArgs...)
-	cmd.Env = append(os.Environ(), "BITCOIND_BIN="+r.bitcoindPath, "BITCOIN_CLI_BIN="+r.bitcoinCliPath)
+	cmd.Env = append(r.scriptEnv(reuseDataDir), "REGTEST_CMD_FILE="+cmdFile)
 	output, err := cmd.CombinedOutput()
+	r.recordStartCommand(cmdFile)
 	if err != nil {
 		if ctx.Err() != nil {
 			return fmt.Errorf("start cancelled: %w", ctx.Err())
 		}
+		if err := r.config.unsupportedFlagError(string(output)); err != nil {
+			return err
+		}
 		return fmt.Errorf("failed to start bitcoind (script: %s): %s", r.scriptPath, string(output))
 	}
 
-	// Now that node is started, create RPC client
-	return r.connectClient()
+	return r.attachLocked(ctx)
+}
+
+// attachLocked finishes bringing up a node that is already running, whether
+// startLocked just launched it or EnsureStarted adopted it: it clears the
+// per-node caches, connects the RPC client, waits for RPC to answer and sets
+// up Config.Wallets. The caller must hold r.mu.
+func (r *Regtest) attachLocked(ctx context.Context) error {
+	r.resetHeightCache()
+	r.mockMu.Lock()
+	r.mockTime = 0
+	r.mockMu.Unlock()
+
+	if err := r.connectClient(); err != nil {
+		return err
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, connectTimeout)
+	defer cancel()
+	if err := r.WaitForConnection(waitCtx); err != nil {
+		return fmt.Errorf("bitcoind is up but RPC is not answering: %w", err)
+	}
+	return r.setupWallets(ctx)
+}
+
+// connectTimeout bounds how long StartContext and EnsureStarted wait for the
+// node to answer RPC.
+const connectTimeout = 30 * time.Second
+
+// unsupportedFlagError returns an error wrapping ErrUnsupportedVersion when
+// the manager script's output shows bitcoind rejected a flag that only some
+// Core versions accept, or nil when it doesn't.
+func (c *Config) unsupportedFlagError(output string) error {
+	if c.PersistMempoolV1 && strings.Contains(output, "-persistmempoolv1") {
+		return fmt.Errorf("%w: PersistMempoolV1 requires Bitcoin Core 26.0.0 or newer: %s",
+			ErrUnsupportedVersion, output)
+	}
+	if c.RPCSerialVersion != nil && strings.Contains(output, "-rpcserialversion") {
+		return fmt.Errorf("%w: RPCSerialVersion requires Bitcoin Core 27 or older: %s",
+			ErrUnsupportedVersion, output)
+	}
+	return nil
+}
+
+// scriptEnv is the environment the manager script runs with: the caller's
+// environment overlaid with Config.Env, plus the resolved binaries and, with
+// reuseDataDir, the marker that stops start/stop from wiping the datadir.
+// exec.Cmd keeps the last value of a duplicated key, so later entries win.
+func (r *Regtest) scriptEnv(reuseDataDir bool) []string {
+	env := append(os.Environ(), r.config.Env...)
+	env = append(env,
+		"BITCOIND_BIN="+r.bitcoindPath,
+		"BITCOIN_CLI_BIN="+r.bitcoinCliPath)
+	if reuseDataDir {
+		env = append(env, "REGTEST_REUSE_DATADIR=1")
+	}
+	if r.config.P2PPort > 0 {
+		env = append(env, "REGTEST_P2P_PORT="+strconv.Itoa(r.config.P2PPort))
+	}
+	return env
+}
+
+// LastStartCommand returns the argument vector the manager script passed to
+// bitcoind on the most recent StartContext, binary path first, with every
+// flag rendered from Config. It is recorded before bitcoind launches, so it
+// is available after a failed start too — copy it into a shell to reproduce
+// the failure by hand. Returns nil before the first Start, or when the
+// script bailed out before launching bitcoind (e.g. the port was in use).
+// The result includes -rpcpassword.
+//
+// Example:
+//
+//	if err := rt.Start(); err != nil {
+//	    t.Fatalf("start failed: %v\ncommand: %s", err, strings.Join(rt.LastStartCommand(), " "))
+//	}
+func (r *Regtest) LastStartCommand() []string {
+	r.startCmdMu.Lock()
+	defer r.startCmdMu.Unlock()
+	return append([]string(nil), r.startCmd...)
+}
+
+// recordStartCommand stores the NUL-separated argv the manager script wrote
+// to path, or nil when the script didn't write one.
+func (r *Regtest) recordStartCommand(path string) {
+	var argv []string
+	if data, err := os.ReadFile(path); err == nil && len(data) > 0 {
+		argv = strings.Split(strings.TrimSuffix(string(data), "\x00"), "\x00")
+	}
+	r.startCmdMu.Lock()
+	r.startCmd = argv
+	r.startCmdMu.Unlock()
 }
 
 // Stop stops the Bitcoin regtest node and performs cleanup.
@@ -287,7 +893,8 @@ func (r *Regtest) StartContext(ctx context.Context) error {
 // The function:
 //   - Sends a stop signal to the running bitcoind process
 //   - Waits for the process to terminate gracefully
-//   - Cleans up data directories and temporary files
+//   - Cleans up data directories and temporary files (the datadir is kept
+//     when Config.ReuseDataDir is set)
 //   - Removes temporary script directory
 //   - Uses mutex locking to prevent race conditions
 //
@@ -308,7 +915,12 @@ func (r *Regtest) StartContext(ctx context.Context) error {
 func (r *Regtest) Stop() error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	return r.stopLocked(context.Background(), r.config.ReuseDataDir)
+}
 
+// stopLocked is Stop's body; the caller must hold r.mu. With reuseDataDir
+// the script leaves the datadir in place.
+func (r *Regtest) stopLocked(ctx context.Context, reuseDataDir bool) error {
 	// Shutdown RPC client if it exists
 	r.clientMu.Lock()
 	if r.client != nil {
@@ -316,12 +928,14 @@ func (r *Regtest) Stop() error {
 		r.client = nil
 	}
 	r.clientMu.Unlock()
+	unregisterLocalNode(r)
+	r.resetHeightCache()
 
 	port := r.extractPort()
 
 	// Pass config parameters to script: stop datadir port user pass
-	cmd := exec.Command("bash", r.scriptPath, "stop", r.config.DataDir, port, r.config.User, r.config.Pass)
-	cmd.Env = append(os.Environ(), "BITCOIND_BIN="+r.bitcoindPath, "BITCOIN_CLI_BIN="+r.bitcoinCliPath)
+	cmd := exec.CommandContext(ctx, "bash", r.scriptPath, "stop", r.config.DataDir, port, r.config.User, r.config.Pass)
+	cmd.Env = r.scriptEnv(reuseDataDir)
 	output, err := cmd.CombinedOutput()
 
 	// Note: The temporary script dir is cleaned up by Cleanup().
@@ -333,6 +947,80 @@ func (r *Regtest) Stop() error {
 	return nil
 }
 
+// portReleaseTimeout bounds how long RestartContext waits for the stopped
+// node to release its RPC port.
+const portReleaseTimeout = 30 * time.Second
+
+// Restart bounces the node: it stops bitcoind, waits until the RPC port is
+// free and nothing answers on it, then starts bitcoind again on the same
+// DataDir and reconnects the RPC client. The datadir is kept regardless of
+// Config.ReuseDataDir, so the chain, wallets and mempool.dat survive.
+// Convenience wrapper around RestartContext using context.Background().
+//
+// As after any bitcoind restart, wallets are not reloaded (call
+// EnsureWallet again), and the height cache and tracked mocktime start out
+// empty. Use it to test how code reacts to the node going away mid-run.
+//
+// Returns:
+//   - error: wrapped with "restart: stop" or "restart: start" naming the
+//     phase that failed; waiting for the port counts as the stop phase.
+//
+// Example:
+//
+//	if err := rt.Restart(); err != nil {
+//	    t.Fatal(err)
+//	}
+//	if err := rt.EnsureWallet(walletName); err != nil {
+//	    t.Fatal(err)
+//	}
+func (r *Regtest) Restart() error {
+	return r.RestartContext(context.Background())
+}
+
+// RestartContext is the context-aware variant of Restart. ctx bounds the
+// whole bounce; the wait for the port is additionally capped at 30 seconds.
+func (r *Regtest) RestartContext(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.stopLocked(ctx, true); err != nil {
+		return fmt.Errorf("restart: stop: %w", err)
+	}
+	if err := r.waitPortReleased(ctx); err != nil {
+		return fmt.Errorf("restart: stop: %w", err)
+	}
+	if err := r.startLocked(ctx, true); err != nil {
+		return fmt.Errorf("restart: start: %w", err)
+	}
+	return nil
+}
+
+// waitPortReleased polls until no node answers on the configured RPC host
+// and the port can be bound again, or portReleaseTimeout or ctx expires.
+func (r *Regtest) waitPortReleased(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, portReleaseTimeout)
+	defer cancel()
+	const interval = 100 * time.Millisecond
+	for {
+		running, err := r.IsRunningContext(ctx)
+		if err == nil && !running {
+			var ln net.Listener
+			ln, err = net.Listen("tcp", r.config.Host)
+			if err == nil {
+				return ln.Close()
+			}
+		}
+		select {
+		case <-ctx.Done():
+			if err == nil {
+				err = errors.New("node still answering")
+			}
+			return fmt.Errorf("waiting for %s to be released: %w (last: %v)", r.config.Host, ctx.Err(), err)
+		case <-time.After(interval):
+		}
+	}
+}
+
 // Cleanup removes temporary files and directories created by this Regtest instance.
 // It is safe to call multiple times. Stop() does not invoke Cleanup() automatically;
 // call it explicitly when you are completely done with the instance.
@@ -351,6 +1039,10 @@ func (r *Regtest) Cleanup() error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	if err := r.StopRecording(); err != nil {
+		return fmt.Errorf("failed to stop recording: %w", err)
+	}
+
 	if r.scriptTmpDir != "" {
 		if err := os.RemoveAll(r.scriptTmpDir); err != nil {
 			return fmt.Errorf("failed to clean up temp directory: %w", err)
@@ -361,6 +1053,111 @@ func (r *Regtest) Cleanup() error {
 	return nil
 }
 
+// managedMarker is the file bitcoind_manager.sh creates in every datadir it
+// starts bitcoind on. KillOrphans only touches bitcoind processes whose
+// -datadir contains it.
+const managedMarker = ".go-regtest"
+
+// managedDataDirRe extracts the -datadir argument from a bitcoind command line.
+var managedDataDirRe = regexp.MustCompile(`(?:^|\s)-datadir=(\S+)`)
+
+// KillOrphans terminates every bitcoind process this library started —
+// recognised by the marker file the manager script leaves in its datadir —
+// and returns how many it stopped. Unrelated bitcoind instances, including
+// other regtest nodes, are left alone. Each process gets SIGTERM and up to
+// 10 seconds to shut down before SIGKILL. Datadirs are not removed. A
+// relative -datadir is resolved against the current working directory, so
+// orphans of a run started elsewhere with a relative DataDir are missed.
+//
+// It is a test-suite cleanup aid for reclaiming ports and memory after a
+// crashed or interrupted run, e.g. from TestMain. It kills live nodes too,
+// including ones owned by other Regtest instances or concurrently running
+// test binaries, so call it only when none should be running. Unix only:
+// processes are listed with ps.
+//
+// Returns:
+//   - int: number of processes terminated
+//   - error: ps failure, or the joined errors for processes that couldn't
+//     be signalled (the count covers the rest).
+//
+// Example:
+//
+//	func TestMain(m *testing.M) {
+//	    if n, err := regtest.KillOrphans(); err == nil && n > 0 {
+//	        log.Printf("killed %d orphaned bitcoind processes", n)
+//	    }
+//	    os.Exit(m.Run())
+//	}
+func KillOrphans() (int, error) {
+	out, err := exec.Command("ps", "-axo", "pid=,args=").Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list processes: %w", err)
+	}
+	killed := 0
+	var errs []error
+	for _, pid := range managedPIDs(string(out), isManagedDataDir) {
+		if err := terminate(pid); err != nil {
+			errs = append(errs, fmt.Errorf("pid %d: %w", pid, err))
+			continue
+		}
+		killed++
+	}
+	return killed, errors.Join(errs...)
+}
+
+// managedPIDs returns the pids of regtest bitcoind processes in psOutput
+// (lines of "<pid> <args>") whose -datadir satisfies managed.
+func managedPIDs(psOutput string, managed func(dataDir string) bool) []int {
+	var pids []int
+	for _, line := range strings.Split(psOutput, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !strings.Contains(filepath.Base(fields[1]), "bitcoind") {
+			continue
+		}
+		args := strings.Join(fields[1:], " ")
+		m := managedDataDirRe.FindStringSubmatch(args)
+		if m == nil || !slices.Contains(fields[1:], "-regtest") || !managed(m[1]) {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		pids = append(pids, pid)
+	}
+	return pids
+}
+
+// isManagedDataDir reports whether dataDir carries the manager script's
+// marker file.
+func isManagedDataDir(dataDir string) bool {
+	_, err := os.Stat(filepath.Join(dataDir, managedMarker))
+	return err == nil
+}
+
+// terminate sends pid SIGTERM, waits up to 10 seconds for it to exit, then
+// sends SIGKILL.
+func terminate(pid int) error {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	if err := p.Signal(syscall.SIGTERM); err != nil {
+		return err
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		if p.Signal(syscall.Signal(0)) != nil {
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if err := p.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return err
+	}
+	return nil
+}
+
 // IsRunning checks if the Bitcoin regtest node is currently running by
 // attempting a short-timeout RPC call against the configured host. It does not
 // depend on the embedded manager script, so it remains valid after Cleanup().
@@ -436,14 +1233,72 @@ func isConnRefusedErr(err error) bool {
 		strings.Contains(msg, "EOF")
 }
 
+// String returns a one-line summary of the instance for logs and test
+// failure output: host, data dir, and — when the RPC client is connected —
+// chain height, loaded wallets, and mempool size, e.g.
+//
+//	regtest{host=127.0.0.1:18443 datadir=./bitcoind_regtest state=running height=101 wallets=[miner user] mempool=2}
+//
+// It never fails: before Start (or after Stop) the state reads
+// "disconnected" and no RPC is attempted, an unresponsive node reads
+// "unreachable", and any individual field that can't be fetched renders as
+// "?". The RPC calls share a 2-second budget, so t.Log(rt) can't hang a test.
+//
+// Example:
+//
+//	if err := doThing(rt); err != nil {
+//	    t.Fatalf("doThing: %v (node: %s)", err, rt)
+//	}
+func (r *Regtest) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "regtest{host=%s datadir=%s", r.config.Host, r.config.DataDir)
+
+	if _, err := r.lockedClient(); err != nil {
+		b.WriteString(" state=disconnected")
+		return b.String() + "}"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	height, err := r.GetBlockCountContext(ctx)
+	if err != nil {
+		b.WriteString(" state=unreachable")
+		return b.String() + "}"
+	}
+	fmt.Fprintf(&b, " state=running height=%d", height)
+
+	wallets := "?"
+	if raw, err := r.rawRPC(ctx, "listwallets"); err == nil {
+		var names []string
+		if json.Unmarshal(raw, &names) == nil {
+			wallets = "[" + strings.Join(names, " ") + "]"
+		}
+	}
+	fmt.Fprintf(&b, " wallets=%s", wallets)
+
+	mempool := "?"
+	if raw, err := r.rawRPC(ctx, "getmempoolinfo"); err == nil {
+		var info struct {
+			Size int64 `json:"size"`
+		}
+		if json.Unmarshal(raw, &info) == nil {
+			mempool = fmt.Sprint(info.Size)
+		}
+	}
+	fmt.Fprintf(&b, " mempool=%s", mempool)
+	return b.String() + "}"
+}
+
 // initialize performs one-time initialization of the Regtest instance.
 // It resolves the bitcoind / bitcoin-cli binaries (honoring Config.BinaryPath
 // or auto-detecting on PATH) and writes the embedded bitcoind manager script
-// to a temporary file.
-func (r *Regtest) initialize() error {
+// to a temporary file. With lazy set, a missing bitcoind leaves the paths
+// empty for startLocked to resolve instead of failing.
+func (r *Regtest) initialize(lazy bool) error {
 	// Resolve the bitcoind binary (Config.BinaryPath if set, else PATH chain).
 	bitcoindPath, bitcoinCliPath, err := resolveBinary(r.config.BinaryPath)
-	if err != nil {
+	if err != nil && !(lazy && errors.Is(err, ErrBitcoindNotFound)) {
 		return err
 	}
 	r.bitcoindPath = bitcoindPath
@@ -514,7 +1369,7 @@ func resolveBitcoind(path string) (string, error) {
 	if path != "" {
 		p, err := exec.LookPath(path)
 		if err != nil {
-			return "", fmt.Errorf("Config.BinaryPath %q: %w", path, err)
+			return "", fmt.Errorf("%w: Config.BinaryPath %q: %w", ErrBitcoindNotFound, path, err)
 		}
 		return p, nil
 	}
@@ -524,7 +1379,7 @@ func resolveBitcoind(path string) (string, error) {
 	if p, err := exec.LookPath("bitcoind"); err == nil {
 		return p, nil
 	}
-	return "", fmt.Errorf("bitcoind not found in PATH (tried bitcoind-inquisition, bitcoind) — install Bitcoin Core or set Config.BinaryPath")
+	return "", fmt.Errorf("%w in PATH (tried bitcoind-inquisition, bitcoind) — install Bitcoin Core or set Config.BinaryPath", ErrBitcoindNotFound)
 }
 
 // resolveBitcoinCli looks for bitcoin-cli alongside the resolved bitcoind
@@ -554,11 +1409,12 @@ func (r *Regtest) connectClient() error {
 		return nil // Already connected
 	}
 
-	client, err := rpcclient.New(r.RPCConfig(), nil)
+	client, err := rpcclient.New(r.dialConfig(), nil)
 	if err != nil {
 		return fmt.Errorf("failed to create RPC client: %w", err)
 	}
 
 	r.client = client
+	registerLocalNode(r)
 	return nil
 }
@@ -4,11 +4,18 @@ import (
 	"bytes"
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -520,10 +527,123 @@ func TestRPC_Warp_ValidationErrors(t *testing.T) {
 			if err := rt.Warp(tc.blocks, tc.miner); err == nil {
 				t.Errorf("expected validation error for blocks=%d miner=%q, got nil", tc.blocks, tc.miner)
 			}
+			if _, err := rt.WarpBlocks(tc.blocks, tc.miner); err == nil {
+				t.Errorf("expected WarpBlocks validation error for blocks=%d miner=%q, got nil", tc.blocks, tc.miner)
+			}
+			if _, err := rt.WarpToWallet(tc.blocks, tc.miner); err == nil {
+				t.Errorf("expected WarpToWallet validation error for blocks=%d wallet=%q, got nil", tc.blocks, tc.miner)
+			}
 		})
 	}
 }
 
+func TestRPC_WarpBlocks(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	const miner = "bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl"
+	start, err := rt.GetBlockCount()
+	if err != nil {
+		t.Fatalf("GetBlockCount: %v", err)
+	}
+	hashes, err := rt.WarpBlocks(5, miner)
+	if err != nil {
+		t.Fatalf("WarpBlocks: %v", err)
+	}
+	if len(hashes) != 5 {
+		t.Fatalf("WarpBlocks returned %d hashes, want 5", len(hashes))
+	}
+	for i, hash := range hashes {
+		height := start + int64(i) + 1
+		want, err := rt.GetBlockHash(height)
+		if err != nil {
+			t.Fatalf("GetBlockHash(%d): %v", height, err)
+		}
+		if !hash.IsEqual(want) {
+			t.Errorf("hashes[%d] = %s, want block %d %s", i, hash, height, want)
+		}
+	}
+}
+
+// TestRPC_WarpToWallet mines past maturity into a wallet WarpToWallet
+// creates itself and checks the wallet can spend the first coinbase.
+func TestRPC_WarpToWallet(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	const wallet = "warp_to_wallet"
+	hashes, err := rt.WarpToWallet(101, wallet)
+	if err != nil {
+		t.Fatalf("WarpToWallet: %v", err)
+	}
+	if len(hashes) != 101 {
+		t.Fatalf("WarpToWallet returned %d hashes, want 101", len(hashes))
+	}
+	raw, err := rt.walletRawRPC(context.Background(), wallet, "getbalance")
+	if err != nil {
+		t.Fatalf("getbalance: %v", err)
+	}
+	var balance float64
+	if err := json.Unmarshal(raw, &balance); err != nil {
+		t.Fatalf("unmarshal getbalance: %v", err)
+	}
+	if balance <= 0 {
+		t.Errorf("spendable balance = %v BTC, want > 0", balance)
+	}
+}
+
+// TestRPC_MineToMaturity funds a wallet with MineToMaturity alone and
+// spends from it.
+func TestRPC_MineToMaturity(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	const wallet = "mine_to_maturity"
+	if err := rt.EnsureWallet(wallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(wallet)
+
+	addr, err := rt.GenerateBech32(wallet)
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	start, err := rt.GetBlockCount()
+	if err != nil {
+		t.Fatalf("GetBlockCount: %v", err)
+	}
+	if err := rt.MineToMaturity(addr); err != nil {
+		t.Fatalf("MineToMaturity: %v", err)
+	}
+	if h, err := rt.GetBlockCount(); err != nil || h != start+coinbaseMaturity+1 {
+		t.Errorf("height = %d, %v; want %d", h, err, start+coinbaseMaturity+1)
+	}
+	if _, err := rt.SendToAddress(addr, 100_000_000); err != nil {
+		t.Errorf("SendToAddress after MineToMaturity: %v", err)
+	}
+	if err := rt.MineToMaturity(""); err == nil {
+		t.Error("MineToMaturity(\"\") should fail")
+	}
+}
+
 // TestRPC_SendToAddress_ValidationErrors covers SendToAddressContext's input
 // guards. As with Warp, these short-circuit before any RPC call.
 func TestRPC_SendToAddress_ValidationErrors(t *testing.T) {
@@ -552,6 +672,121 @@ func TestRPC_SendToAddress_ValidationErrors(t *testing.T) {
 	}
 }
 
+func TestRPC_SendToAllAddressTypes(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(minerWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(minerWallet)
+	miner, err := rt.GenerateBech32(minerWallet)
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := rt.Warp(101, miner); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+
+	if _, _, err := rt.SendToAllAddressTypes(0, miner); err == nil {
+		t.Error("SendToAllAddressTypes(0) should fail")
+	}
+	if _, _, err := rt.SendToAllAddressTypes(100_000, ""); err == nil {
+		t.Error("SendToAllAddressTypes without a miner should fail")
+	}
+
+	const satsEach = 100_000
+	txid, addrs, err := rt.SendToAllAddressTypes(satsEach, miner)
+	if err != nil {
+		t.Fatalf("SendToAllAddressTypes: %v", err)
+	}
+	raw, err := rt.Client().GetRawTransaction(txid)
+	if err != nil {
+		t.Fatalf("GetRawTransaction: %v", err)
+	}
+	tx := raw.MsgTx()
+	wantClass := map[string]txscript.ScriptClass{
+		"legacy":      txscript.PubKeyHashTy,
+		"p2sh-segwit": txscript.ScriptHashTy,
+		"bech32":      txscript.WitnessV0PubKeyHashTy,
+		"bech32m":     txscript.WitnessV1TaprootTy,
+	}
+	if len(addrs) != len(wantClass) {
+		t.Fatalf("got %d addresses, want %d: %v", len(addrs), len(wantClass), addrs)
+	}
+	for addrType, class := range wantClass {
+		script, err := ScriptForAddress(addrs[addrType])
+		if err != nil {
+			t.Fatalf("ScriptForAddress(%s): %v", addrs[addrType], err)
+		}
+		if got := txscript.GetScriptClass(script); got != class {
+			t.Errorf("%s address %s has script class %v, want %v", addrType, addrs[addrType], got, class)
+		}
+		found := false
+		for i, out := range tx.TxOut {
+			if bytes.Equal(out.PkScript, script) {
+				found = true
+				rt.AssertUTXO(t, wire.OutPoint{Hash: *txid, Index: uint32(i)}, satsEach, false)
+			}
+		}
+		if !found {
+			t.Errorf("tx %s has no output to the %s address", txid, addrType)
+		}
+	}
+}
+
+func TestRPC_SendToAddressWithFeeRate(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(minerWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(minerWallet)
+	miner, err := rt.GenerateBech32(minerWallet)
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := rt.Warp(101, miner); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+
+	for _, bad := range []struct {
+		sats int64
+		rate float64
+	}{{0, 5}, {1_000, 0}, {1_000, -1}, {1_000, math.NaN()}} {
+		if _, err := rt.SendToAddressWithFeeRate(miner, bad.sats, bad.rate); err == nil {
+			t.Errorf("SendToAddressWithFeeRate(%d, %v) should fail", bad.sats, bad.rate)
+		}
+	}
+
+	txid, err := rt.SendToAddressWithFeeRate(miner, 1_000_000, 5)
+	if err != nil {
+		t.Fatalf("SendToAddressWithFeeRate: %v", err)
+	}
+	entry, err := rt.mempoolEntry(context.Background(), txid)
+	if err != nil {
+		t.Fatalf("getmempoolentry: %v", err)
+	}
+	// The wallet sizes signatures pessimistically, so the real vsize may
+	// come in a vbyte under the one the fee was computed for.
+	if lo, hi := 5*entry.VSize, 5*(entry.VSize+1); int64(entry.Fee) < lo || int64(entry.Fee) > hi {
+		t.Errorf("fee %d sat for %d vB, want %d-%d (5 sat/vB)", int64(entry.Fee), entry.VSize, lo, hi)
+	}
+}
+
 // TestRPC_Concurrent_WarpAndSend stresses the dual-mutex pattern (mu for
 // lifecycle, clientMu for client access) by interleaving Warp (which mines
 // blocks) with SendToAddress (which spends from the wallet). Run under -race
@@ -678,7 +913,10 @@ func TestRPC_ChainState(t *testing.T) {
 		t.Fatalf("GetBlock: %v", err)
 	}
 	if len(block.Transactions) == 0 {
-		t.Error("expected at least one tx (coinbase) in block")
+		t.Fatal("expected at least one tx (coinbase) in block")
+	}
+	if !blockchain.IsCoinBaseTx(block.Transactions[0]) {
+		t.Errorf("first tx of block %s is not a coinbase", bestHash)
 	}
 
 	verbose, err := rt.GetBlockVerbose(bestHash)
@@ -773,6 +1011,52 @@ func TestRPC_MineToHeight(t *testing.T) {
 	}
 }
 
+// TestRPC_WaitForHeight covers the active path (mine the shortfall), the
+// passive path (another goroutine mines) and a passive wait that times out.
+func TestRPC_WaitForHeight(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	const miner = "bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl"
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := rt.WaitForHeight(ctx, 10, miner); err != nil {
+		t.Fatalf("WaitForHeight(10, miner): %v", err)
+	}
+	if h, err := rt.GetBlockCount(); err != nil || h != 10 {
+		t.Errorf("after active WaitForHeight(10), height = %d, %v", h, err)
+	}
+
+	mined := make(chan error, 1)
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		mined <- rt.Warp(5, miner)
+	}()
+	if err := rt.WaitForHeight(ctx, 15, ""); err != nil {
+		t.Fatalf("WaitForHeight(15, passive): %v", err)
+	}
+	if err := <-mined; err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+
+	short, cancelShort := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancelShort()
+	err = rt.WaitForHeight(short, 16, "")
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "last seen 15") {
+		t.Errorf("passive wait past the tip: err = %v, want deadline naming height 15", err)
+	}
+	if err := rt.WaitForHeight(ctx, -1, ""); err == nil {
+		t.Error("WaitForHeight(-1) should error")
+	}
+}
+
 // TestRPC_Reorg_InvalidateReconsider exercises the InvalidateBlock and
 // ReconsiderBlock primitives. After mining 5 blocks, invalidating the tip
 // must drop the chain by one; reconsidering it must restore the original
@@ -896,6 +1180,71 @@ func TestRPC_Reorg_NilHash(t *testing.T) {
 	}
 }
 
+// TestRPC_Reorg_WaitForTip checks WaitForTip returns once the tip matches,
+// follows an InvalidateBlock/ReconsiderBlock round trip, and times out on a
+// hash the node never reaches.
+func TestRPC_Reorg_WaitForTip(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(minerWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(minerWallet)
+	addr, err := rt.GenerateBech32(minerWallet)
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := rt.Warp(2, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	tip, err := rt.GetBestBlockHash()
+	if err != nil {
+		t.Fatalf("GetBestBlockHash: %v", err)
+	}
+	height, err := rt.GetBlockCount()
+	if err != nil {
+		t.Fatalf("GetBlockCount: %v", err)
+	}
+	parent, err := rt.GetBlockHash(height - 1)
+	if err != nil {
+		t.Fatalf("GetBlockHash: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := rt.WaitForTip(ctx, tip); err != nil {
+		t.Fatalf("WaitForTip(current tip): %v", err)
+	}
+	if err := rt.InvalidateBlock(tip); err != nil {
+		t.Fatalf("InvalidateBlock: %v", err)
+	}
+	if err := rt.WaitForTip(ctx, parent); err != nil {
+		t.Fatalf("WaitForTip(parent): %v", err)
+	}
+	if err := rt.ReconsiderBlock(tip); err != nil {
+		t.Fatalf("ReconsiderBlock: %v", err)
+	}
+	if err := rt.WaitForTip(ctx, tip); err != nil {
+		t.Fatalf("WaitForTip(reconsidered tip): %v", err)
+	}
+
+	short, cancelShort := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancelShort()
+	if err := rt.WaitForTip(short, &chainhash.Hash{1}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("WaitForTip(unknown hash) = %v, want context.DeadlineExceeded", err)
+	}
+	if err := rt.WaitForTip(ctx, nil); err == nil {
+		t.Error("WaitForTip(nil) should error")
+	}
+}
+
 // TestRPC_TestMempoolAccept_Valid asks bitcoind to validate a freshly-signed
 // (but unbroadcast) tx. Allowed must be true and Fees must be populated.
 func TestRPC_TestMempoolAccept_Valid(t *testing.T) {
@@ -1213,11 +1562,78 @@ func TestRPC_ChainState_NilHash(t *testing.T) {
 	if _, err := rt.GetBlockVerbose(nil); err == nil {
 		t.Error("GetBlockVerbose(nil) should return validation error")
 	}
+	if _, err := rt.GetBlockVerboseTx(nil); err == nil {
+		t.Error("GetBlockVerboseTx(nil) should return validation error")
+	}
 	if _, err := rt.GetBlockHeader(nil); err == nil {
 		t.Error("GetBlockHeader(nil) should return validation error")
 	}
 }
 
+// TestRPC_GetBlockVerboseTx mines a block holding one wallet send and
+// checks both of its transactions come back decoded.
+func TestRPC_GetBlockVerboseTx(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(userWallet)
+
+	addr, err := rt.GenerateBech32(userWallet)
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := rt.MineToMaturity(addr); err != nil {
+		t.Fatalf("MineToMaturity: %v", err)
+	}
+	txid, err := rt.SendToAddress(addr, 100_000)
+	if err != nil {
+		t.Fatalf("SendToAddress: %v", err)
+	}
+	hashes, err := rt.WarpBlocks(1, addr)
+	if err != nil {
+		t.Fatalf("WarpBlocks: %v", err)
+	}
+
+	block, err := rt.GetBlockVerboseTx(hashes[0])
+	if err != nil {
+		t.Fatalf("GetBlockVerboseTx: %v", err)
+	}
+	if block.Hash != hashes[0].String() {
+		t.Errorf("Hash = %s, want %s", block.Hash, hashes[0])
+	}
+	if len(block.Tx) != 2 {
+		t.Fatalf("block has %d transactions, want 2", len(block.Tx))
+	}
+	if len(block.Tx[0].Vin) != 1 || block.Tx[0].Vin[0].Coinbase == "" {
+		t.Errorf("Tx[0] is not a decoded coinbase: %+v", block.Tx[0].Vin)
+	}
+	sent := block.Tx[1]
+	if sent.Txid != txid.String() {
+		t.Errorf("Tx[1].Txid = %s, want %s", sent.Txid, txid)
+	}
+	if len(sent.Vin) == 0 || len(sent.Vout) == 0 {
+		t.Errorf("Tx[1] not decoded: %d inputs, %d outputs", len(sent.Vin), len(sent.Vout))
+	}
+	found := false
+	for _, out := range sent.Vout {
+		if out.ScriptPubKey.Address == addr && out.Value == 0.001 {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Tx[1] has no 0.001 BTC output to %s", addr)
+	}
+}
+
 // assembleTrivialRegtestBlock builds a minimum valid regtest block on top of
 // tmpl: a single coinbase tx paying to OP_TRUE, with the witness commitment
 // the template provided, then brute-force solves the (trivial) regtest PoW.
@@ -1468,10 +1884,9 @@ func TestRPC_DecodeRawTransaction_Nil(t *testing.T) {
 	}
 }
 
-// TestRPC_DecodeScript_P2TR pins that DecodeScript returns the correct script
-// type and disassembled ASM for a P2TR (Taproot) scriptPubKey. The script is
-// fixed-shape: OP_1 <32-byte x-only pubkey>.
-func TestRPC_DecodeScript_P2TR(t *testing.T) {
+// TestRPC_VirtualSize checks the local Weight and VirtualSize agree with
+// decoderawtransaction for a signed segwit spend.
+func TestRPC_VirtualSize(t *testing.T) {
 	rt, err := New(nil)
 	if err != nil {
 		t.Fatalf("New: %v", err)
@@ -1485,14 +1900,71 @@ func TestRPC_DecodeScript_P2TR(t *testing.T) {
 		t.Fatalf("EnsureWallet: %v", err)
 	}
 	defer rt.UnloadWallet(userWallet)
-
-	taprootAddr, err := rt.GenerateBech32m(userWallet)
+	addr, err := rt.GenerateBech32(userWallet)
 	if err != nil {
-		t.Fatalf("GenerateBech32m: %v", err)
+		t.Fatalf("GenerateBech32: %v", err)
 	}
-	addr, err := btcutil.DecodeAddress(taprootAddr, &chaincfg.RegressionNetParams)
+	if err := rt.Warp(101, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	script, err := ScriptForAddress(addr)
 	if err != nil {
-		t.Fatalf("DecodeAddress: %v", err)
+		t.Fatalf("ScriptForAddress: %v", err)
+	}
+	hash, err := rt.GetBlockHash(1)
+	if err != nil {
+		t.Fatalf("GetBlockHash: %v", err)
+	}
+	block, err := rt.GetBlock(hash)
+	if err != nil {
+		t.Fatalf("GetBlock: %v", err)
+	}
+	coinbase := block.Transactions[0]
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: coinbase.TxHash(), Index: 0}, nil, nil))
+	tx.AddTxOut(wire.NewTxOut(coinbase.TxOut[0].Value-10_000, script))
+	signed, err := rt.SignRawTransactionWithWallet(tx)
+	if err != nil {
+		t.Fatalf("SignRawTransactionWithWallet: %v", err)
+	}
+
+	decoded, err := rt.DecodeRawTransaction(signed)
+	if err != nil {
+		t.Fatalf("DecodeRawTransaction: %v", err)
+	}
+	if got := Weight(signed); got != int(decoded.Weight) {
+		t.Errorf("Weight = %d, decoderawtransaction weight = %d", got, decoded.Weight)
+	}
+	if got := VirtualSize(signed); got != int(decoded.Vsize) {
+		t.Errorf("VirtualSize = %d, decoderawtransaction vsize = %d", got, decoded.Vsize)
+	}
+}
+
+// TestRPC_DecodeScript_P2TR pins that DecodeScript returns the correct script
+// type and disassembled ASM for a P2TR (Taproot) scriptPubKey. The script is
+// fixed-shape: OP_1 <32-byte x-only pubkey>.
+func TestRPC_DecodeScript_P2TR(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(userWallet)
+
+	taprootAddr, err := rt.GenerateBech32m(userWallet)
+	if err != nil {
+		t.Fatalf("GenerateBech32m: %v", err)
+	}
+	addr, err := btcutil.DecodeAddress(taprootAddr, &chaincfg.RegressionNetParams)
+	if err != nil {
+		t.Fatalf("DecodeAddress: %v", err)
 	}
 	pkScript, err := txscript.PayToAddrScript(addr)
 	if err != nil {
@@ -2153,6 +2625,88 @@ func TestRPC_WarpTime(t *testing.T) {
 	}
 }
 
+// TestRPC_GetMedianTimePast checks GetMedianTimePast tracks WarpTime and
+// that a time-locked tx is rejected as non-final until MTP passes its
+// locktime.
+func TestRPC_GetMedianTimePast(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(minerWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(minerWallet)
+	addr, err := rt.GenerateBech32(minerWallet)
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := rt.Warp(101, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+
+	mtp, err := rt.GetMedianTimePast()
+	if err != nil {
+		t.Fatalf("GetMedianTimePast: %v", err)
+	}
+	info, err := rt.GetBlockChainInfo()
+	if err != nil {
+		t.Fatalf("GetBlockChainInfo: %v", err)
+	}
+	if mtp != info.MedianTime {
+		t.Errorf("GetMedianTimePast = %d, getblockchaininfo.mediantime = %d", mtp, info.MedianTime)
+	}
+
+	script, err := ScriptForAddress(addr)
+	if err != nil {
+		t.Fatalf("ScriptForAddress: %v", err)
+	}
+	hash, err := rt.GetBlockHash(1)
+	if err != nil {
+		t.Fatalf("GetBlockHash: %v", err)
+	}
+	block, err := rt.GetBlock(hash)
+	if err != nil {
+		t.Fatalf("GetBlock: %v", err)
+	}
+	coinbase := block.Transactions[0]
+	locktime := mtp + 3600
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Hash: coinbase.TxHash(), Index: 0},
+		Sequence:         wire.MaxTxInSequenceNum - 1,
+	})
+	tx.AddTxOut(wire.NewTxOut(coinbase.TxOut[0].Value-10_000, script))
+	tx.LockTime = uint32(locktime)
+	signed, err := rt.SignRawTransactionWithWallet(tx)
+	if err != nil {
+		t.Fatalf("SignRawTransactionWithWallet: %v", err)
+	}
+	if err := rt.ExpectReject(signed, "non-final"); err != nil {
+		t.Errorf("before MTP passes locktime: %v", err)
+	}
+
+	// A tx is final when its locktime is strictly below MTP.
+	newMTP, err := rt.WarpTime(2*time.Hour, addr)
+	if err != nil {
+		t.Fatalf("WarpTime: %v", err)
+	}
+	if got, err := rt.GetMedianTimePast(); err != nil || got != newMTP {
+		t.Fatalf("GetMedianTimePast after WarpTime = %d, %v; want %d", got, err, newMTP)
+	}
+	if newMTP <= locktime {
+		t.Fatalf("MTP %d did not pass locktime %d", newMTP, locktime)
+	}
+	if _, err := rt.BroadcastTransaction(signed); err != nil {
+		t.Errorf("after MTP passes locktime: %v", err)
+	}
+}
+
 // TestRPC_WarpTime_Validation pins duration > 0 and miner non-empty.
 func TestRPC_WarpTime_Validation(t *testing.T) {
 	rt, err := New(nil)
@@ -2171,3 +2725,3411 @@ func TestRPC_WarpTime_Validation(t *testing.T) {
 		t.Error("WarpTime(_, \"\") should reject")
 	}
 }
+
+// TestRPC_SweepToScript sweeps a funded wallet to a P2WSH(OP_TRUE) script —
+// a destination the caller holds only as raw bytes — and checks the single
+// output lands in the mempool with the requested script and a positive fee.
+func TestRPC_SweepToScript(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(userWallet)
+
+	miner, err := rt.GenerateBech32(userWallet)
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := rt.Warp(102, miner); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+
+	witnessScript := []byte{txscript.OP_TRUE}
+	scriptHash := sha256.Sum256(witnessScript)
+	script, err := txscript.NewScriptBuilder().AddOp(txscript.OP_0).AddData(scriptHash[:]).Script()
+	if err != nil {
+		t.Fatalf("build script: %v", err)
+	}
+
+	txid, err := rt.SweepToScript(script, 2)
+	if err != nil {
+		t.Fatalf("SweepToScript: %v", err)
+	}
+
+	out, err := rt.GetTxOut(txid, 0, true)
+	if err != nil {
+		t.Fatalf("GetTxOut: %v", err)
+	}
+	if out == nil {
+		t.Fatal("sweep output not found in mempool")
+	}
+	if out.ScriptPubKey.Hex != hex.EncodeToString(script) {
+		t.Errorf("output script = %s, want %x", out.ScriptPubKey.Hex, script)
+	}
+	// Two mature coinbases at 50 BTC each; the sweep must pay some fee.
+	if out.Value <= 0 || out.Value >= 100 {
+		t.Errorf("output value = %v BTC, want in (0, 100)", out.Value)
+	}
+
+	if _, err := rt.SweepToScript(script, 2); err == nil {
+		t.Error("second sweep of an empty wallet should fail")
+	}
+}
+
+// TestRPC_SweepToScript_Validation pins the empty-script and fee-rate checks.
+func TestRPC_SweepToScript_Validation(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Cleanup() })
+
+	if _, err := rt.SweepToScript(nil, 1); err == nil {
+		t.Error("SweepToScript(nil, ...) should reject")
+	}
+	for _, rate := range []float64{0, math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if _, err := rt.SweepToScript([]byte{txscript.OP_TRUE}, rate); err == nil {
+			t.Errorf("SweepToScript(_, %v) should reject", rate)
+		}
+	}
+}
+
+// TestRPC_GetRawMempoolVerbose sends a parent and a child spending its change
+// and checks both appear with fees and the expected ancestor linkage.
+func TestRPC_GetRawMempool(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(userWallet)
+
+	addr, err := rt.GenerateBech32(userWallet)
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := rt.Warp(101, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+
+	txid, err := rt.SendToAddress(addr, 10_000_000)
+	if err != nil {
+		t.Fatalf("SendToAddress: %v", err)
+	}
+	txids, err := rt.GetRawMempool()
+	if err != nil {
+		t.Fatalf("GetRawMempool: %v", err)
+	}
+	if len(txids) != 1 || !txids[0].IsEqual(txid) {
+		t.Fatalf("GetRawMempool = %v, want [%s]", txids, txid)
+	}
+	info, err := rt.GetMempoolInfo()
+	if err != nil {
+		t.Fatalf("GetMempoolInfo: %v", err)
+	}
+	if info.Size != 1 || info.Bytes <= 0 {
+		t.Errorf("GetMempoolInfo = %+v, want size 1 and non-zero bytes", info)
+	}
+
+	if err := rt.Warp(1, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	if txids, err := rt.GetRawMempool(); err != nil || len(txids) != 0 {
+		t.Errorf("GetRawMempool after Warp = %v, %v; want empty", txids, err)
+	}
+	if info, err := rt.GetMempoolInfo(); err != nil || info.Size != 0 {
+		t.Errorf("GetMempoolInfo after Warp = %+v, %v; want size 0", info, err)
+	}
+}
+
+func TestRPC_GetRawMempoolVerbose(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(userWallet)
+
+	addr, err := rt.GenerateBech32(userWallet)
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := rt.Warp(101, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+
+	empty, err := rt.GetRawMempoolVerbose()
+	if err != nil {
+		t.Fatalf("GetRawMempoolVerbose (empty): %v", err)
+	}
+	if len(empty) != 0 {
+		t.Fatalf("expected empty mempool, got %d entries", len(empty))
+	}
+
+	// Only one mature coinbase exists, so the second send must spend the
+	// first send's change and become its child.
+	parent, err := rt.SendToAddress(addr, 10_000_000)
+	if err != nil {
+		t.Fatalf("SendToAddress parent: %v", err)
+	}
+	child, err := rt.SendToAddress(addr, 1_000_000)
+	if err != nil {
+		t.Fatalf("SendToAddress child: %v", err)
+	}
+
+	pool, err := rt.GetRawMempoolVerbose()
+	if err != nil {
+		t.Fatalf("GetRawMempoolVerbose: %v", err)
+	}
+	p, ok := pool[parent.String()]
+	if !ok {
+		t.Fatalf("parent %s missing from mempool", parent)
+	}
+	c, ok := pool[child.String()]
+	if !ok {
+		t.Fatalf("child %s missing from mempool", child)
+	}
+	if p.Fee <= 0 || p.VSize <= 0 {
+		t.Errorf("parent fee/vsize = %v/%d, want > 0", p.Fee, p.VSize)
+	}
+	if p.DescendantCount != 2 {
+		t.Errorf("parent DescendantCount = %d, want 2", p.DescendantCount)
+	}
+	if c.AncestorCount != 2 {
+		t.Errorf("child AncestorCount = %d, want 2", c.AncestorCount)
+	}
+	if len(c.Depends) != 1 || c.Depends[0] != parent.String() {
+		t.Errorf("child Depends = %v, want [%s]", c.Depends, parent)
+	}
+	if c.AncestorFee != p.ModifiedFee+c.ModifiedFee {
+		t.Errorf("child AncestorFee = %v, want %v", c.AncestorFee, p.ModifiedFee+c.ModifiedFee)
+	}
+}
+
+// TestRPC_MempoolFeeHistogram checks the histogram accounts for every
+// mempool transaction exactly once: counts, vsizes and fees summed across
+// buckets match GetRawMempoolVerbose, and each tx's fee rate lies inside a
+// returned bucket.
+func TestRPC_MempoolFeeHistogram(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(userWallet)
+
+	addr, err := rt.GenerateBech32(userWallet)
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := rt.Warp(101, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+
+	empty, err := rt.MempoolFeeHistogram()
+	if err != nil {
+		t.Fatalf("MempoolFeeHistogram (empty): %v", err)
+	}
+	if len(empty) != 0 {
+		t.Fatalf("expected no buckets for an empty mempool, got %+v", empty)
+	}
+
+	for _, sats := range []int64{10_000_000, 1_000_000, 500_000} {
+		if _, err := rt.SendToAddress(addr, sats); err != nil {
+			t.Fatalf("SendToAddress: %v", err)
+		}
+	}
+	pool, err := rt.GetRawMempoolVerbose()
+	if err != nil {
+		t.Fatalf("GetRawMempoolVerbose: %v", err)
+	}
+	hist, err := rt.MempoolFeeHistogram()
+	if err != nil {
+		t.Fatalf("MempoolFeeHistogram: %v", err)
+	}
+
+	var count int
+	var vsize int64
+	var fee btcutil.Amount
+	for i, b := range hist {
+		if i > 0 && b.MinFeeRate < hist[i-1].MaxFeeRate {
+			t.Errorf("bucket %d (%g) overlaps bucket %d (%g)", i, b.MinFeeRate, i-1, hist[i-1].MaxFeeRate)
+		}
+		count += b.Count
+		vsize += b.VSize
+		fee += b.Fee
+	}
+	var wantVSize int64
+	var wantFee btcutil.Amount
+	for txid, e := range pool {
+		wantVSize += e.VSize
+		wantFee += e.Fee
+		rate := float64(e.Fee) / float64(e.VSize)
+		found := false
+		for _, b := range hist {
+			if rate >= b.MinFeeRate && rate < b.MaxFeeRate {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("tx %s at %.2f sat/vB is in no bucket", txid, rate)
+		}
+	}
+	if count != len(pool) || vsize != wantVSize || fee != wantFee {
+		t.Errorf("histogram totals = %d txs/%d vB/%v, want %d/%d/%v",
+			count, vsize, fee, len(pool), wantVSize, wantFee)
+	}
+}
+
+// TestRPC_MempoolTxFeeRate builds a parent/child chain and checks the
+// per-tx and ancestor-package rates against the raw mempool entries.
+func TestRPC_MempoolTxFeeRate(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(userWallet)
+
+	addr, err := rt.GenerateBech32(userWallet)
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := rt.Warp(101, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	// One mature coinbase: the second send spends the first's change.
+	parent, err := rt.SendToAddress(addr, 10_000_000)
+	if err != nil {
+		t.Fatalf("SendToAddress parent: %v", err)
+	}
+	child, err := rt.SendToAddress(addr, 1_000_000)
+	if err != nil {
+		t.Fatalf("SendToAddress child: %v", err)
+	}
+	pool, err := rt.GetRawMempoolVerbose()
+	if err != nil {
+		t.Fatalf("GetRawMempoolVerbose: %v", err)
+	}
+	p, c := pool[parent.String()], pool[child.String()]
+
+	const eps = 1e-9
+	for _, tc := range []struct {
+		name string
+		get  func(*chainhash.Hash) (float64, error)
+		txid *chainhash.Hash
+		want float64
+	}{
+		{"parent", rt.MempoolTxFeeRate, parent, float64(p.ModifiedFee) / float64(p.VSize)},
+		{"child", rt.MempoolTxFeeRate, child, float64(c.ModifiedFee) / float64(c.VSize)},
+		{"parent ancestor", rt.MempoolTxAncestorFeeRate, parent, float64(p.ModifiedFee) / float64(p.VSize)},
+		{"child ancestor", rt.MempoolTxAncestorFeeRate, child,
+			float64(p.ModifiedFee+c.ModifiedFee) / float64(p.VSize+c.VSize)},
+	} {
+		got, err := tc.get(tc.txid)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if got <= 0 || math.Abs(got-tc.want) > eps {
+			t.Errorf("%s rate = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+
+	if _, err := rt.MempoolTxFeeRate(&chainhash.Hash{}); err == nil {
+		t.Error("unknown txid should error")
+	}
+	if _, err := rt.MempoolTxAncestorFeeRate(nil); err == nil {
+		t.Error("nil txid should error")
+	}
+}
+
+// TestRPC_PrioritiseTransaction pushes a tx's modified fee below the
+// template's minimum with a negative delta, checks it drops out of
+// getblocktemplate, then bumps it back and checks Warp mines it.
+func TestRPC_PrioritiseTransaction(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(minerWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(minerWallet)
+	addr, err := rt.GenerateBech32(minerWallet)
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := rt.Warp(101, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	txid, err := rt.SendToAddress(addr, 100_000)
+	if err != nil {
+		t.Fatalf("SendToAddress: %v", err)
+	}
+
+	inTemplate := func() bool {
+		t.Helper()
+		tmpl, err := rt.GetBlockTemplate(&btcjson.TemplateRequest{Mode: "template", Rules: []string{"segwit"}})
+		if err != nil {
+			t.Fatalf("GetBlockTemplate: %v", err)
+		}
+		for _, tx := range tmpl.Transactions {
+			if tx.TxID == txid.String() {
+				return true
+			}
+		}
+		return false
+	}
+
+	const delta = 10_000_000
+	ok, err := rt.PrioritiseTransaction(txid, -delta)
+	if err != nil || !ok {
+		t.Fatalf("PrioritiseTransaction(-%d) = %v, %v", delta, ok, err)
+	}
+	if inTemplate() {
+		t.Error("deprioritised tx should be left out of the block template")
+	}
+
+	deltas, err := rt.GetPrioritisedTransactions()
+	switch {
+	case errors.Is(err, ErrUnsupportedVersion):
+		t.Log(err)
+	case err != nil:
+		t.Fatalf("GetPrioritisedTransactions: %v", err)
+	default:
+		got, ok := deltas[txid.String()]
+		if !ok || got.FeeDelta != -delta || !got.InMempool {
+			t.Errorf("deltas[%s] = %+v (present %v), want FeeDelta -%d in mempool", txid, got, ok, delta)
+		}
+	}
+
+	if _, err := rt.PrioritiseTransaction(txid, 2*delta); err != nil {
+		t.Fatalf("PrioritiseTransaction(+%d): %v", 2*delta, err)
+	}
+	if !inTemplate() {
+		t.Error("bumped tx should be in the block template")
+	}
+	if err := rt.Warp(1, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	out, err := rt.GetTxOut(txid, 0, false)
+	if err != nil {
+		t.Fatalf("GetTxOut: %v", err)
+	}
+	if out == nil || out.Confirmations != 1 {
+		t.Errorf("bumped tx not mined in the next block: %+v", out)
+	}
+
+	if _, err := rt.PrioritiseTransaction(txid, 0); err == nil {
+		t.Error("zero delta should error")
+	}
+}
+
+// TestRPC_WaitForTxConfirmedOrReplaced covers both the plain path (the tx
+// itself confirms) and the replacement path (bumpfee replaces the tx, and
+// the wait follows it to the replacement's txid).
+func TestRPC_WaitForTxConfirmedOrReplaced(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(userWallet)
+
+	addr, err := rt.GenerateBech32(userWallet)
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := rt.Warp(101, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	plain, err := rt.SendToAddress(addr, 1_000_000)
+	if err != nil {
+		t.Fatalf("SendToAddress: %v", err)
+	}
+	got, err := rt.WaitForTxConfirmedOrReplaced(ctx, plain, 3, addr)
+	if err != nil {
+		t.Fatalf("WaitForTxConfirmedOrReplaced (plain): %v", err)
+	}
+	if !got.IsEqual(plain) {
+		t.Errorf("plain: confirmed %s, want %s", got, plain)
+	}
+
+	orig, err := rt.SendToAddress(addr, 2_000_000)
+	if err != nil {
+		t.Fatalf("SendToAddress: %v", err)
+	}
+	resp, err := rt.rawRPC(ctx, "bumpfee", orig.String())
+	if err != nil {
+		t.Fatalf("bumpfee: %v", err)
+	}
+	var bumped struct {
+		TxID string `json:"txid"`
+	}
+	if err := json.Unmarshal(resp, &bumped); err != nil {
+		t.Fatalf("unmarshal bumpfee: %v", err)
+	}
+
+	got, err = rt.WaitForTxConfirmedOrReplaced(ctx, orig, 1, addr)
+	if err != nil {
+		t.Fatalf("WaitForTxConfirmedOrReplaced (replaced): %v", err)
+	}
+	if got.String() != bumped.TxID {
+		t.Errorf("replaced: confirmed %s, want replacement %s", got, bumped.TxID)
+	}
+}
+
+// TestRPC_WaitForTxConfirmedOrReplaced_Validation pins the argument checks.
+func TestRPC_WaitForTxConfirmedOrReplaced_Validation(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Cleanup() })
+
+	ctx := context.Background()
+	txid := &chainhash.Hash{}
+	if _, err := rt.WaitForTxConfirmedOrReplaced(ctx, nil, 1, "addr"); err == nil {
+		t.Error("nil txid should reject")
+	}
+	if _, err := rt.WaitForTxConfirmedOrReplaced(ctx, txid, 0, "addr"); err == nil {
+		t.Error("minConf 0 should reject")
+	}
+	if _, err := rt.WaitForTxConfirmedOrReplaced(ctx, txid, 1, ""); err == nil {
+		t.Error("empty miner should reject")
+	}
+}
+
+// TestRPC_ConflictedTransactions replaces a wallet tx with bumpfee, checks
+// the original is listed as conflicted while its replacement is not, and
+// that PurgeConflicted abandons it so the listing comes back empty.
+func TestRPC_ConflictedTransactions(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(userWallet)
+
+	addr, err := rt.GenerateBech32(userWallet)
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := rt.Warp(101, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+
+	if got, err := rt.ListConflictedTransactions(); err != nil {
+		t.Fatalf("ListConflictedTransactions: %v", err)
+	} else if len(got) != 0 {
+		t.Fatalf("expected no conflicted entries on a fresh wallet, got %d", len(got))
+	}
+
+	orig, err := rt.SendToAddress(addr, 1_000_000)
+	if err != nil {
+		t.Fatalf("SendToAddress: %v", err)
+	}
+	resp, err := rt.rawRPC(context.Background(), "bumpfee", orig.String())
+	if err != nil {
+		t.Fatalf("bumpfee: %v", err)
+	}
+	var bumped struct {
+		TxID string `json:"txid"`
+	}
+	if err := json.Unmarshal(resp, &bumped); err != nil {
+		t.Fatalf("unmarshal bumpfee: %v", err)
+	}
+
+	conflicted, err := rt.ListConflictedTransactions()
+	if err != nil {
+		t.Fatalf("ListConflictedTransactions: %v", err)
+	}
+	if len(conflicted) == 0 {
+		t.Fatal("expected the replaced tx to be listed as conflicted")
+	}
+	for _, e := range conflicted {
+		if e.TxID != orig.String() {
+			t.Errorf("unexpected conflicted txid %s (replacement is %s)", e.TxID, bumped.TxID)
+		}
+	}
+
+	if err := rt.PurgeConflicted(); err != nil {
+		t.Fatalf("PurgeConflicted: %v", err)
+	}
+	if got, err := rt.ListConflictedTransactions(); err != nil {
+		t.Fatalf("ListConflictedTransactions after purge: %v", err)
+	} else if len(got) != 0 {
+		t.Errorf("expected no conflicted entries after purge, got %d", len(got))
+	}
+}
+
+// TestRPC_ResyncWallet strands a confirmed wallet tx by invalidating the
+// block that matured its coinbase input, and checks ResyncWallet abandons it
+// and mines the settling block.
+func TestRPC_ResyncWallet(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(userWallet)
+
+	addr, err := rt.GenerateBech32(userWallet)
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := rt.Warp(101, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	// Only block 1's coinbase is mature, so the send spends it.
+	txid, err := rt.SendToAddress(addr, 1_000_000)
+	if err != nil {
+		t.Fatalf("SendToAddress: %v", err)
+	}
+	if err := rt.Warp(1, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	// Back at height 1 the coinbase is immature again, so the tx can't
+	// return to the mempool.
+	block2, err := rt.GetBlockHash(2)
+	if err != nil {
+		t.Fatalf("GetBlockHash: %v", err)
+	}
+	if err := rt.InvalidateBlock(block2); err != nil {
+		t.Fatalf("InvalidateBlock: %v", err)
+	}
+
+	if err := rt.ResyncWallet(addr); err != nil {
+		t.Fatalf("ResyncWallet: %v", err)
+	}
+	if height, err := rt.GetBlockCount(); err != nil || height != 2 {
+		t.Errorf("GetBlockCount = %d, %v; want 2 after the settling block", height, err)
+	}
+	raw, err := rt.rawRPC(context.Background(), "gettransaction", txid.String())
+	if err != nil {
+		t.Fatalf("gettransaction: %v", err)
+	}
+	var wtx struct {
+		Confirmations int64 `json:"confirmations"`
+		Abandoned     bool  `json:"abandoned"`
+	}
+	if err := json.Unmarshal(raw, &wtx); err != nil {
+		t.Fatalf("unmarshal gettransaction: %v", err)
+	}
+	if wtx.Confirmations != 0 || !wtx.Abandoned {
+		t.Errorf("stranded tx: confirmations %d, abandoned %v; want 0, true", wtx.Confirmations, wtx.Abandoned)
+	}
+
+	// Nothing left to abandon: a second pass only rescans.
+	if err := rt.ResyncWallet(""); err != nil {
+		t.Errorf("second ResyncWallet: %v", err)
+	}
+}
+
+// TestRPC_GetNodeAddresses seeds addrman through addpeeraddress and checks
+// GetNodeAddresses reports the entry; a fresh node starts empty.
+func TestRPC_GetNodeAddresses(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	addrs, err := rt.GetNodeAddresses(0)
+	if err != nil {
+		t.Fatalf("GetNodeAddresses: %v", err)
+	}
+	if len(addrs) != 0 {
+		t.Fatalf("expected empty addrman on a fresh node, got %+v", addrs)
+	}
+
+	if _, err := rt.rawRPC(context.Background(), "addpeeraddress", "1.2.3.4", 18444); err != nil {
+		t.Fatalf("addpeeraddress: %v", err)
+	}
+	addrs, err = rt.GetNodeAddresses(0)
+	if err != nil {
+		t.Fatalf("GetNodeAddresses after addpeeraddress: %v", err)
+	}
+	if len(addrs) != 1 || addrs[0].Address != "1.2.3.4" || addrs[0].Port != 18444 {
+		t.Errorf("GetNodeAddresses = %+v, want single 1.2.3.4:18444", addrs)
+	}
+
+	if _, err := rt.GetNodeAddresses(-1); err == nil {
+		t.Error("GetNodeAddresses(-1) should reject")
+	}
+}
+
+// TestRPC_WarpWithCoinbaseData mines a block carrying a pool tag and a
+// pending mempool tx, then reads it back to check the tag sits after the
+// BIP34 height push and the tx was confirmed.
+func TestRPC_WarpWithCoinbaseData(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(minerWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(minerWallet)
+
+	miner, err := rt.GenerateBech32(minerWallet)
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := rt.Warp(101, miner); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	txid, err := rt.SendToAddress(miner, 1_000_000)
+	if err != nil {
+		t.Fatalf("SendToAddress: %v", err)
+	}
+
+	tag := []byte("/go-regtest/")
+	hash, err := rt.WarpWithCoinbaseData(miner, tag)
+	if err != nil {
+		t.Fatalf("WarpWithCoinbaseData: %v", err)
+	}
+
+	height, err := rt.GetBlockCount()
+	if err != nil {
+		t.Fatalf("GetBlockCount: %v", err)
+	}
+	if height != 102 {
+		t.Errorf("height = %d, want 102", height)
+	}
+
+	block, err := rt.GetBlock(hash)
+	if err != nil {
+		t.Fatalf("GetBlock: %v", err)
+	}
+	want, err := txscript.NewScriptBuilder().AddInt64(102).AddData(tag).Script()
+	if err != nil {
+		t.Fatalf("build expected script: %v", err)
+	}
+	if got := block.Transactions[0].TxIn[0].SignatureScript; !bytes.Equal(got, want) {
+		t.Errorf("coinbase scriptSig = %x, want %x", got, want)
+	}
+	found := false
+	for _, tx := range block.Transactions[1:] {
+		if tx.TxHash() == *txid {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("mempool tx %s not included in block", txid)
+	}
+
+	if _, err := rt.WarpWithCoinbaseData(miner, make([]byte, 100)); err == nil {
+		t.Error("oversized coinbase data should reject")
+	}
+}
+
+// TestRPC_WalletFeeSettings exercises settxfee and setwalletflag round-trips
+// on a loaded wallet, plus their argument validation.
+func TestRPC_WalletFeeSettings(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(userWallet)
+
+	ok, err := rt.SetTxFee(0.0001)
+	if err != nil {
+		t.Fatalf("SetTxFee: %v", err)
+	}
+	if !ok {
+		t.Error("SetTxFee returned false")
+	}
+	info, err := rt.GetWalletInformation()
+	if err != nil {
+		t.Fatalf("GetWalletInformation: %v", err)
+	}
+	if info.PayTransactionFee != 0.0001 {
+		t.Errorf("paytxfee = %v, want 0.0001", info.PayTransactionFee)
+	}
+	if _, err := rt.SetTxFee(-1); err == nil {
+		t.Error("SetTxFee(-1) should reject")
+	}
+
+	res, err := rt.SetWalletFlag("avoid_reuse", true)
+	if err != nil {
+		t.Fatalf("SetWalletFlag: %v", err)
+	}
+	if res.FlagName != "avoid_reuse" || !res.FlagState {
+		t.Errorf("SetWalletFlag = %+v, want avoid_reuse=true", res)
+	}
+	if _, err := rt.SetWalletFlag("", true); err == nil {
+		t.Error("SetWalletFlag(\"\") should reject")
+	}
+}
+
+// TestRPC_String checks the connected summary reports height, wallets and
+// mempool size.
+func TestRPC_String(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(userWallet)
+
+	got := rt.String()
+	for _, want := range []string{"state=running", "height=0", "wallets=[" + userWallet + "]", "mempool=0"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("String() = %q, missing %q", got, want)
+		}
+	}
+	t.Log(rt)
+}
+
+// TestRPC_KeyPoolSize_IsWalletLocked checks both accessors on a fresh
+// unencrypted wallet, then encrypts it and checks it reads as locked.
+func TestRPC_GetReceivedByLabel(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(minerWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(minerWallet)
+	miner, err := rt.GenerateBech32(minerWallet)
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := rt.Warp(101, miner); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+
+	deposits := map[string][]int64{
+		"customer-a": {1_000_000, 2_000_000},
+		"customer-b": {500_000},
+	}
+	for label, amounts := range deposits {
+		for _, sats := range amounts {
+			addr, err := rt.GenerateBech32(label)
+			if err != nil {
+				t.Fatalf("GenerateBech32(%s): %v", label, err)
+			}
+			if _, err := rt.SendToAddress(addr, sats); err != nil {
+				t.Fatalf("SendToAddress: %v", err)
+			}
+		}
+	}
+
+	check := func(label string, minConf int, want int64) {
+		t.Helper()
+		got, err := rt.GetReceivedByLabel(label, minConf)
+		if err != nil {
+			t.Fatalf("GetReceivedByLabel(%s, %d): %v", label, minConf, err)
+		}
+		if got != want {
+			t.Errorf("GetReceivedByLabel(%s, %d) = %d, want %d", label, minConf, got, want)
+		}
+	}
+	check("customer-a", 0, 3_000_000)
+	check("customer-b", 0, 500_000)
+	check("customer-a", 1, 0)
+
+	if err := rt.Warp(1, miner); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	check("customer-a", 1, 3_000_000)
+	check("customer-b", 1, 500_000)
+
+	if _, err := rt.GetReceivedByLabel("nobody", 0); err == nil {
+		t.Error("GetReceivedByLabel of an unknown label should fail")
+	}
+	if _, err := rt.GetReceivedByLabel("customer-a", -1); err == nil {
+		t.Error("GetReceivedByLabel with negative minConf should fail")
+	}
+}
+
+// TestRPC_GetBalances mines one coinbase into a fresh wallet and follows it
+// from immature to trusted across the maturity boundary.
+func TestRPC_GetBalances(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	const wallet = "getbalances"
+	const other = "bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl"
+	if err := rt.EnsureWallet(wallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(wallet)
+
+	before, err := rt.GetBalances()
+	if err != nil {
+		t.Fatalf("GetBalances: %v", err)
+	}
+	if _, err := rt.WarpToWallet(1, wallet); err != nil {
+		t.Fatalf("WarpToWallet: %v", err)
+	}
+	immature, err := rt.GetBalances()
+	if err != nil {
+		t.Fatalf("GetBalances: %v", err)
+	}
+	reward := immature.Mine.Immature - before.Mine.Immature
+	if reward <= 0 {
+		t.Fatalf("immature balance did not grow: %v -> %v", before.Mine.Immature, immature.Mine.Immature)
+	}
+	if immature.Mine.Trusted != before.Mine.Trusted {
+		t.Errorf("trusted balance changed on a fresh coinbase: %v -> %v", before.Mine.Trusted, immature.Mine.Trusted)
+	}
+
+	// At 100 confirmations the wallet still counts the coinbase immature.
+	if err := rt.Warp(coinbaseMaturity-1, other); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	atBoundary, err := rt.GetBalances()
+	if err != nil {
+		t.Fatalf("GetBalances: %v", err)
+	}
+	if atBoundary.Mine.Immature != immature.Mine.Immature {
+		t.Errorf("immature balance at 100 confirmations = %v, want %v", atBoundary.Mine.Immature, immature.Mine.Immature)
+	}
+
+	if err := rt.Warp(1, other); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	mature, err := rt.GetBalances()
+	if err != nil {
+		t.Fatalf("GetBalances: %v", err)
+	}
+	if mature.Mine.Immature != before.Mine.Immature {
+		t.Errorf("immature balance after maturity = %v, want %v", mature.Mine.Immature, before.Mine.Immature)
+	}
+	grew, _ := btcutil.NewAmount(mature.Mine.Trusted - before.Mine.Trusted)
+	if want, _ := btcutil.NewAmount(reward); grew != want {
+		t.Errorf("trusted balance grew by %v, want %v", grew, want)
+	}
+
+	sats, err := rt.GetBalance()
+	if err != nil {
+		t.Fatalf("GetBalance: %v", err)
+	}
+	want, err := btcutil.NewAmount(mature.Mine.Trusted)
+	if err != nil {
+		t.Fatalf("NewAmount: %v", err)
+	}
+	if sats != want {
+		t.Errorf("GetBalance = %v, want %v", sats, want)
+	}
+}
+
+func TestRPC_KeyPoolSize_IsWalletLocked(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(userWallet)
+
+	n, err := rt.KeyPoolSize()
+	if err != nil {
+		t.Fatalf("KeyPoolSize: %v", err)
+	}
+	if n <= 0 {
+		t.Errorf("KeyPoolSize = %d, want > 0 on a fresh wallet", n)
+	}
+
+	locked, err := rt.IsWalletLocked()
+	if err != nil {
+		t.Fatalf("IsWalletLocked: %v", err)
+	}
+	if locked {
+		t.Error("unencrypted wallet reported as locked")
+	}
+
+	if _, err := rt.rawRPC(context.Background(), "encryptwallet", "passphrase"); err != nil {
+		t.Fatalf("encryptwallet: %v", err)
+	}
+	locked, err = rt.IsWalletLocked()
+	if err != nil {
+		t.Fatalf("IsWalletLocked after encrypt: %v", err)
+	}
+	if !locked {
+		t.Error("encrypted wallet reported as unlocked")
+	}
+}
+
+// TestRPC_GenerateAddresses batches 50 getnewaddress calls and checks the
+// results are distinct addresses of the requested type.
+func TestRPC_GenerateAddresses(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(userWallet)
+
+	addrs, err := rt.GenerateAddresses("fanout", "bech32m", 50)
+	if err != nil {
+		t.Fatalf("GenerateAddresses: %v", err)
+	}
+	if len(addrs) != 50 {
+		t.Fatalf("got %d addresses, want 50", len(addrs))
+	}
+	seen := make(map[string]bool, len(addrs))
+	for _, a := range addrs {
+		if !strings.HasPrefix(a, "bcrt1p") {
+			t.Errorf("address %q is not bech32m", a)
+		}
+		if seen[a] {
+			t.Errorf("duplicate address %q", a)
+		}
+		seen[a] = true
+	}
+
+	if _, err := rt.GenerateAddresses("fanout", "not-a-type", 2); err == nil {
+		t.Error("unknown address type should fail")
+	}
+	if _, err := rt.GenerateAddresses("fanout", "", 0); err == nil {
+		t.Error("count 0 should reject")
+	}
+	if _, err := rt.GenerateAddresses("fanout", "", maxGenerateAddresses+1); err == nil {
+		t.Error("count above the cap should reject")
+	}
+}
+
+// TestRPC_IsReplaceableInMempool checks the node's bip125-replaceable view
+// of a wallet tx agrees with the locally computed IsReplaceable.
+func TestRPC_IsReplaceableInMempool(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(userWallet)
+
+	addr, err := rt.GenerateBech32(userWallet)
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := rt.Warp(101, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	txid, err := rt.SendToAddress(addr, 1_000_000)
+	if err != nil {
+		t.Fatalf("SendToAddress: %v", err)
+	}
+	raw, err := rt.Client().GetRawTransaction(txid)
+	if err != nil {
+		t.Fatalf("GetRawTransaction: %v", err)
+	}
+
+	inMempool, err := rt.IsReplaceableInMempool(txid)
+	if err != nil {
+		t.Fatalf("IsReplaceableInMempool: %v", err)
+	}
+	if local := IsReplaceable(raw.MsgTx()); local != inMempool {
+		t.Errorf("IsReplaceable = %v, node says %v", local, inMempool)
+	}
+
+	if _, err := rt.IsReplaceableInMempool(nil); err == nil {
+		t.Error("nil txid should reject")
+	}
+	if _, err := rt.IsReplaceableInMempool(&chainhash.Hash{}); err == nil {
+		t.Error("unknown txid should fail")
+	}
+}
+
+// TestRPC_CreateFundedPSBT funds a two-output PSBT from the wallet and checks
+// it parses, carries both outputs plus change, and reports a positive fee.
+func TestRPC_CreateFundedPSBT(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(userWallet)
+
+	addrs, err := rt.GenerateAddresses("psbt", "bech32", 3)
+	if err != nil {
+		t.Fatalf("GenerateAddresses: %v", err)
+	}
+	if err := rt.Warp(101, addrs[0]); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+
+	encoded, fee, changePos, err := rt.CreateFundedPSBT(map[string]int64{
+		addrs[1]: 50_000,
+		addrs[2]: 70_000,
+	}, &FundOptions{FeeRate: 5, LockUnspents: true})
+	if err != nil {
+		t.Fatalf("CreateFundedPSBT: %v", err)
+	}
+	if fee <= 0 {
+		t.Errorf("fee = %d, want > 0", fee)
+	}
+
+	resp, err := rt.rawRPC(context.Background(), "decodepsbt", encoded)
+	if err != nil {
+		t.Fatalf("decodepsbt: %v", err)
+	}
+	var decoded struct {
+		Tx struct {
+			Vout []json.RawMessage `json:"vout"`
+		} `json:"tx"`
+		Inputs []struct {
+			WitnessUtxo json.RawMessage `json:"witness_utxo"`
+		} `json:"inputs"`
+	}
+	if err := json.Unmarshal(resp, &decoded); err != nil {
+		t.Fatalf("unmarshal decodepsbt: %v", err)
+	}
+	if n := len(decoded.Tx.Vout); n != 3 {
+		t.Fatalf("got %d outputs, want 2 payments + change", n)
+	}
+	if changePos < 0 || changePos >= 3 {
+		t.Errorf("changePos = %d, want in [0, 3)", changePos)
+	}
+	if len(decoded.Inputs) == 0 || decoded.Inputs[0].WitnessUtxo == nil {
+		t.Error("expected funded inputs with witness UTXO data for the signer")
+	}
+
+	if _, _, _, err := rt.CreateFundedPSBT(nil, nil); err == nil {
+		t.Error("empty outputs should reject")
+	}
+	if _, _, _, err := rt.CreateFundedPSBT(map[string]int64{addrs[1]: 0}, nil); err == nil {
+		t.Error("zero amount should reject")
+	}
+}
+
+func TestRPC_CombineAndJoinPSBTs(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(userWallet)
+
+	addrs, err := rt.GenerateAddresses("psbt", "bech32", 3)
+	if err != nil {
+		t.Fatalf("GenerateAddresses: %v", err)
+	}
+	// Two mature coinbases so the two PSBTs below can lock distinct inputs.
+	if err := rt.Warp(102, addrs[0]); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+
+	opts := &FundOptions{FeeRate: 5, LockUnspents: true}
+	first, _, _, err := rt.CreateFundedPSBT(map[string]int64{addrs[1]: 50_000}, opts)
+	if err != nil {
+		t.Fatalf("CreateFundedPSBT first: %v", err)
+	}
+	second, _, _, err := rt.CreateFundedPSBT(map[string]int64{addrs[2]: 60_000}, opts)
+	if err != nil {
+		t.Fatalf("CreateFundedPSBT second: %v", err)
+	}
+
+	ctx := context.Background()
+	decodeCounts := func(encoded string) (inputs, outputs int) {
+		t.Helper()
+		resp, err := rt.rawRPC(ctx, "decodepsbt", encoded)
+		if err != nil {
+			t.Fatalf("decodepsbt: %v", err)
+		}
+		var decoded struct {
+			Tx struct {
+				Vin  []json.RawMessage `json:"vin"`
+				Vout []json.RawMessage `json:"vout"`
+			} `json:"tx"`
+		}
+		if err := json.Unmarshal(resp, &decoded); err != nil {
+			t.Fatalf("unmarshal decodepsbt: %v", err)
+		}
+		return len(decoded.Tx.Vin), len(decoded.Tx.Vout)
+	}
+
+	// One "signer" processes its copy; combining with the untouched copy
+	// must carry the signatures through to a complete finalize.
+	resp, err := rt.rawRPC(ctx, "walletprocesspsbt", first)
+	if err != nil {
+		t.Fatalf("walletprocesspsbt: %v", err)
+	}
+	var processed struct {
+		PSBT string `json:"psbt"`
+	}
+	if err := json.Unmarshal(resp, &processed); err != nil {
+		t.Fatalf("unmarshal walletprocesspsbt: %v", err)
+	}
+	combined, err := rt.CombinePSBT([]string{first, processed.PSBT})
+	if err != nil {
+		t.Fatalf("CombinePSBT: %v", err)
+	}
+	resp, err = rt.rawRPC(ctx, "finalizepsbt", combined)
+	if err != nil {
+		t.Fatalf("finalizepsbt: %v", err)
+	}
+	var finalized struct {
+		Complete bool `json:"complete"`
+	}
+	if err := json.Unmarshal(resp, &finalized); err != nil {
+		t.Fatalf("unmarshal finalizepsbt: %v", err)
+	}
+	if !finalized.Complete {
+		t.Error("combined PSBT should finalize complete")
+	}
+
+	if _, err := rt.CombinePSBT([]string{first, second}); err == nil {
+		t.Error("combining PSBTs for different transactions should fail")
+	}
+
+	joined, err := rt.JoinPSBTs([]string{first, second})
+	if err != nil {
+		t.Fatalf("JoinPSBTs: %v", err)
+	}
+	in1, out1 := decodeCounts(first)
+	in2, out2 := decodeCounts(second)
+	in, out := decodeCounts(joined)
+	if in != in1+in2 || out != out1+out2 {
+		t.Errorf("joined PSBT has %d inputs/%d outputs, want %d/%d", in, out, in1+in2, out1+out2)
+	}
+
+	if _, err := rt.JoinPSBTs([]string{first}); err == nil {
+		t.Error("joining a single PSBT should reject")
+	}
+	if _, err := rt.CombinePSBT(nil); err == nil {
+		t.Error("combining no PSBTs should reject")
+	}
+}
+
+func TestRPC_AnalyzePSBT(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(userWallet)
+
+	addrs, err := rt.GenerateAddresses("psbt", "bech32", 2)
+	if err != nil {
+		t.Fatalf("GenerateAddresses: %v", err)
+	}
+	if err := rt.Warp(101, addrs[0]); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+
+	encoded, fee, _, err := rt.CreateFundedPSBT(map[string]int64{addrs[1]: 50_000}, &FundOptions{FeeRate: 5})
+	if err != nil {
+		t.Fatalf("CreateFundedPSBT: %v", err)
+	}
+
+	a, err := rt.AnalyzePSBT(encoded)
+	if err != nil {
+		t.Fatalf("AnalyzePSBT: %v", err)
+	}
+	if a.Next != "signer" {
+		t.Errorf("Next = %q, want signer", a.Next)
+	}
+	if a.Fee != btcutil.Amount(fee) {
+		t.Errorf("Fee = %v, want %d sat", a.Fee, fee)
+	}
+	if a.EstimatedVSize <= 0 || a.EstimatedFeeRate <= 0 {
+		t.Errorf("estimates = %d vB / %v sat/vB, want > 0", a.EstimatedVSize, a.EstimatedFeeRate)
+	}
+	if len(a.Inputs) == 0 {
+		t.Fatal("expected at least one input")
+	}
+	for i, in := range a.Inputs {
+		if !in.HasUTXO || in.IsFinal || len(in.Missing.Signatures) == 0 {
+			t.Errorf("input %d = %+v, want UTXO present and signature missing", i, in)
+		}
+	}
+
+	if _, err := rt.AnalyzePSBT("not a psbt"); err == nil {
+		t.Error("garbage PSBT should fail")
+	}
+	if _, err := rt.AnalyzePSBT(""); err == nil {
+		t.Error("empty PSBT should reject")
+	}
+}
+
+func TestRPC_MempoolMinFee_WouldRelay(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(userWallet)
+
+	floor, err := rt.MempoolMinFee()
+	if err != nil {
+		t.Fatalf("MempoolMinFee: %v", err)
+	}
+	if floor <= 0 {
+		t.Fatalf("MempoolMinFee = %v, want > 0", floor)
+	}
+
+	addrStr, err := rt.GenerateBech32(userWallet)
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := rt.Warp(101, addrStr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	addr, err := btcutil.DecodeAddress(addrStr, &chaincfg.RegressionNetParams)
+	if err != nil {
+		t.Fatalf("DecodeAddress: %v", err)
+	}
+	hash, err := rt.GetBlockHash(1)
+	if err != nil {
+		t.Fatalf("GetBlockHash: %v", err)
+	}
+	block, err := rt.GetBlock(hash)
+	if err != nil {
+		t.Fatalf("GetBlock: %v", err)
+	}
+	coinbase := block.Transactions[0]
+	inputs := []btcjson.TransactionInput{{Txid: coinbase.TxHash().String(), Vout: 0}}
+
+	build := func(fee btcutil.Amount) *wire.MsgTx {
+		t.Helper()
+		value := btcutil.Amount(coinbase.TxOut[0].Value) - fee
+		tx, err := rt.CreateRawTransaction(inputs, map[btcutil.Address]btcutil.Amount{addr: value}, nil)
+		if err != nil {
+			t.Fatalf("CreateRawTransaction: %v", err)
+		}
+		signed, err := rt.SignRawTransactionWithWallet(tx)
+		if err != nil {
+			t.Fatalf("SignRawTransactionWithWallet: %v", err)
+		}
+		return signed
+	}
+
+	ok, reason, err := rt.WouldRelay(build(0))
+	if err != nil {
+		t.Fatalf("WouldRelay zero fee: %v", err)
+	}
+	if ok {
+		t.Error("zero-fee tx should not relay")
+	}
+	if !strings.Contains(reason, "below mempool min fee") {
+		t.Errorf("reason = %q, want the local fee-rate comparison", reason)
+	}
+
+	ok, reason, err = rt.WouldRelay(build(10_000))
+	if err != nil {
+		t.Fatalf("WouldRelay: %v", err)
+	}
+	if !ok {
+		t.Errorf("10k-sat fee tx should relay, got %q", reason)
+	}
+
+	if _, _, err := rt.WouldRelay(nil); err == nil {
+		t.Error("nil tx should reject")
+	}
+}
+
+func TestRPC_MigrateWallet(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	const legacy = "legacy_migrate"
+	// createwallet name disable_private_keys blank passphrase avoid_reuse descriptors=false.
+	// Builds without BDB support (Core 29+ by default) can't create one.
+	if _, err := rt.rawRPC(context.Background(), "createwallet", legacy, false, false, "", false, false); err != nil {
+		t.Skipf("cannot create a legacy wallet on this build: %v", err)
+	}
+	if err := rt.UnloadWallet(legacy); err != nil {
+		t.Fatalf("UnloadWallet: %v", err)
+	}
+
+	res, err := rt.MigrateWallet(legacy)
+	if errors.Is(err, ErrUnsupportedVersion) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatalf("MigrateWallet: %v", err)
+	}
+	defer rt.UnloadWallet(res.WalletName)
+	if res.WalletName != legacy {
+		t.Errorf("WalletName = %q, want %q", res.WalletName, legacy)
+	}
+	if res.BackupPath == "" {
+		t.Error("expected a backup path")
+	}
+
+	// A descriptor wallet can't be migrated again.
+	if _, err := rt.MigrateWallet(legacy); err == nil {
+		t.Error("migrating an already-migrated wallet should fail")
+	}
+	if _, err := rt.MigrateWallet(""); err == nil {
+		t.Error("empty name should reject")
+	}
+}
+
+// TestRPC_DumpImportWallet rejects a descriptor wallet, then dumps a legacy
+// wallet and imports the dump into a second legacy wallet, checking the key
+// moved with it.
+func TestRPC_DumpImportWallet(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+	dump := filepath.Join(t.TempDir(), "keys.txt")
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	if _, err := rt.DumpWallet(dump); !errors.Is(err, ErrDescriptorWallet) {
+		t.Errorf("descriptor wallet: DumpWallet err = %v, want ErrDescriptorWallet", err)
+	}
+	if err := rt.ImportWallet(dump); !errors.Is(err, ErrDescriptorWallet) {
+		t.Errorf("descriptor wallet: ImportWallet err = %v, want ErrDescriptorWallet", err)
+	}
+	if err := rt.UnloadWallet(userWallet); err != nil {
+		t.Fatalf("UnloadWallet: %v", err)
+	}
+
+	// createwallet name disable_private_keys blank passphrase avoid_reuse descriptors=false.
+	if _, err := rt.rawRPC(context.Background(), "createwallet", "legacy_src", false, false, "", false, false); err != nil {
+		t.Skipf("cannot create a legacy wallet on this build: %v", err)
+	}
+	addr, err := rt.GenerateBech32("portable")
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	res, err := rt.DumpWallet(dump)
+	if err != nil {
+		t.Fatalf("DumpWallet: %v", err)
+	}
+	if res.Filename != dump {
+		t.Errorf("Filename = %q, want %q", res.Filename, dump)
+	}
+	if err := rt.UnloadWallet("legacy_src"); err != nil {
+		t.Fatalf("UnloadWallet: %v", err)
+	}
+
+	if _, err := rt.rawRPC(context.Background(), "createwallet", "legacy_dst", false, false, "", false, false); err != nil {
+		t.Fatalf("createwallet legacy_dst: %v", err)
+	}
+	defer rt.UnloadWallet("legacy_dst")
+	if err := rt.ImportWallet(res.Filename); err != nil {
+		t.Fatalf("ImportWallet: %v", err)
+	}
+	resp, err := rt.rawRPC(context.Background(), "getaddressinfo", addr)
+	if err != nil {
+		t.Fatalf("getaddressinfo: %v", err)
+	}
+	var info struct {
+		IsMine bool `json:"ismine"`
+	}
+	if err := json.Unmarshal(resp, &info); err != nil {
+		t.Fatalf("unmarshal getaddressinfo: %v", err)
+	}
+	if !info.IsMine {
+		t.Errorf("address %s not owned by the importing wallet", addr)
+	}
+
+	if _, err := rt.DumpWallet(""); err == nil {
+		t.Error("empty path should error")
+	}
+}
+
+func TestRPC_StressMine(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.CacheHeight = true
+	rt, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(userWallet)
+
+	addr, err := rt.GenerateBech32(userWallet)
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := rt.StressMine(ctx, 8, 5, addr); err != nil {
+		t.Fatalf("StressMine: %v", err)
+	}
+	h, err := rt.GetBlockCount()
+	if err != nil {
+		t.Fatalf("GetBlockCount: %v", err)
+	}
+	if h != 40 {
+		t.Errorf("height = %d, want 40", h)
+	}
+
+	if err := rt.StressMine(ctx, 0, 5, addr); err == nil {
+		t.Error("zero goroutines should reject")
+	}
+	if err := rt.StressMine(ctx, 2, 0, addr); err == nil {
+		t.Error("zero blocksEach should reject")
+	}
+	if err := rt.StressMine(ctx, 2, 1, ""); err == nil {
+		t.Error("empty miner should reject")
+	}
+}
+
+func TestRPC_CheckUTXO(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(userWallet)
+
+	addr, err := rt.GenerateBech32(userWallet)
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := rt.Warp(1, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	hash, err := rt.GetBlockHash(1)
+	if err != nil {
+		t.Fatalf("GetBlockHash: %v", err)
+	}
+	block, err := rt.GetBlock(hash)
+	if err != nil {
+		t.Fatalf("GetBlock: %v", err)
+	}
+	coinbase := block.Transactions[0]
+	op := wire.OutPoint{Hash: coinbase.TxHash(), Index: 0}
+	value := coinbase.TxOut[0].Value
+
+	rt.AssertUTXO(t, op, value, false)
+	if err := rt.CheckUTXO(op, value+1, false); err == nil || !strings.Contains(err.Error(), "value") {
+		t.Errorf("wrong value: err = %v, want a value mismatch", err)
+	}
+	missing := wire.OutPoint{Hash: op.Hash, Index: 99}
+	if err := rt.CheckUTXO(missing, value, true); err == nil || !strings.Contains(err.Error(), "spent or does not exist") {
+		t.Errorf("missing output: err = %v, want spent-or-missing", err)
+	}
+}
+
+func TestRPC_TotalValue(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(userWallet)
+
+	addr, err := rt.GenerateBech32(userWallet)
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := rt.Warp(101, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	// The genesis coinbase is unspendable and never enters the UTXO set.
+	before, err := rt.TotalValue()
+	if err != nil {
+		t.Fatalf("TotalValue: %v", err)
+	}
+	AssertValueConserved(t, 0, before, BlockSubsidies(0, 101))
+
+	if _, err := rt.SendToAddress(addr, 10_000_000); err != nil {
+		t.Fatalf("SendToAddress: %v", err)
+	}
+	// The fee moves into the next coinbase, so only the subsidy is new.
+	if err := rt.Warp(1, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	after, err := rt.TotalValue()
+	if err != nil {
+		t.Fatalf("TotalValue: %v", err)
+	}
+	AssertValueConserved(t, before, after, BlockSubsidies(101, 102))
+}
+
+// TestRPC_GetChainWork checks chain work grows by 2 per minimum-difficulty
+// block and follows the active tip across an invalidation.
+func TestRPC_GetChainWork(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	const miner = "bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl"
+	before, err := rt.GetChainWork()
+	if err != nil {
+		t.Fatalf("GetChainWork: %v", err)
+	}
+	hashes, err := rt.WarpBlocks(3, miner)
+	if err != nil {
+		t.Fatalf("WarpBlocks: %v", err)
+	}
+	after, err := rt.GetChainWork()
+	if err != nil {
+		t.Fatalf("GetChainWork: %v", err)
+	}
+	if got := new(big.Int).Sub(after, before); got.Cmp(big.NewInt(6)) != 0 {
+		t.Errorf("chain work grew by %s over 3 blocks, want 6", got)
+	}
+
+	if err := rt.InvalidateBlock(hashes[2]); err != nil {
+		t.Fatalf("InvalidateBlock: %v", err)
+	}
+	shorter, err := rt.GetChainWork()
+	if err != nil {
+		t.Fatalf("GetChainWork: %v", err)
+	}
+	if got := new(big.Int).Sub(after, shorter); got.Cmp(big.NewInt(2)) != 0 {
+		t.Errorf("invalidating the tip removed %s work, want 2", got)
+	}
+	if err := rt.ReconsiderBlock(hashes[2]); err != nil {
+		t.Fatalf("ReconsiderBlock: %v", err)
+	}
+	restored, err := rt.GetChainWork()
+	if err != nil {
+		t.Fatalf("GetChainWork: %v", err)
+	}
+	if restored.Cmp(after) != 0 {
+		t.Errorf("chain work after ReconsiderBlock = %s, want %s", restored, after)
+	}
+}
+
+// TestRPC_CoinbaseMaturityRemaining walks a coinbase output from 1
+// confirmation to maturity and checks a wallet transaction's output is
+// rejected with ErrNotCoinbase.
+func TestRPC_CoinbaseMaturityRemaining(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(userWallet)
+
+	addr, err := rt.GenerateBech32(userWallet)
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := rt.Warp(1, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	hash, err := rt.GetBlockHash(1)
+	if err != nil {
+		t.Fatalf("GetBlockHash: %v", err)
+	}
+	block, err := rt.GetBlock(hash)
+	if err != nil {
+		t.Fatalf("GetBlock: %v", err)
+	}
+	op := wire.OutPoint{Hash: block.Transactions[0].TxHash(), Index: 0}
+
+	for _, step := range []struct {
+		mine int64
+		want int
+	}{
+		{0, 99},
+		{98, 1},
+		{1, 0},
+		{5, 0},
+	} {
+		if step.mine > 0 {
+			if err := rt.Warp(step.mine, addr); err != nil {
+				t.Fatalf("Warp: %v", err)
+			}
+		}
+		got, err := rt.CoinbaseMaturityRemaining(op)
+		if err != nil {
+			t.Fatalf("CoinbaseMaturityRemaining: %v", err)
+		}
+		if got != step.want {
+			t.Errorf("after mining %d more: remaining = %d, want %d", step.mine, got, step.want)
+		}
+	}
+
+	txid, err := rt.SendToAddress(addr, 1_000_000)
+	if err != nil {
+		t.Fatalf("SendToAddress: %v", err)
+	}
+	if err := rt.Warp(1, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	if _, err := rt.CoinbaseMaturityRemaining(wire.OutPoint{Hash: *txid, Index: 0}); !errors.Is(err, ErrNotCoinbase) {
+		t.Errorf("wallet tx output: err = %v, want ErrNotCoinbase", err)
+	}
+	if _, err := rt.CoinbaseMaturityRemaining(wire.OutPoint{Hash: op.Hash, Index: 99}); err == nil {
+		t.Error("missing output should error")
+	}
+}
+
+// TestRPC_WarpWithTransactions mines a policy-rejected but consensus-valid
+// tx (a bare OP_TRUE output) directly into a block, then checks a
+// consensus-invalid block (the same input spent again) is refused.
+func TestRPC_WarpWithTransactions(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(userWallet)
+
+	addr, err := rt.GenerateBech32(userWallet)
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := rt.Warp(101, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	hash, err := rt.GetBlockHash(1)
+	if err != nil {
+		t.Fatalf("GetBlockHash: %v", err)
+	}
+	block, err := rt.GetBlock(hash)
+	if err != nil {
+		t.Fatalf("GetBlock: %v", err)
+	}
+	coinbase := block.Transactions[0]
+
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: coinbase.TxHash(), Index: 0}, nil, nil))
+	tx.AddTxOut(wire.NewTxOut(coinbase.TxOut[0].Value-10_000, []byte{txscript.OP_TRUE}))
+	signed, err := rt.SignRawTransactionWithWallet(tx)
+	if err != nil {
+		t.Fatalf("SignRawTransactionWithWallet: %v", err)
+	}
+
+	results, err := rt.TestMempoolAccept(signed)
+	if err != nil {
+		t.Fatalf("TestMempoolAccept: %v", err)
+	}
+	if len(results) != 1 || results[0].Allowed {
+		t.Fatalf("bare OP_TRUE output should fail policy, got %+v", results)
+	}
+
+	mined, err := rt.WarpWithTransactions(addr, []*wire.MsgTx{signed})
+	if err != nil {
+		t.Fatalf("WarpWithTransactions: %v", err)
+	}
+	got, err := rt.GetBlock(mined)
+	if err != nil {
+		t.Fatalf("GetBlock: %v", err)
+	}
+	if len(got.Transactions) != 2 || got.Transactions[1].TxHash() != signed.TxHash() {
+		t.Fatalf("block should hold coinbase + %s, got %d txs", signed.TxHash(), len(got.Transactions))
+	}
+
+	if _, err := rt.WarpWithTransactions(addr, []*wire.MsgTx{signed}); err == nil {
+		t.Error("re-mining a spent input should fail block validation")
+	}
+	if _, err := rt.WarpWithTransactions(addr, []*wire.MsgTx{nil}); err == nil {
+		t.Error("nil tx should error")
+	}
+}
+
+// TestRPC_GetAddressesByLabel checks every address generated under a label
+// comes back with purpose "receive", other labels' addresses don't, and an
+// unused label yields an empty map.
+func TestRPC_GetAddressesByLabel(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(userWallet)
+
+	want, err := rt.GenerateAddresses("payroll", "bech32", 3)
+	if err != nil {
+		t.Fatalf("GenerateAddresses: %v", err)
+	}
+	taproot, err := rt.GenerateBech32m("payroll")
+	if err != nil {
+		t.Fatalf("GenerateBech32m: %v", err)
+	}
+	want = append(want, taproot)
+	other, err := rt.GenerateBech32("other")
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+
+	got, err := rt.GetAddressesByLabel("payroll")
+	if err != nil {
+		t.Fatalf("GetAddressesByLabel: %v", err)
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %d addresses, want %d: %v", len(got), len(want), got)
+	}
+	for _, a := range want {
+		info, ok := got[a]
+		if !ok {
+			t.Errorf("address %s missing from label", a)
+			continue
+		}
+		if info.Purpose != "receive" {
+			t.Errorf("address %s purpose = %q, want receive", a, info.Purpose)
+		}
+	}
+	if _, ok := got[other]; ok {
+		t.Errorf("address %s from another label listed under payroll", other)
+	}
+
+	none, err := rt.GetAddressesByLabel("unused")
+	if err != nil {
+		t.Fatalf("GetAddressesByLabel(unused): %v", err)
+	}
+	if none == nil || len(none) != 0 {
+		t.Errorf("unused label = %#v, want empty map", none)
+	}
+}
+
+// TestRPC_SetLabel relabels a wallet address and checks it moves between
+// labels, and that a foreign address is refused.
+func TestRPC_SetLabel(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(userWallet)
+
+	addr, err := rt.GenerateBech32("old")
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := rt.SetLabel(addr, "new"); err != nil {
+		t.Fatalf("SetLabel: %v", err)
+	}
+	newAddrs, err := rt.GetAddressesByLabel("new")
+	if err != nil {
+		t.Fatalf("GetAddressesByLabel(new): %v", err)
+	}
+	if _, ok := newAddrs[addr]; !ok {
+		t.Errorf("address %s missing from new label: %v", addr, newAddrs)
+	}
+	oldAddrs, err := rt.GetAddressesByLabel("old")
+	if err != nil {
+		t.Fatalf("GetAddressesByLabel(old): %v", err)
+	}
+	if _, ok := oldAddrs[addr]; ok {
+		t.Errorf("address %s still listed under old label", addr)
+	}
+
+	// A P2WSH(OP_TRUE) address no wallet key can own.
+	foreign, err := anyoneCanSpendAddress()
+	if err != nil {
+		t.Fatalf("anyoneCanSpendAddress: %v", err)
+	}
+	if err := rt.SetLabel(foreign, "new"); err == nil || !strings.Contains(err.Error(), "not owned") {
+		t.Errorf("SetLabel(foreign) err = %v, want not-owned error", err)
+	}
+	if err := rt.SetLabel("", "new"); err == nil {
+		t.Error("SetLabel(\"\") should error")
+	}
+}
+
+// TestRPC_SpendableOutPoints loads two wallets, funds one with mature and
+// immature coinbases plus a wallet payment, and checks the named wallet's
+// listing: only mature coinbases, the coinbase flag, minConf filtering, and
+// nothing from the other wallet.
+func TestRPC_SpendableOutPoints(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	for _, w := range []string{minerWallet, userWallet} {
+		if err := rt.EnsureWallet(w); err != nil {
+			t.Fatalf("EnsureWallet(%s): %v", w, err)
+		}
+		defer rt.UnloadWallet(w)
+	}
+	resp, err := rt.walletRawRPC(context.Background(), minerWallet, "getnewaddress")
+	if err != nil {
+		t.Fatalf("getnewaddress: %v", err)
+	}
+	var miner string
+	if err := json.Unmarshal(resp, &miner); err != nil {
+		t.Fatalf("unmarshal address: %v", err)
+	}
+	if err := rt.Warp(101, miner); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+
+	utxos, err := rt.SpendableOutPoints(minerWallet, 1)
+	if err != nil {
+		t.Fatalf("SpendableOutPoints: %v", err)
+	}
+	// 101 coinbases, of which only the first has 100 confirmations.
+	if len(utxos) != 1 {
+		t.Fatalf("got %d spendable outputs, want 1 mature coinbase: %+v", len(utxos), utxos)
+	}
+	cb := utxos[0]
+	if !cb.Coinbase || cb.Amount != 50*btcutil.SatoshiPerBitcoin || cb.Address != miner {
+		t.Errorf("coinbase utxo = %+v", cb)
+	}
+	rt.AssertUTXO(t, cb.OutPoint, int64(cb.Amount), false)
+
+	user, err := rt.SpendableOutPoints(userWallet, 0)
+	if err != nil {
+		t.Fatalf("SpendableOutPoints(user): %v", err)
+	}
+	if len(user) != 0 {
+		t.Errorf("user wallet should be empty, got %+v", user)
+	}
+
+	// A 0-conf wallet spend: change shows up at minConf 0 only.
+	if _, err := rt.walletRawRPC(context.Background(), minerWallet, "sendtoaddress", miner, 1.0); err != nil {
+		t.Fatalf("sendtoaddress: %v", err)
+	}
+	conf, err := rt.SpendableOutPoints(minerWallet, 1)
+	if err != nil {
+		t.Fatalf("SpendableOutPoints(minConf 1): %v", err)
+	}
+	if len(conf) != 0 {
+		t.Errorf("minConf 1 after spending the only coinbase = %+v, want none", conf)
+	}
+	unconf, err := rt.SpendableOutPoints(minerWallet, 0)
+	if err != nil {
+		t.Fatalf("SpendableOutPoints(minConf 0): %v", err)
+	}
+	if len(unconf) != 2 {
+		t.Fatalf("minConf 0 = %d outputs, want payment + change", len(unconf))
+	}
+	for _, u := range unconf {
+		if u.Coinbase || u.Confirmations != 0 {
+			t.Errorf("mempool utxo = %+v, want non-coinbase with 0 confirmations", u)
+		}
+	}
+
+	if _, err := rt.SpendableOutPoints(minerWallet, -1); err == nil {
+		t.Error("negative minConf should error")
+	}
+}
+
+// TestRPC_ScanBlocks mines to a watched address at two heights, spends one
+// of those outputs, and checks scanblocks still finds both blocks. A node
+// without -blockfilterindex must report ErrBlockFilterIndexDisabled.
+func TestRPC_ScanBlocks(t *testing.T) {
+	plain, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := plain.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	_, err = plain.ScanBlocks([]string{"addr(bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl)"}, 0, -1)
+	if errors.Is(err, ErrUnsupportedVersion) {
+		_ = plain.Stop()
+		t.Skipf("scanblocks unsupported: %v", err)
+	}
+	if !errors.Is(err, ErrBlockFilterIndexDisabled) {
+		t.Errorf("without -blockfilterindex: err = %v, want ErrBlockFilterIndexDisabled", err)
+	}
+	if err := plain.Stop(); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.ExtraArgs = []string{"-blockfilterindex=1"}
+	rt, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(userWallet)
+	watched, err := rt.GenerateBech32("watched")
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	other, err := rt.GenerateBech32("other")
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+
+	if err := rt.Warp(1, watched); err != nil { // height 1
+		t.Fatalf("Warp: %v", err)
+	}
+	if err := rt.Warp(100, other); err != nil { // heights 2..101
+		t.Fatalf("Warp: %v", err)
+	}
+	if err := rt.Warp(1, watched); err != nil { // height 102
+		t.Fatalf("Warp: %v", err)
+	}
+	// Sweep every mature output, including the height-1 coinbase, so that
+	// match is gone from the UTXO set.
+	otherScript, err := ScriptForAddress(other)
+	if err != nil {
+		t.Fatalf("ScriptForAddress: %v", err)
+	}
+	if _, err := rt.SweepToScript(otherScript, 2); err != nil {
+		t.Fatalf("SweepToScript: %v", err)
+	}
+	if err := rt.Warp(1, other); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+
+	want := map[string]bool{}
+	for _, h := range []int64{1, 102} {
+		hash, err := rt.GetBlockHash(h)
+		if err != nil {
+			t.Fatalf("GetBlockHash(%d): %v", h, err)
+		}
+		want[hash.String()] = true
+	}
+
+	res, err := rt.ScanBlocks([]string{"addr(" + watched + ")"}, 0, -1)
+	if err != nil {
+		t.Fatalf("ScanBlocks: %v", err)
+	}
+	for _, b := range res.RelevantBlocks {
+		delete(want, b)
+	}
+	if len(want) != 0 {
+		t.Errorf("blocks %v missing from relevant_blocks %v", want, res.RelevantBlocks)
+	}
+	if res.FromHeight != 0 || res.ToHeight != 103 {
+		t.Errorf("scanned %d..%d, want 0..103", res.FromHeight, res.ToHeight)
+	}
+
+	ranged, err := rt.ScanBlocks([]string{"addr(" + watched + ")"}, 2, 101)
+	if err != nil {
+		t.Fatalf("ScanBlocks(2..101): %v", err)
+	}
+	if ranged.ToHeight != 101 {
+		t.Errorf("ranged ToHeight = %d, want 101", ranged.ToHeight)
+	}
+
+	if _, err := rt.ScanBlocks(nil, 0, -1); err == nil {
+		t.Error("no descriptors should error")
+	}
+	if _, err := rt.ScanBlocks([]string{"addr(" + watched + ")"}, 5, 4); err == nil {
+		t.Error("stopHeight below startHeight should error")
+	}
+}
+
+// TestRPC_ConfirmStable confirms a tx to depth 3, reorgs its block out so
+// the tx drops back to the mempool, and checks ConfirmStable re-mines it to
+// a new block at the required depth.
+func TestRPC_ConfirmStable(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(userWallet)
+	addr, err := rt.GenerateBech32(userWallet)
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := rt.Warp(101, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	txid, err := rt.SendToAddress(addr, 1_000_000)
+	if err != nil {
+		t.Fatalf("SendToAddress: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := rt.ConfirmStable(ctx, txid, 3, addr); err != nil {
+		t.Fatalf("ConfirmStable: %v", err)
+	}
+	conf, first, err := rt.txDepth(ctx, txid)
+	if err != nil {
+		t.Fatalf("txDepth: %v", err)
+	}
+	if conf < 3 {
+		t.Fatalf("confirmations = %d, want >= 3", conf)
+	}
+
+	// Reorg the containing block out; the tx returns to the mempool.
+	firstHash, err := chainhash.NewHashFromStr(first)
+	if err != nil {
+		t.Fatalf("NewHashFromStr: %v", err)
+	}
+	if err := rt.InvalidateBlock(firstHash); err != nil {
+		t.Fatalf("InvalidateBlock: %v", err)
+	}
+	if conf, _, err := rt.txDepth(ctx, txid); err != nil || conf != 0 {
+		t.Fatalf("after reorg: confirmations = %d, err = %v; want 0 (mempool)", conf, err)
+	}
+
+	// Mine the replacement chain to a fresh address so its blocks can't
+	// hash-collide with the invalidated ones.
+	addr2, err := rt.GenerateBech32(userWallet)
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := rt.ConfirmStable(ctx, txid, 2, addr2); err != nil {
+		t.Fatalf("ConfirmStable after reorg: %v", err)
+	}
+	conf, second, err := rt.txDepth(ctx, txid)
+	if err != nil {
+		t.Fatalf("txDepth: %v", err)
+	}
+	if conf < 2 || second == first {
+		t.Errorf("after re-confirm: confirmations = %d in %s, want >= 2 in a block other than %s", conf, second, first)
+	}
+
+	if err := rt.ConfirmStable(ctx, txid, 0, addr); err == nil {
+		t.Error("minConf 0 should error")
+	}
+}
+
+// TestRPC_ExpectReject covers the three outcomes: rejected for the wanted
+// reason (nil), rejected for another reason (error quoting it), and
+// accepted (error).
+func TestRPC_ExpectReject(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(userWallet)
+	addr, err := rt.GenerateBech32(userWallet)
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := rt.Warp(101, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	script, err := ScriptForAddress(addr)
+	if err != nil {
+		t.Fatalf("ScriptForAddress: %v", err)
+	}
+	hash, err := rt.GetBlockHash(1)
+	if err != nil {
+		t.Fatalf("GetBlockHash: %v", err)
+	}
+	block, err := rt.GetBlock(hash)
+	if err != nil {
+		t.Fatalf("GetBlock: %v", err)
+	}
+	coinbase := block.Transactions[0]
+	spend := func(fee int64) *wire.MsgTx {
+		t.Helper()
+		tx := wire.NewMsgTx(2)
+		tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: coinbase.TxHash(), Index: 0}, nil, nil))
+		tx.AddTxOut(wire.NewTxOut(coinbase.TxOut[0].Value-fee, script))
+		signed, err := rt.SignRawTransactionWithWallet(tx)
+		if err != nil {
+			t.Fatalf("SignRawTransactionWithWallet: %v", err)
+		}
+		return signed
+	}
+
+	first := spend(10_000)
+	if err := rt.ExpectReject(first, "bad-txns-inputs-missingorspent"); err == nil || !strings.Contains(err.Error(), "accepted") {
+		t.Errorf("valid tx: err = %v, want an accepted error", err)
+	}
+	if err := rt.Warp(1, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+
+	conflict := spend(20_000)
+	if err := rt.ExpectReject(conflict, "bad-txns-inputs-missingorspent"); err != nil {
+		t.Errorf("double spend: %v", err)
+	}
+	err = rt.ExpectReject(conflict, "min relay fee not met")
+	if err == nil || !strings.Contains(err.Error(), "bad-txns-inputs-missingorspent") {
+		t.Errorf("wrong reason: err = %v, want one quoting the actual reason", err)
+	}
+	if err := rt.ExpectReject(conflict, ""); err == nil {
+		t.Error("empty wantReason should error")
+	}
+}
+
+// TestRPC_CreateTxChain builds a chain offline from a mature coinbase,
+// broadcasts it in order, and checks the tip's ancestor count and that each
+// link spends its parent.
+func TestRPC_CreateTxChain(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(userWallet)
+	addr, err := rt.GenerateBech32(userWallet)
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := rt.Warp(101, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	hash, err := rt.GetBlockHash(1)
+	if err != nil {
+		t.Fatalf("GetBlockHash: %v", err)
+	}
+	block, err := rt.GetBlock(hash)
+	if err != nil {
+		t.Fatalf("GetBlock: %v", err)
+	}
+	funding := wire.OutPoint{Hash: block.Transactions[0].TxHash(), Index: 0}
+
+	const length = 5
+	chain, err := rt.CreateTxChain(funding, length, 2)
+	if err != nil {
+		t.Fatalf("CreateTxChain: %v", err)
+	}
+	if len(chain) != length {
+		t.Fatalf("len(chain) = %d, want %d", len(chain), length)
+	}
+	prev := funding
+	for i, tx := range chain {
+		if got := tx.TxIn[0].PreviousOutPoint; got != prev {
+			t.Errorf("chain[%d] spends %v, want %v", i, got, prev)
+		}
+		prev = wire.OutPoint{Hash: *ComputeTxID(tx), Index: 0}
+		if _, err := rt.BroadcastTransaction(tx); err != nil {
+			t.Fatalf("broadcast chain[%d]: %v", i, err)
+		}
+	}
+
+	pool, err := rt.GetRawMempoolVerbose()
+	if err != nil {
+		t.Fatalf("GetRawMempoolVerbose: %v", err)
+	}
+	tip, ok := pool[prev.Hash.String()]
+	if !ok {
+		t.Fatalf("chain tip %s not in mempool", prev.Hash)
+	}
+	if tip.AncestorCount != length {
+		t.Errorf("tip AncestorCount = %d, want %d", tip.AncestorCount, length)
+	}
+
+	if _, err := rt.CreateTxChain(funding, 1, 2); err == nil {
+		t.Error("spent funding output should error")
+	}
+	if _, err := rt.CreateTxChain(prev, 0, 2); err == nil {
+		t.Error("zero length should error")
+	}
+	for _, rate := range []float64{0, math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if _, err := rt.CreateTxChain(prev, 1, rate); err == nil {
+			t.Errorf("fee rate %v should error", rate)
+		}
+	}
+}
+
+// TestRPC_CreateSpendableUTXO checks the returned outpoint is the
+// confirmed payment of the requested amount, not the change.
+func TestRPC_CreateSpendableUTXO(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(userWallet)
+	addr, err := rt.GenerateBech32(userWallet)
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := rt.Warp(101, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+
+	const sats = 123_456
+	op, err := rt.CreateSpendableUTXO(sats, addr)
+	if err != nil {
+		t.Fatalf("CreateSpendableUTXO: %v", err)
+	}
+	out, err := rt.GetTxOut(&op.Hash, op.Index, false)
+	if err != nil {
+		t.Fatalf("GetTxOut: %v", err)
+	}
+	if out == nil {
+		t.Fatalf("outpoint %v is not an unspent confirmed output", op)
+	}
+	if got, err := btcutil.NewAmount(out.Value); err != nil || int64(got) != sats {
+		t.Errorf("output value = %v, want %d sats", out.Value, sats)
+	}
+	if out.Confirmations != 1 {
+		t.Errorf("confirmations = %d, want 1", out.Confirmations)
+	}
+
+	if _, err := rt.CreateSpendableUTXO(0, addr); err == nil {
+		t.Error("zero amount should error")
+	}
+}
+
+// TestRPC_SpendUTXO spends chosen UTXOs with SpendUTXO and checks the
+// input, payment, change and fee rate, plus the dust-change and
+// insufficient-value paths.
+func TestRPC_SpendUTXO(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(userWallet)
+	addr, err := rt.GenerateBech32(userWallet)
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := rt.Warp(101, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	const dest = "bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl"
+	destScript, err := ScriptForAddress(dest)
+	if err != nil {
+		t.Fatalf("ScriptForAddress: %v", err)
+	}
+
+	spend := func(value, sats int64, feeRate float64) (*wire.MsgTx, MempoolEntry) {
+		t.Helper()
+		utxo, err := rt.CreateSpendableUTXO(value, addr)
+		if err != nil {
+			t.Fatalf("CreateSpendableUTXO: %v", err)
+		}
+		txid, err := rt.SpendUTXO(utxo, dest, sats, feeRate)
+		if err != nil {
+			t.Fatalf("SpendUTXO: %v", err)
+		}
+		tx, err := rt.Client().GetRawTransaction(txid)
+		if err != nil {
+			t.Fatalf("GetRawTransaction: %v", err)
+		}
+		msg := tx.MsgTx()
+		if len(msg.TxIn) != 1 || msg.TxIn[0].PreviousOutPoint != utxo {
+			t.Errorf("inputs = %v, want exactly %v", msg.TxIn, utxo)
+		}
+		if msg.TxOut[0].Value != sats || !bytes.Equal(msg.TxOut[0].PkScript, destScript) {
+			t.Errorf("output 0 = %d to %x, want %d to %x", msg.TxOut[0].Value, msg.TxOut[0].PkScript, sats, destScript)
+		}
+		mempool, err := rt.GetRawMempoolVerbose()
+		if err != nil {
+			t.Fatalf("GetRawMempoolVerbose: %v", err)
+		}
+		entry, ok := mempool[txid.String()]
+		if !ok {
+			t.Fatalf("tx %s not in mempool", txid)
+		}
+		return msg, entry
+	}
+
+	tx, entry := spend(1_000_000, 400_000, 5)
+	if len(tx.TxOut) != 2 {
+		t.Fatalf("outputs = %d, want payment and change", len(tx.TxOut))
+	}
+	if rate := float64(entry.Fee) / float64(entry.VSize); rate < 5 || rate > 5.5 {
+		t.Errorf("fee rate = %.2f sat/vB (fee %v, vsize %d), want ~5", rate, entry.Fee, entry.VSize)
+	}
+	if got := tx.TxOut[1].Value + 400_000 + int64(entry.Fee); got != 1_000_000 {
+		t.Errorf("outputs plus fee = %d, want the input's 1000000", got)
+	}
+
+	// ~150 sats of change at 1 sat/vB is dust, so it goes to the fee.
+	tx, entry = spend(100_000, 99_700, 1)
+	if len(tx.TxOut) != 1 || int64(entry.Fee) != 300 {
+		t.Errorf("dust change: %d outputs, fee %v; want 1 output and a 300 sat fee", len(tx.TxOut), entry.Fee)
+	}
+
+	utxo, err := rt.CreateSpendableUTXO(50_000, addr)
+	if err != nil {
+		t.Fatalf("CreateSpendableUTXO: %v", err)
+	}
+	if _, err := rt.SpendUTXO(utxo, dest, 50_000, 1); err == nil {
+		t.Error("spending the whole input leaves nothing for the fee and should fail")
+	}
+	if _, err := rt.SpendUTXO(utxo, dest, 60_000, 1); err == nil {
+		t.Error("sending more than the input holds should fail")
+	}
+	if _, err := rt.SpendUTXO(wire.OutPoint{Hash: chainhash.Hash{1}}, dest, 1_000, 1); err == nil {
+		t.Error("an unknown input should fail")
+	}
+	for _, rate := range []float64{0, math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if _, err := rt.SpendUTXO(utxo, dest, 1_000, rate); err == nil {
+			t.Errorf("fee rate %v should fail", rate)
+		}
+	}
+}
+
+// TestRPC_ListLabels checks receive labels from GenerateBech32 and a send
+// (address-book) label are listed under the right purpose filter.
+func TestRPC_ListLabels(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(userWallet)
+	if _, err := rt.GenerateBech32("payroll"); err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	// setlabel on a foreign address creates a "send" address-book entry.
+	if _, err := rt.rawRPC(context.Background(), "setlabel", "bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl", "payee"); err != nil {
+		t.Fatalf("setlabel: %v", err)
+	}
+
+	for _, tc := range []struct {
+		purpose     string
+		want, wantN []string
+	}{
+		{purpose: "", want: []string{"payroll", "payee"}},
+		{purpose: "receive", want: []string{"payroll"}, wantN: []string{"payee"}},
+		{purpose: "send", want: []string{"payee"}, wantN: []string{"payroll"}},
+	} {
+		labels, err := rt.ListLabels(tc.purpose)
+		if err != nil {
+			t.Fatalf("ListLabels(%q): %v", tc.purpose, err)
+		}
+		for _, l := range tc.want {
+			if !slices.Contains(labels, l) {
+				t.Errorf("ListLabels(%q) = %v, missing %q", tc.purpose, labels, l)
+			}
+		}
+		for _, l := range tc.wantN {
+			if slices.Contains(labels, l) {
+				t.Errorf("ListLabels(%q) = %v, should not contain %q", tc.purpose, labels, l)
+			}
+		}
+	}
+
+	if _, err := rt.ListLabels("spend"); err == nil {
+		t.Error("unknown purpose should error")
+	}
+}
+
+// TestRPC_BroadcastIdempotent re-broadcasts a tx while it is in the
+// mempool, once confirmed, and once its output is spent too, expecting its
+// txid every time; a conflicting tx still errors.
+func TestRPC_BroadcastIdempotent(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(userWallet)
+	addr, err := rt.GenerateBech32(userWallet)
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := rt.Warp(101, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	funding, err := rt.CreateSpendableUTXO(1_000_000, addr)
+	if err != nil {
+		t.Fatalf("CreateSpendableUTXO: %v", err)
+	}
+	chain, err := rt.CreateTxChain(funding, 2, 2)
+	if err != nil {
+		t.Fatalf("CreateTxChain: %v", err)
+	}
+	parent, child := chain[0], chain[1]
+	want := ComputeTxID(parent)
+	// Same input, different fee: a double spend of parent, never broadcast.
+	conflict, err := rt.CreateTxChain(funding, 1, 5)
+	if err != nil {
+		t.Fatalf("CreateTxChain conflict: %v", err)
+	}
+
+	check := func(stage string) {
+		t.Helper()
+		got, err := rt.BroadcastIdempotent(parent)
+		if err != nil {
+			t.Fatalf("%s: BroadcastIdempotent: %v", stage, err)
+		}
+		if *got != *want {
+			t.Errorf("%s: txid = %s, want %s", stage, got, want)
+		}
+	}
+	check("first broadcast")
+	check("in mempool")
+	if err := rt.Warp(1, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	check("confirmed")
+	if _, err := rt.BroadcastTransaction(child); err != nil {
+		t.Fatalf("broadcast child: %v", err)
+	}
+	if err := rt.Warp(1, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	check("confirmed and spent")
+
+	if _, err := rt.BroadcastIdempotent(conflict[0]); err == nil {
+		t.Error("a conflicting double spend should still error")
+	}
+	if _, err := rt.BroadcastIdempotent(nil); err == nil {
+		t.Error("nil tx should error")
+	}
+}
+
+// TestRPC_WarpDetailed checks one coinbase output per mined block, in
+// order, with values and maturity matching the chain.
+func TestRPC_WarpDetailed(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(minerWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(minerWallet)
+	addr, err := rt.GenerateBech32(minerWallet)
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+
+	const blocks = 101
+	outs, err := rt.WarpDetailed(blocks, addr)
+	if err != nil {
+		t.Fatalf("WarpDetailed: %v", err)
+	}
+	if len(outs) != blocks {
+		t.Fatalf("len(outs) = %d, want %d", len(outs), blocks)
+	}
+	for i, out := range outs {
+		if want := int64(i + 1); out.Height != want {
+			t.Errorf("outs[%d].Height = %d, want %d", i, out.Height, want)
+		}
+		hash, err := rt.GetBlockHash(out.Height)
+		if err != nil {
+			t.Fatalf("GetBlockHash: %v", err)
+		}
+		if *hash != out.BlockHash {
+			t.Errorf("outs[%d].BlockHash = %s, chain has %s", i, out.BlockHash, hash)
+		}
+		if want := btcutil.Amount(blockchain.CalcBlockSubsidy(int32(i+1), &chaincfg.RegressionNetParams)); out.Amount != want {
+			t.Errorf("outs[%d].Amount = %v, want %v", i, out.Amount, want)
+		}
+		remaining, err := rt.CoinbaseMaturityRemaining(out.OutPoint)
+		if err != nil {
+			t.Fatalf("CoinbaseMaturityRemaining(outs[%d]): %v", i, err)
+		}
+		if out.Mature != (remaining == 0) {
+			t.Errorf("outs[%d].Mature = %v, but %d blocks remain", i, out.Mature, remaining)
+		}
+	}
+	if !outs[0].Mature || outs[blocks-1].Mature {
+		t.Errorf("Mature = %v (first), %v (last); want true, false", outs[0].Mature, outs[blocks-1].Mature)
+	}
+
+	if _, err := rt.WarpDetailed(0, addr); err == nil {
+		t.Error("zero blocks should error")
+	}
+}
+
+// TestRPC_ValidateDescriptorChecksum checks the local checksum accepts every
+// descriptor bitcoind exports for a fresh wallet.
+func TestRPC_ValidateDescriptorChecksum(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(userWallet)
+
+	raw, err := rt.rawRPC(context.Background(), "listdescriptors")
+	if err != nil {
+		t.Fatalf("listdescriptors: %v", err)
+	}
+	var res struct {
+		Descriptors []struct {
+			Desc string `json:"desc"`
+		} `json:"descriptors"`
+	}
+	if err := json.Unmarshal(raw, &res); err != nil {
+		t.Fatalf("decode listdescriptors: %v", err)
+	}
+	if len(res.Descriptors) == 0 {
+		t.Fatal("listdescriptors returned no descriptors")
+	}
+	for _, d := range res.Descriptors {
+		if err := ValidateDescriptorChecksum(d.Desc); err != nil {
+			t.Errorf("ValidateDescriptorChecksum(%s): %v", d.Desc, err)
+		}
+	}
+}
+
+// TestRPC_GetBlockBytes checks the raw bytes deserialize to the block
+// GetBlock returns and hash to the requested block.
+func TestRPC_GetBlockBytes(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.Warp(1, "bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl"); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	hash, err := rt.GetBestBlockHash()
+	if err != nil {
+		t.Fatalf("GetBestBlockHash: %v", err)
+	}
+	raw, err := rt.GetBlockBytes(hash)
+	if err != nil {
+		t.Fatalf("GetBlockBytes: %v", err)
+	}
+	var parsed wire.MsgBlock
+	if err := parsed.Deserialize(bytes.NewReader(raw)); err != nil {
+		t.Fatalf("Deserialize: %v", err)
+	}
+	if got := parsed.BlockHash(); got != *hash {
+		t.Errorf("block hash = %s, want %s", got, hash)
+	}
+	block, err := rt.GetBlock(hash)
+	if err != nil {
+		t.Fatalf("GetBlock: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := block.Serialize(&buf); err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+	if !bytes.Equal(raw, buf.Bytes()) {
+		t.Error("GetBlockBytes differs from GetBlock's serialization")
+	}
+
+	if _, err := rt.GetBlockBytes(nil); err == nil {
+		t.Error("GetBlockBytes(nil) should fail")
+	}
+	if _, err := rt.GetBlockBytes(&chainhash.Hash{1}); err == nil {
+		t.Error("GetBlockBytes of an unknown hash should fail")
+	}
+}
+
+// TestRPC_OutputScript checks OutputScript returns the payout script of a
+// coinbase output and honours includeMempool for unconfirmed outputs.
+func TestRPC_OutputScript(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(minerWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(minerWallet)
+	miner, err := rt.GenerateBech32(minerWallet)
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := rt.Warp(101, miner); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	want, err := ScriptForAddress(miner)
+	if err != nil {
+		t.Fatalf("ScriptForAddress: %v", err)
+	}
+
+	hash, err := rt.GetBlockHash(1)
+	if err != nil {
+		t.Fatalf("GetBlockHash: %v", err)
+	}
+	block, err := rt.GetBlock(hash)
+	if err != nil {
+		t.Fatalf("GetBlock: %v", err)
+	}
+	coinbase := wire.OutPoint{Hash: block.Transactions[0].TxHash(), Index: 0}
+	got, err := rt.OutputScript(coinbase, false)
+	if err != nil {
+		t.Fatalf("OutputScript(coinbase): %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("OutputScript(coinbase) = %x, want %x", got, want)
+	}
+
+	txid, err := rt.SendToAddress(miner, 1_000_000)
+	if err != nil {
+		t.Fatalf("SendToAddress: %v", err)
+	}
+	tx, err := rt.Client().GetRawTransaction(txid)
+	if err != nil {
+		t.Fatalf("GetRawTransaction: %v", err)
+	}
+	for i, out := range tx.MsgTx().TxOut {
+		op := wire.OutPoint{Hash: *txid, Index: uint32(i)}
+		got, err := rt.OutputScript(op, true)
+		if err != nil {
+			t.Fatalf("OutputScript(%s, mempool): %v", op, err)
+		}
+		if !bytes.Equal(got, out.PkScript) {
+			t.Errorf("OutputScript(%s) = %x, want %x", op, got, out.PkScript)
+		}
+	}
+	if _, err := rt.OutputScript(wire.OutPoint{Hash: *txid}, false); err == nil {
+		t.Error("OutputScript of an unconfirmed output without includeMempool should fail")
+	}
+	if _, err := rt.OutputScript(coinbase, true); err == nil {
+		t.Error("OutputScript of a mempool-spent output should fail with includeMempool")
+	}
+}
+
+func TestRPC_GetSpentOutputs(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(minerWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(minerWallet)
+	miner, err := rt.GenerateBech32(minerWallet)
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := rt.Warp(101, miner); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+
+	txid, err := rt.SendToAddress(miner, 1_000_000)
+	if err != nil {
+		t.Fatalf("SendToAddress: %v", err)
+	}
+	raw, err := rt.Client().GetRawTransaction(txid)
+	if err != nil {
+		t.Fatalf("GetRawTransaction: %v", err)
+	}
+	tx := raw.MsgTx()
+	pool, err := rt.GetRawMempoolVerbose()
+	if err != nil {
+		t.Fatalf("GetRawMempoolVerbose: %v", err)
+	}
+	wantFee := pool[txid.String()].Fee
+
+	check := func(when string) {
+		t.Helper()
+		spent, err := rt.GetSpentOutputs(tx)
+		if err != nil {
+			t.Fatalf("GetSpentOutputs (%s): %v", when, err)
+		}
+		if len(spent) != len(tx.TxIn) {
+			t.Fatalf("GetSpentOutputs (%s) returned %d outputs for %d inputs", when, len(spent), len(tx.TxIn))
+		}
+		fee := btcutil.Amount(0)
+		for i, u := range spent {
+			if u.OutPoint != tx.TxIn[i].PreviousOutPoint {
+				t.Errorf("%s: output %d is %s, want %s", when, i, u.OutPoint, tx.TxIn[i].PreviousOutPoint)
+			}
+			if !u.Coinbase || u.Address != miner || u.Confirmations < 100 {
+				t.Errorf("%s: output %d = %+v, want a mature coinbase paying %s", when, i, u, miner)
+			}
+			fee += u.Amount
+		}
+		for _, out := range tx.TxOut {
+			fee -= btcutil.Amount(out.Value)
+		}
+		if fee != wantFee {
+			t.Errorf("%s: fee from spent outputs = %v, mempool says %v", when, fee, wantFee)
+		}
+	}
+	check("unconfirmed")
+	// Once confirmed the prevouts are gone from the UTXO set.
+	if err := rt.Warp(1, miner); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	check("confirmed")
+
+	hash, err := rt.GetBlockHash(1)
+	if err != nil {
+		t.Fatalf("GetBlockHash: %v", err)
+	}
+	block, err := rt.GetBlock(hash)
+	if err != nil {
+		t.Fatalf("GetBlock: %v", err)
+	}
+	if spent, err := rt.GetSpentOutputs(block.Transactions[0]); err != nil || len(spent) != 0 {
+		t.Errorf("GetSpentOutputs(coinbase) = %v, %v; want empty", spent, err)
+	}
+	if _, err := rt.GetSpentOutputs(nil); err == nil {
+		t.Error("GetSpentOutputs(nil) should fail")
+	}
+}
+
+// TestRPC_RecordReplay records a short session, wipes the chain with a
+// Stop/Start, and replays the trace onto the fresh node.
+func TestRPC_RecordReplay(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	var trace bytes.Buffer
+	if err := rt.StartRecording(&trace); err != nil {
+		t.Fatalf("StartRecording: %v", err)
+	}
+	if err := rt.Warp(5, "bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl"); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	if _, err := rt.GetBlockCount(); err != nil {
+		t.Fatalf("GetBlockCount: %v", err)
+	}
+	if err := rt.StopRecording(); err != nil {
+		t.Fatalf("StopRecording: %v", err)
+	}
+	if !strings.Contains(trace.String(), `"method":"generatetoaddress"`) {
+		t.Fatalf("trace is missing generatetoaddress:\n%s", trace.String())
+	}
+
+	if err := rt.Stop(); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if err := rt.ReplayRPC(bytes.NewReader(trace.Bytes())); err != nil {
+		t.Fatalf("ReplayRPC: %v", err)
+	}
+	if h, err := rt.GetBlockCount(); err != nil || h != 5 {
+		t.Errorf("height after replay = %d, %v; want 5", h, err)
+	}
+}
+
+func TestRPC_SaveLoadMempool(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(userWallet)
+
+	addr, err := rt.GenerateBech32(userWallet)
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := rt.Warp(101, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	if _, err := rt.SendToAddress(addr, 100_000); err != nil {
+		t.Fatalf("SendToAddress: %v", err)
+	}
+
+	path, err := rt.SaveMempool()
+	if err != nil {
+		t.Fatalf("SaveMempool: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("saved mempool %q: %v", path, err)
+	}
+
+	// Re-importing the node's own mempool is a no-op for transactions
+	// already present, but exercises the full read path.
+	err = rt.LoadMempool(path)
+	if errors.Is(err, ErrUnsupportedVersion) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatalf("LoadMempool: %v", err)
+	}
+	if err := rt.LoadMempool(""); err == nil {
+		t.Error("empty path should reject")
+	}
+}
+
+func TestRPC_SetupFundedChain(t *testing.T) {
+	const btc = 100_000_000
+	rt, addr := SetupFundedChain(t, &FixtureOpts{Height: 150, FundingSats: 60 * btc})
+
+	h, err := rt.GetBlockCount()
+	if err != nil {
+		t.Fatalf("GetBlockCount: %v", err)
+	}
+	if h != 150 {
+		t.Errorf("height = %d, want 150", h)
+	}
+	if addr == "" {
+		t.Fatal("expected a funded address")
+	}
+
+	resp, err := rt.rawRPC(context.Background(), "getbalance")
+	if err != nil {
+		t.Fatalf("getbalance: %v", err)
+	}
+	var balance float64
+	if err := json.Unmarshal(resp, &balance); err != nil {
+		t.Fatalf("unmarshal getbalance: %v", err)
+	}
+	// Two 50 BTC funding blocks, both mature at height 150.
+	if balance != 100 {
+		t.Errorf("balance = %v BTC, want 100", balance)
+	}
+	if _, err := rt.SendToAddress(addr, 1_000_000); err != nil {
+		t.Errorf("funded wallet should be able to spend: %v", err)
+	}
+}
+
+func TestRPC_FreezeChain(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(userWallet)
+
+	addr, err := rt.GenerateBech32(userWallet)
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := rt.Warp(1, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+
+	rt.FreezeChain()
+	if err := rt.Warp(5, addr); !errors.Is(err, ErrChainFrozen) {
+		t.Errorf("Warp while frozen: err = %v, want ErrChainFrozen", err)
+	}
+	if err := rt.MineToHeight(10, addr); !errors.Is(err, ErrChainFrozen) {
+		t.Errorf("MineToHeight while frozen: err = %v, want ErrChainFrozen", err)
+	}
+	if _, err := rt.WarpWithCoinbaseData(addr, []byte("frozen")); !errors.Is(err, ErrChainFrozen) {
+		t.Errorf("WarpWithCoinbaseData while frozen: err = %v, want ErrChainFrozen", err)
+	}
+	h, err := rt.GetBlockCount()
+	if err != nil {
+		t.Fatalf("GetBlockCount: %v", err)
+	}
+	if h != 1 {
+		t.Errorf("height while frozen = %d, want 1", h)
+	}
+
+	rt.UnfreezeChain()
+	if err := rt.Warp(1, addr); err != nil {
+		t.Fatalf("Warp after unfreeze: %v", err)
+	}
+}
+
+// TestRPC_GetDeployment checks the single-deployment view of
+// getdeploymentinfo and its ErrUnknownDeployment contract.
+func TestRPC_GetDeployment(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	info, err := rt.GetDeployment("taproot")
+	if err != nil {
+		t.Fatalf("GetDeployment(taproot): %v", err)
+	}
+	if len(info.Deployments) != 1 {
+		t.Errorf("got %d deployments, want only taproot", len(info.Deployments))
+	}
+	if d, ok := info.Deployments["taproot"]; !ok || !d.Active {
+		t.Errorf("taproot = %+v (present %v), want active", d, ok)
+	}
+	if info.Hash == "" {
+		t.Error("expected the evaluated tip hash")
+	}
+
+	if _, err := rt.GetDeployment("definitely-not-a-real-deployment"); !errors.Is(err, ErrUnknownDeployment) {
+		t.Errorf("unknown deployment: err = %v, want ErrUnknownDeployment", err)
+	}
+}
+
+// TestRPC_GetSoftForks checks the always-on regtest deployments report
+// active and that the summary agrees with GetDeploymentInfo.
+func TestRPC_GetSoftForks(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	forks, err := rt.GetSoftForks()
+	if err != nil {
+		t.Fatalf("GetSoftForks: %v", err)
+	}
+	for _, name := range []string{"segwit", "taproot"} {
+		if sf, ok := forks[name]; !ok || !sf.Active {
+			t.Errorf("%s = %+v (present %v), want active", name, sf, ok)
+		}
+	}
+	if sf := forks["segwit"]; sf.Type != "buried" {
+		t.Errorf("segwit type = %q, want buried", sf.Type)
+	}
+
+	info, err := rt.GetDeploymentInfo()
+	if err != nil {
+		t.Fatalf("GetDeploymentInfo: %v", err)
+	}
+	for name, d := range info.Deployments {
+		if sf, ok := forks[name]; !ok || sf.Active != d.Active || sf.Type != d.Type {
+			t.Errorf("%s: GetSoftForks %+v disagrees with GetDeploymentInfo %+v", name, sf, d)
+		}
+	}
+}
+
+func TestRPC_SignRawTransactionWithKey(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	// Private keys 1 and 2, compressed, regtest WIF. Neither is in any wallet.
+	key, err := btcutil.DecodeWIF("cMahea7zqjxrtgAbB7LSGbcQUr1uX1ojuat9jZodMN87JcbXMTcA")
+	if err != nil {
+		t.Fatalf("DecodeWIF: %v", err)
+	}
+	otherKey, err := btcutil.DecodeWIF("cMahea7zqjxrtgAbB7LSGbcQUr1uX1ojuat9jZodMN87K7XCyj5v")
+	if err != nil {
+		t.Fatalf("DecodeWIF: %v", err)
+	}
+	addr, err := btcutil.NewAddressWitnessPubKeyHash(
+		btcutil.Hash160(key.SerializePubKey()), &chaincfg.RegressionNetParams)
+	if err != nil {
+		t.Fatalf("NewAddressWitnessPubKeyHash: %v", err)
+	}
+	if err := rt.Warp(101, addr.EncodeAddress()); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+
+	hash, err := rt.GetBlockHash(1)
+	if err != nil {
+		t.Fatalf("GetBlockHash: %v", err)
+	}
+	block, err := rt.GetBlock(hash)
+	if err != nil {
+		t.Fatalf("GetBlock: %v", err)
+	}
+	coinbase := block.Transactions[0]
+	prevOut := coinbase.TxOut[0]
+
+	prevHash := coinbase.TxHash()
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&prevHash, 0), nil, nil))
+	tx.AddTxOut(wire.NewTxOut(prevOut.Value-10_000, prevOut.PkScript))
+
+	amount := btcutil.Amount(prevOut.Value).ToBTC()
+	prev := []btcjson.RawTxWitnessInput{{
+		Txid:         coinbase.TxHash().String(),
+		Vout:         0,
+		ScriptPubKey: hex.EncodeToString(prevOut.PkScript),
+		Amount:       &amount,
+	}}
+
+	if _, complete, err := rt.SignRawTransactionWithKey(tx, []*btcutil.WIF{otherKey}, prev); err != nil {
+		t.Fatalf("SignRawTransactionWithKey wrong key: %v", err)
+	} else if complete {
+		t.Error("signing with an unrelated key should be incomplete")
+	}
+
+	signed, complete, err := rt.SignRawTransactionWithKey(tx, []*btcutil.WIF{key}, prev)
+	if err != nil {
+		t.Fatalf("SignRawTransactionWithKey: %v", err)
+	}
+	if !complete {
+		t.Fatal("signing with the owning key should be complete")
+	}
+	res, err := rt.TestMempoolAccept(signed)
+	if err != nil {
+		t.Fatalf("TestMempoolAccept: %v", err)
+	}
+	if !res[0].Allowed {
+		t.Errorf("signed tx rejected: %s", res[0].RejectReason)
+	}
+
+	if _, _, err := rt.SignRawTransactionWithKey(tx, nil, prev); err == nil {
+		t.Error("no keys should reject")
+	}
+}
+
+func TestRPC_CreateAndFundMultisig(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet(userWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	defer rt.UnloadWallet(userWallet)
+
+	addr, err := rt.GenerateBech32(userWallet)
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := rt.Warp(101, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+
+	// Compressed public keys of private keys 1, 2 and 3.
+	pubKeys := []string{
+		"0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798",
+		"02c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5",
+		"02f9308a019258c31049344f85f89d5229b531c845836f99b08601f113bce036f9",
+	}
+	ms, err := rt.CreateMultisig(2, pubKeys, "bech32")
+	if err != nil {
+		t.Fatalf("CreateMultisig: %v", err)
+	}
+	if !strings.HasPrefix(ms.Address, "bcrt1q") || ms.RedeemScript == "" || !strings.HasPrefix(ms.Descriptor, "wsh(multi(2,") {
+		t.Errorf("multisig = %+v, want a 2-of-3 P2WSH", ms)
+	}
+
+	op, err := rt.FundMultisig(ms, 1_000_000, addr)
+	if err != nil {
+		t.Fatalf("FundMultisig: %v", err)
+	}
+	rt.AssertUTXO(t, *op, 1_000_000, false)
+
+	// Spend it back with two of the three keys.
+	var wifs []*btcutil.WIF
+	for _, s := range []string{
+		"cMahea7zqjxrtgAbB7LSGbcQUr1uX1ojuat9jZodMN87JcbXMTcA",
+		"cMahea7zqjxrtgAbB7LSGbcQUr1uX1ojuat9jZodMN87K7XCyj5v",
+	} {
+		wif, err := btcutil.DecodeWIF(s)
+		if err != nil {
+			t.Fatalf("DecodeWIF: %v", err)
+		}
+		wifs = append(wifs, wif)
+	}
+	script, err := ScriptForAddress(ms.Address)
+	if err != nil {
+		t.Fatalf("ScriptForAddress: %v", err)
+	}
+	payout, err := ScriptForAddress(addr)
+	if err != nil {
+		t.Fatalf("ScriptForAddress: %v", err)
+	}
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(op, nil, nil))
+	tx.AddTxOut(wire.NewTxOut(990_000, payout))
+	amount := btcutil.Amount(1_000_000).ToBTC()
+	signed, complete, err := rt.SignRawTransactionWithKey(tx, wifs, []btcjson.RawTxWitnessInput{{
+		Txid:          op.Hash.String(),
+		Vout:          op.Index,
+		ScriptPubKey:  hex.EncodeToString(script),
+		WitnessScript: &ms.RedeemScript,
+		Amount:        &amount,
+	}})
+	if err != nil {
+		t.Fatalf("SignRawTransactionWithKey: %v", err)
+	}
+	if !complete {
+		t.Fatal("2 of 3 signatures should complete the multisig")
+	}
+	res, err := rt.TestMempoolAccept(signed)
+	if err != nil {
+		t.Fatalf("TestMempoolAccept: %v", err)
+	}
+	if !res[0].Allowed {
+		t.Errorf("multisig spend rejected: %s", res[0].RejectReason)
+	}
+
+	if _, err := rt.CreateMultisig(4, pubKeys, "bech32"); err == nil {
+		t.Error("nRequired > len(pubKeys) should reject")
+	}
+	if _, err := rt.CreateMultisig(0, pubKeys, ""); err == nil {
+		t.Error("nRequired 0 should reject")
+	}
+}
@@ -1,21 +1,33 @@
 package regtest
 
 import (
+	"bytes"
 	"context"
+	"encoding/binary"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/rpcclient"
+	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 )
 
@@ -404,6 +416,7 @@ func Test_RPCMethods_BeforeStart(t *testing.T) {
 		{"UnloadWallet", func() error { return rt.UnloadWallet("w") }},
 		{"GenerateBech32", func() error { _, err := rt.GenerateBech32("l"); return err }},
 		{"GenerateBech32m", func() error { _, err := rt.GenerateBech32m("l"); return err }},
+		{"GenerateAddresses", func() error { _, err := rt.GenerateAddresses("l", "bech32", 2); return err }},
 		{"ScanTxOutSetForAddress", func() error {
 			_, err := rt.ScanTxOutSetForAddress("bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl")
 			return err
@@ -420,6 +433,82 @@ func Test_RPCMethods_BeforeStart(t *testing.T) {
 		{"GetChainTips", func() error { _, err := rt.GetChainTips(); return err }},
 		{"GetDeploymentInfo", func() error { _, err := rt.GetDeploymentInfo(); return err }},
 		{"DeploymentStatus", func() error { _, err := rt.DeploymentStatus("taproot"); return err }},
+		{"GetDeployment", func() error { _, err := rt.GetDeployment("taproot"); return err }},
+		{"MempoolFeeHistogram", func() error { _, err := rt.MempoolFeeHistogram(); return err }},
+		{"CoinbaseMaturityRemaining", func() error { _, err := rt.CoinbaseMaturityRemaining(wire.OutPoint{}); return err }},
+		{"WarpWithTransactions", func() error {
+			_, err := rt.WarpWithTransactions("bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl", nil)
+			return err
+		}},
+		{"GetAddressesByLabel", func() error { _, err := rt.GetAddressesByLabel("l"); return err }},
+		{"SetLabel", func() error { return rt.SetLabel("bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl", "l") }},
+		{"GetBlockFromPeer", func() error { return rt.GetBlockFromPeer(&chainhash.Hash{}, 0) }},
+		{"GetSoftForks", func() error { _, err := rt.GetSoftForks(); return err }},
+		{"SpendableOutPoints", func() error { _, err := rt.SpendableOutPoints("w", 1); return err }},
+		{"ScanBlocks", func() error { _, err := rt.ScanBlocks([]string{"addr(x)"}, 0, -1); return err }},
+		{"ConfirmStable", func() error {
+			return rt.ConfirmStable(context.Background(), &chainhash.Hash{}, 1, "bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl")
+		}},
+		{"DumpWallet", func() error { _, err := rt.DumpWallet("/tmp/dump"); return err }},
+		{"ImportWallet", func() error { return rt.ImportWallet("/tmp/dump") }},
+		{"MempoolTxFeeRate", func() error { _, err := rt.MempoolTxFeeRate(&chainhash.Hash{}); return err }},
+		{"MempoolTxAncestorFeeRate", func() error { _, err := rt.MempoolTxAncestorFeeRate(&chainhash.Hash{}); return err }},
+		{"ExpectReject", func() error { return rt.ExpectReject(wire.NewMsgTx(2), "bad-txns") }},
+		{"CreateTxChain", func() error { _, err := rt.CreateTxChain(wire.OutPoint{}, 2, 1); return err }},
+		{"GetMedianTimePast", func() error { _, err := rt.GetMedianTimePast(); return err }},
+		{"PrioritiseTransaction", func() error { _, err := rt.PrioritiseTransaction(&chainhash.Hash{}, 1); return err }},
+		{"GetPrioritisedTransactions", func() error { _, err := rt.GetPrioritisedTransactions(); return err }},
+		{"CreateSpendableUTXO", func() error {
+			_, err := rt.CreateSpendableUTXO(1000, "bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl")
+			return err
+		}},
+		{"ListLabels", func() error { _, err := rt.ListLabels(""); return err }},
+		{"BroadcastIdempotent", func() error { _, err := rt.BroadcastIdempotent(wire.NewMsgTx(2)); return err }},
+		{"CheckDeploymentActiveAt", func() error { return rt.CheckDeploymentActiveAt("csv", 10) }},
+		{"WarpDetailed", func() error {
+			_, err := rt.WarpDetailed(1, "bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl")
+			return err
+		}},
+		{"GetNetworkInfo", func() error { _, err := rt.GetNetworkInfo(); return err }},
+		{"GetTxOutSetInfo", func() error { _, err := rt.GetTxOutSetInfo(); return err }},
+		{"UTXOSetsEqual", func() error { _, err := UTXOSetsEqual(rt, rt); return err }},
+		{"ResyncWallet", func() error { return rt.ResyncWallet("") }},
+		{"GetBlockBytes", func() error { _, err := rt.GetBlockBytes(&chainhash.Hash{}); return err }},
+		{"OutputScript", func() error { _, err := rt.OutputScript(wire.OutPoint{}, false); return err }},
+		{"WaitForHeight", func() error { return rt.WaitForHeight(context.Background(), 1, "") }},
+		{"SpendUTXO", func() error {
+			_, err := rt.SpendUTXO(wire.OutPoint{}, "bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl", 1_000, 1)
+			return err
+		}},
+		{"ReplayRPC", func() error { return rt.ReplayRPC(strings.NewReader("")) }},
+		{"WaitForConnection", func() error { return rt.WaitForConnection(context.Background()) }},
+		{"TotalValue", func() error { _, err := rt.TotalValue(); return err }},
+		{"GetSpentOutputs", func() error { _, err := rt.GetSpentOutputs(wire.NewMsgTx(2)); return err }},
+		{"WarpBlocks", func() error {
+			_, err := rt.WarpBlocks(1, "bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl")
+			return err
+		}},
+		{"GetReceivedByLabel", func() error { _, err := rt.GetReceivedByLabel("", 0); return err }},
+		{"SendToAddressWithFeeRate", func() error {
+			_, err := rt.SendToAddressWithFeeRate("bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl", 1000, 5)
+			return err
+		}},
+		{"SendToAllAddressTypes", func() error {
+			_, _, err := rt.SendToAllAddressTypes(1000, "bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl")
+			return err
+		}},
+		{"WarpToWallet", func() error { _, err := rt.WarpToWallet(1, "w"); return err }},
+		{"GetBalance", func() error { _, err := rt.GetBalance(); return err }},
+		{"GetBalances", func() error { _, err := rt.GetBalances(); return err }},
+		{"GetChainWork", func() error { _, err := rt.GetChainWork(); return err }},
+		{"MineToMaturity", func() error { return rt.MineToMaturity("bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl") }},
+		{"CreateStuckTransaction", func() error {
+			_, err := rt.CreateStuckTransaction("bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl", 1000)
+			return err
+		}},
+		{"BumpFee", func() error { _, err := rt.BumpFee(&chainhash.Hash{}, 0); return err }},
+		{"GetBlockVerboseTx", func() error { _, err := rt.GetBlockVerboseTx(&chainhash.Hash{}); return err }},
+		{"WaitForTip", func() error { return rt.WaitForTip(context.Background(), &chainhash.Hash{}) }},
 		{"TestMempoolAccept", func() error {
 			tx := wire.NewMsgTx(2)
 			tx.AddTxIn(&wire.TxIn{})
@@ -433,6 +522,10 @@ func Test_RPCMethods_BeforeStart(t *testing.T) {
 		{"MineToHeight", func() error {
 			return rt.MineToHeight(1, "bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl")
 		}},
+		{"WarpWithCoinbaseData", func() error {
+			_, err := rt.WarpWithCoinbaseData("bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl", []byte("tag"))
+			return err
+		}},
 		{"MineUntilActive", func() error {
 			_, err := rt.MineUntilActive("testdummy", "bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl", 100)
 			return err
@@ -459,6 +552,55 @@ func Test_RPCMethods_BeforeStart(t *testing.T) {
 		{"Disconnect", func() error { return rt.Disconnect(&Regtest{config: DefaultConfig()}) }},
 		{"AddNode", func() error { return rt.AddNode("127.0.0.1:18444") }},
 		{"GetConnectionCount", func() error { _, err := rt.GetConnectionCount(); return err }},
+		{"GetNodeAddresses", func() error { _, err := rt.GetNodeAddresses(0); return err }},
+		{"EnumerateSigners", func() error { _, err := rt.EnumerateSigners(); return err }},
+		{"SweepToScript", func() error { _, err := rt.SweepToScript([]byte{0x51}, 1); return err }},
+		{"GetMempoolInfo", func() error { _, err := rt.GetMempoolInfo(); return err }},
+		{"GetRawMempool", func() error { _, err := rt.GetRawMempool(); return err }},
+		{"GetRawMempoolVerbose", func() error { _, err := rt.GetRawMempoolVerbose(); return err }},
+		{"CreateFundedPSBT", func() error {
+			_, _, _, err := rt.CreateFundedPSBT(map[string]int64{"bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl": 1000}, nil)
+			return err
+		}},
+		{"CombinePSBT", func() error { _, err := rt.CombinePSBT([]string{"cHNidP8="}); return err }},
+		{"JoinPSBTs", func() error { _, err := rt.JoinPSBTs([]string{"cHNidP8=", "cHNidP8="}); return err }},
+		{"AnalyzePSBT", func() error { _, err := rt.AnalyzePSBT("cHNidP8="); return err }},
+		{"IsReplaceableInMempool", func() error { _, err := rt.IsReplaceableInMempool(&chainhash.Hash{}); return err }},
+		{"MempoolMinFee", func() error { _, err := rt.MempoolMinFee(); return err }},
+		{"WouldRelay", func() error { _, _, err := rt.WouldRelay(wire.NewMsgTx(2)); return err }},
+		{"CheckUTXO", func() error { return rt.CheckUTXO(wire.OutPoint{}, 1, true) }},
+		{"CreateMultisig", func() error {
+			_, err := rt.CreateMultisig(1, []string{"0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"}, "")
+			return err
+		}},
+		{"FundMultisig", func() error {
+			_, err := rt.FundMultisig(&CreateMultisigResult{Address: "bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl"}, 1000, "bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl")
+			return err
+		}},
+		{"SignRawTransactionWithKey", func() error {
+			wif, err := btcutil.DecodeWIF("cMahea7zqjxrtgAbB7LSGbcQUr1uX1ojuat9jZodMN87JcbXMTcA")
+			if err != nil {
+				return err
+			}
+			_, _, err = rt.SignRawTransactionWithKey(wire.NewMsgTx(2), []*btcutil.WIF{wif}, nil)
+			return err
+		}},
+		{"SaveMempool", func() error { _, err := rt.SaveMempool(); return err }},
+		{"LoadMempool", func() error { return rt.LoadMempool("mempool.dat") }},
+		{"ListConflictedTransactions", func() error { _, err := rt.ListConflictedTransactions(); return err }},
+		{"PurgeConflicted", func() error { return rt.PurgeConflicted() }},
+		{"SetTxFee", func() error { _, err := rt.SetTxFee(0.0001); return err }},
+		{"MigrateWallet", func() error { _, err := rt.MigrateWallet("legacy"); return err }},
+		{"StressMine", func() error {
+			return rt.StressMine(context.Background(), 1, 1, "bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl")
+		}},
+		{"SetWalletFlag", func() error { _, err := rt.SetWalletFlag("avoid_reuse", true); return err }},
+		{"KeyPoolSize", func() error { _, err := rt.KeyPoolSize(); return err }},
+		{"IsWalletLocked", func() error { _, err := rt.IsWalletLocked(); return err }},
+		{"WaitForTxConfirmedOrReplaced", func() error {
+			_, err := rt.WaitForTxConfirmedOrReplaced(context.Background(), &chainhash.Hash{}, 1, "addr")
+			return err
+		}},
 	}
 	for _, c := range checks {
 		t.Run(c.name, func(t *testing.T) {
@@ -529,6 +671,54 @@ func Test_RestartCycle(t *testing.T) {
 	}
 }
 
+// Test_Restart mines 10 blocks, bounces the node with Restart and checks
+// the chain survived even though ReuseDataDir is off.
+func Test_Restart(t *testing.T) {
+	rt, err := New(&Config{
+		Host:    "127.0.0.1:21270",
+		User:    "user",
+		Pass:    "pass",
+		DataDir: filepath.Join(t.TempDir(), "restart"),
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Stop(); _ = rt.Cleanup() })
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	if err := rt.Warp(10, "bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl"); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	tip, err := rt.GetBestBlockHash()
+	if err != nil {
+		t.Fatalf("GetBestBlockHash: %v", err)
+	}
+
+	if err := rt.Restart(); err != nil {
+		t.Fatalf("Restart: %v", err)
+	}
+	if h, err := rt.GetBlockCount(); err != nil || h != 10 {
+		t.Errorf("height after Restart = %d, %v; want 10", h, err)
+	}
+	if got, err := rt.GetBestBlockHash(); err != nil || !got.IsEqual(tip) {
+		t.Errorf("tip after Restart = %v, %v; want %s", got, err, tip)
+	}
+
+	// On a stopped node Restart just starts it; the plain Stop before it
+	// wiped the datadir.
+	if err := rt.Stop(); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if err := rt.Restart(); err != nil {
+		t.Fatalf("Restart of a stopped node: %v", err)
+	}
+	if h, err := rt.GetBlockCount(); err != nil || h != 0 {
+		t.Errorf("height after Stop and Restart = %d, %v; want 0 (Stop wipes the datadir)", h, err)
+	}
+}
+
 // Test_Context_Cancellation verifies that *Context variants surface context
 // errors when the supplied context is already cancelled.
 func Test_Context_Cancellation(t *testing.T) {
@@ -554,6 +744,27 @@ func Test_Context_Cancellation(t *testing.T) {
 		t.Errorf("expected context.Canceled, got %v", err)
 	}
 
+	// The wallet and transaction helpers surface it too, through their
+	// error wrapping.
+	const addr = "bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl"
+	calls := []struct {
+		name string
+		fn   func() error
+	}{
+		{"SendToAddressContext", func() error { _, err := rt.SendToAddressContext(ctx, addr, 1000); return err }},
+		{"GetTxOutContext", func() error { _, err := rt.GetTxOutContext(ctx, &chainhash.Hash{}, 0, true); return err }},
+		{"ScanTxOutSetForAddressContext", func() error { _, err := rt.ScanTxOutSetForAddressContext(ctx, addr); return err }},
+		{"CreateWalletContext", func() error { _, err := rt.CreateWalletContext(ctx, "ctxcancel"); return err }},
+		{"LoadWalletContext", func() error { _, err := rt.LoadWalletContext(ctx, "ctxcancel"); return err }},
+		{"GenerateBech32Context", func() error { _, err := rt.GenerateBech32Context(ctx, ""); return err }},
+		{"WarpContext", func() error { return rt.WarpContext(ctx, 1, addr) }},
+	}
+	for _, c := range calls {
+		if err := c.fn(); !errors.Is(err, context.Canceled) {
+			t.Errorf("%s: expected context.Canceled, got %v", c.name, err)
+		}
+	}
+
 	// A timeout-bound ctx should also propagate.
 	tctx, tcancel := context.WithTimeout(context.Background(), time.Nanosecond)
 	defer tcancel()
@@ -701,6 +912,126 @@ func Test_VBParams_Render(t *testing.T) {
 	}
 }
 
+// Test_RenderExtraArgs_TypedFields unit-tests the flags rendered for the
+// typed Config fields beyond VBParams / AcceptNonstdTxn (no node spawned).
+// Each field renders after ExtraArgs so the typed value wins when bitcoind
+// sees the same flag twice.
+func Test_RenderExtraArgs_TypedFields(t *testing.T) {
+	cases := []struct {
+		name string
+		cfg  Config
+		want []string
+	}{
+		{
+			name: "external-signer",
+			cfg:  Config{ExternalSigner: "/usr/local/bin/hwi"},
+			want: []string{"-signer=/usr/local/bin/hwi"},
+		},
+		{
+			name: "ua-comment",
+			cfg:  Config{UAComment: "node-a"},
+			want: []string{"-uacomment=node-a"},
+		},
+		{
+			name: "debug-categories",
+			cfg:  Config{DebugCategories: []string{"mempool", "net"}},
+			want: []string{"-debug=mempool", "-debug=net"},
+		},
+		{
+			name: "blocks-only",
+			cfg:  Config{BlocksOnly: true},
+			want: []string{"-blocksonly=1"},
+		},
+		{
+			name: "mempool-limits",
+			cfg: Config{
+				LimitAncestorCount:   5,
+				LimitDescendantCount: 6,
+				LimitAncestorSize:    50,
+				LimitDescendantSize:  60,
+			},
+			want: []string{
+				"-limitancestorcount=5",
+				"-limitdescendantcount=6",
+				"-limitancestorsize=50",
+				"-limitdescendantsize=60",
+			},
+		},
+		{
+			name: "mempool-limits-partial",
+			cfg:  Config{LimitDescendantCount: 3},
+			want: []string{"-limitdescendantcount=3"},
+		},
+		{
+			name: "conf-file",
+			cfg:  Config{ConfFile: "/etc/bitcoin/regtest.conf", BlocksOnly: true},
+			want: []string{"-blocksonly=1", "-conf=/etc/bitcoin/regtest.conf"},
+		},
+		{
+			name: "persist-mempool-v1",
+			cfg:  Config{PersistMempoolV1: true},
+			want: []string{"-persistmempoolv1=1"},
+		},
+		{
+			name: "change-type",
+			cfg:  Config{ChangeType: "legacy"},
+			want: []string{"-changetype=legacy"},
+		},
+		{
+			name: "assume-valid",
+			cfg:  Config{AssumeValid: "0"},
+			want: []string{"-assumevalid=0"},
+		},
+		{
+			name: "max-tx-fee",
+			cfg:  Config{MaxTxFee: 0.5},
+			want: []string{"-maxtxfee=0.50000000"},
+		},
+		{
+			name: "block-min-tx-fee",
+			cfg:  Config{BlockMinTxFee: 0.0001},
+			want: []string{"-blockmintxfee=0.00010000"},
+		},
+		{
+			name: "max-connections",
+			cfg:  Config{MaxConnections: 16},
+			want: []string{"-maxconnections=16"},
+		},
+		{
+			name: "fast-prune",
+			cfg:  Config{FastPrune: true},
+			want: []string{"-fastprune=1"},
+		},
+		{
+			name: "minimum-chain-work",
+			cfg:  Config{MinimumChainWork: "0x100"},
+			want: []string{"-minimumchainwork=0x100"},
+		},
+		{
+			name: "rpc-serial-version-0",
+			cfg:  Config{RPCSerialVersion: new(int)},
+			want: []string{"-rpcserialversion=0", "-deprecatedrpc=serialversion"},
+		},
+		{
+			name: "rpc-serial-version-1",
+			cfg:  Config{RPCSerialVersion: func() *int { v := 1; return &v }()},
+			want: []string{"-rpcserialversion=1"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.cfg.renderExtraArgs()
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+			// clone must carry every typed field through unchanged.
+			if again := tc.cfg.clone().renderExtraArgs(); !slices.Equal(again, tc.want) {
+				t.Errorf("clone().renderExtraArgs() = %v, want %v", again, tc.want)
+			}
+		})
+	}
+}
+
 // Test_New_EmptyVBParamDeployment pins the validation contract that an empty
 // Deployment field is rejected at New time rather than silently producing a
 // malformed -vbparams= flag.
@@ -870,6 +1201,95 @@ func Test_ExtractP2PPort(t *testing.T) {
 	}
 }
 
+// Test_P2PPort pins the effective P2P port and Config.P2PPort validation.
+func Test_P2PPort(t *testing.T) {
+	cases := []struct {
+		cfg  Config
+		want int
+	}{
+		{Config{Host: "127.0.0.1:20000"}, 20001},
+		{Config{Host: "127.0.0.1:20000", P2PPort: 30000}, 30000},
+		{Config{Host: "127.0.0.1"}, 18444},
+	}
+	for _, tc := range cases {
+		rt := &Regtest{config: &tc.cfg}
+		if got := rt.P2PPort(); got != tc.want {
+			t.Errorf("P2PPort() with %+v = %d, want %d", tc.cfg, got, tc.want)
+		}
+		if env := rt.scriptEnv(false); tc.cfg.P2PPort > 0 && !slices.Contains(env, fmt.Sprintf("REGTEST_P2P_PORT=%d", tc.cfg.P2PPort)) {
+			t.Errorf("scriptEnv lacks REGTEST_P2P_PORT for %+v", tc.cfg)
+		}
+	}
+	for _, bad := range []Config{
+		{P2PPort: -1},
+		{P2PPort: 65536},
+		{Host: "127.0.0.1:20000", P2PPort: 20000},
+	} {
+		if _, err := New(&bad); err == nil {
+			t.Errorf("New(%+v) should reject P2PPort", bad)
+		}
+	}
+}
+
+// Test_P2PPort_Override starts a node whose RPC port + 1 is taken on a
+// different P2P port, and checks Connect reaches it there.
+func Test_P2PPort_Override(t *testing.T) {
+	taken, err := net.Listen("tcp", "127.0.0.1:21301")
+	if err != nil {
+		t.Skipf("port 21301 unavailable: %v", err)
+	}
+	defer func() { _ = taken.Close() }()
+
+	rt1, err := New(&Config{
+		Host:    "127.0.0.1:21310",
+		User:    "user",
+		Pass:    "pass",
+		DataDir: filepath.Join(t.TempDir(), "rt1"),
+	})
+	if err != nil {
+		t.Fatalf("New rt1: %v", err)
+	}
+	t.Cleanup(func() { _ = rt1.Stop(); _ = rt1.Cleanup() })
+	rt2, err := New(&Config{
+		Host:    "127.0.0.1:21300",
+		User:    "user",
+		Pass:    "pass",
+		DataDir: filepath.Join(t.TempDir(), "rt2"),
+		P2PPort: 21305,
+	})
+	if err != nil {
+		t.Fatalf("New rt2: %v", err)
+	}
+	t.Cleanup(func() { _ = rt2.Stop(); _ = rt2.Cleanup() })
+	if got := rt2.P2PPort(); got != 21305 {
+		t.Fatalf("P2PPort() = %d, want 21305", got)
+	}
+
+	if err := rt1.Start(); err != nil {
+		t.Fatalf("Start rt1: %v", err)
+	}
+	if err := rt2.Start(); err != nil {
+		t.Fatalf("Start rt2: %v", err)
+	}
+	if err := rt1.Connect(rt2); err != nil {
+		t.Fatalf("rt1.Connect(rt2): %v", err)
+	}
+	deadline := time.Now().Add(15 * time.Second)
+	for {
+		n, err := rt2.GetConnectionCount()
+		if err != nil {
+			t.Fatalf("rt2.GetConnectionCount: %v", err)
+		}
+		if n >= 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("rt1 never connected to rt2's P2PPort")
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+}
+
 // Test_MultiNode_Connect_Sync exercises the full multi-node story: start two
 // regtest nodes, Connect rt1 -> rt2, observe GetConnectionCount go positive
 // on both within a timeout, then Warp on rt1 and confirm rt2's height
@@ -1059,3 +1479,2571 @@ func Test_Config_BinaryPath_Invalid(t *testing.T) {
 		t.Errorf("error %q should mention the bogus path %q", err.Error(), bogus)
 	}
 }
+
+// Test_Config_BinaryPath_Symlink starts a node from a bitcoind symlinked
+// into a directory that is not on PATH, as CI images with several Core
+// versions under /opt lay them out.
+func Test_Config_BinaryPath_Symlink(t *testing.T) {
+	bitcoindPath, err := exec.LookPath("bitcoind")
+	if err != nil {
+		t.Skipf("bitcoind not in PATH: %v", err)
+	}
+	link := filepath.Join(t.TempDir(), "bitcoin-x", "bin", "bitcoind")
+	if err := os.MkdirAll(filepath.Dir(link), 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.Symlink(bitcoindPath, link); err != nil {
+		t.Fatalf("Symlink: %v", err)
+	}
+
+	rt, err := New(&Config{
+		Host:       "127.0.0.1:21280",
+		User:       "user",
+		Pass:       "pass",
+		DataDir:    filepath.Join(t.TempDir(), "regtest"),
+		BinaryPath: link,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Stop(); _ = rt.Cleanup() })
+	if rt.bitcoindPath != link {
+		t.Errorf("rt.bitcoindPath = %q, want %q", rt.bitcoindPath, link)
+	}
+
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if cmd := rt.LastStartCommand(); len(cmd) == 0 || cmd[0] != link {
+		t.Errorf("LastStartCommand()[0] = %v, want %q", cmd, link)
+	}
+}
+
+// Test_Config_BinaryPath_NotExecutable confirms that a BinaryPath naming a
+// file without the execute bit is rejected at New().
+func Test_Config_BinaryPath_NotExecutable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bitcoind")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	_, err := New(&Config{BinaryPath: path})
+	if !errors.Is(err, ErrBitcoindNotFound) {
+		t.Errorf("New with non-executable BinaryPath = %v, want ErrBitcoindNotFound", err)
+	}
+}
+
+// Test_ExternalSigner_Enumerate points -signer at a mock HWI-style script
+// that reports a single device and asserts EnumerateSigners surfaces it.
+// Skips when the bitcoind build lacks external signer support (the -signer
+// flag is then rejected at startup).
+func Test_ExternalSigner_Enumerate(t *testing.T) {
+	dir := t.TempDir()
+	signer := filepath.Join(dir, "mock_signer.sh")
+	script := "#!/bin/sh\n" +
+		"for arg in \"$@\"; do last=\"$arg\"; done\n" +
+		"if [ \"$last\" = enumerate ]; then\n" +
+		"  echo '[{\"fingerprint\":\"00000001\",\"type\":\"trezor\",\"model\":\"trezor_t\"}]'\n" +
+		"fi\n"
+	if err := os.WriteFile(signer, []byte(script), 0700); err != nil {
+		t.Fatalf("write mock signer: %v", err)
+	}
+
+	rt, err := New(&Config{
+		Host:           "127.0.0.1:21000",
+		User:           "user",
+		Pass:           "pass",
+		DataDir:        filepath.Join(dir, "regtest"),
+		ExternalSigner: signer,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Stop(); _ = rt.Cleanup() })
+
+	if err := rt.Start(); err != nil {
+		if strings.Contains(err.Error(), "-signer") {
+			t.Skipf("bitcoind built without external signer support: %v", err)
+		}
+		t.Fatalf("Start: %v", err)
+	}
+
+	signers, err := rt.EnumerateSigners()
+	if err != nil {
+		t.Fatalf("EnumerateSigners: %v", err)
+	}
+	if len(signers) != 1 {
+		t.Fatalf("expected 1 signer, got %d: %+v", len(signers), signers)
+	}
+	if signers[0].Fingerprint != "00000001" {
+		t.Errorf("Fingerprint = %q, want 00000001", signers[0].Fingerprint)
+	}
+	if signers[0].Name != "trezor_t" {
+		t.Errorf("Name = %q, want trezor_t", signers[0].Name)
+	}
+}
+
+// Test_HeightCache_Bookkeeping pins the Config.CacheHeight state machine
+// without a node: unprimed caches don't serve, Warp-style advances only
+// apply to a primed cache, and peering disables the cache for good.
+func Test_HeightCache_Bookkeeping(t *testing.T) {
+	rt := &Regtest{config: &Config{CacheHeight: true}}
+
+	if _, ok := rt.cachedHeight(); ok {
+		t.Fatal("unprimed cache should not serve")
+	}
+	rt.beginMining()
+	rt.endMining(5)
+	if _, ok := rt.cachedHeight(); ok {
+		t.Fatal("advance on an unprimed cache should not prime it")
+	}
+
+	rt.storeHeight(10, rt.heightGeneration())
+	rt.beginMining()
+	rt.endMining(3)
+	if h, ok := rt.cachedHeight(); !ok || h != 13 {
+		t.Fatalf("cachedHeight = (%d, %v), want (13, true)", h, ok)
+	}
+
+	// A reading taken before a concurrent Warp must not overwrite it.
+	gen := rt.heightGeneration()
+	rt.beginMining()
+	rt.endMining(1)
+	rt.storeHeight(13, gen)
+	if h, ok := rt.cachedHeight(); !ok || h != 14 {
+		t.Fatalf("stale store: cachedHeight = (%d, %v), want (14, true)", h, ok)
+	}
+
+	// A reading taken while mining is in flight may already include the
+	// new blocks; storing it and then advancing would count them twice.
+	rt.InvalidateHeightCache()
+	rt.beginMining()
+	rt.storeHeight(16, rt.heightGeneration())
+	if _, ok := rt.cachedHeight(); ok {
+		t.Fatal("a reading taken mid-mining must not prime the cache")
+	}
+	rt.endMining(2)
+	rt.storeHeight(16, rt.heightGeneration())
+	rt.beginMining()
+	rt.abortMining()
+	if _, ok := rt.cachedHeight(); ok {
+		t.Fatal("abortMining should drop the cached height")
+	}
+
+	gen = rt.heightGeneration()
+	rt.InvalidateHeightCache()
+	if _, ok := rt.cachedHeight(); ok {
+		t.Fatal("InvalidateHeightCache should drop the cached height")
+	}
+	rt.storeHeight(14, gen)
+	if _, ok := rt.cachedHeight(); ok {
+		t.Fatal("a reading from before InvalidateHeightCache must not prime the cache")
+	}
+
+	rt.storeHeight(20, rt.heightGeneration())
+	rt.markPeered()
+	rt.storeHeight(21, rt.heightGeneration())
+	if _, ok := rt.cachedHeight(); ok {
+		t.Fatal("a peered node must never serve from cache")
+	}
+
+	rt.resetHeightCache()
+	rt.storeHeight(0, rt.heightGeneration())
+	if h, ok := rt.cachedHeight(); !ok || h != 0 {
+		t.Fatalf("after reset: cachedHeight = (%d, %v), want (0, true)", h, ok)
+	}
+
+	off := &Regtest{config: &Config{}}
+	off.storeHeight(7, off.heightGeneration())
+	if _, ok := off.cachedHeight(); ok {
+		t.Fatal("cache must stay off when Config.CacheHeight is false")
+	}
+}
+
+// Test_HeightCache_ConcurrentWarp reads the height from an unprimed cache
+// while Warps are in flight and checks the cache ends up matching bitcoind
+// rather than double-counting blocks a racing getblockcount already saw.
+func Test_HeightCache_ConcurrentWarp(t *testing.T) {
+	rt, err := New(&Config{
+		Host:        "127.0.0.1:21360",
+		User:        "user",
+		Pass:        "pass",
+		DataDir:     filepath.Join(t.TempDir(), "regtest"),
+		CacheHeight: true,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Stop(); _ = rt.Cleanup() })
+
+	const miner = "bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl"
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			rt.InvalidateHeightCache()
+			_, _ = rt.GetBlockCount()
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		if err := rt.Warp(5, miner); err != nil {
+			close(done)
+			wg.Wait()
+			t.Fatalf("Warp: %v", err)
+		}
+	}
+	close(done)
+	wg.Wait()
+
+	cached, err := rt.GetBlockCount()
+	if err != nil {
+		t.Fatalf("GetBlockCount: %v", err)
+	}
+	raw, err := rt.rawRPC(context.Background(), "getblockcount")
+	if err != nil {
+		t.Fatalf("getblockcount: %v", err)
+	}
+	var fresh int64
+	if err := json.Unmarshal(raw, &fresh); err != nil {
+		t.Fatalf("unmarshal getblockcount: %v", err)
+	}
+	if cached != fresh || fresh != 100 {
+		t.Errorf("cached height = %d, getblockcount = %d, want both 100", cached, fresh)
+	}
+}
+
+// Test_LocalNodeAt checks AddNode's lookup of in-process instances by
+// loopback P2P address.
+func Test_LocalNodeAt(t *testing.T) {
+	rt := &Regtest{config: &Config{Host: "127.0.0.1:21998"}}
+	registerLocalNode(rt)
+	t.Cleanup(func() { unregisterLocalNode(rt) })
+
+	for _, host := range []string{"127.0.0.1:21999", "localhost:21999", "[::1]:21999"} {
+		if got := localNodeAt(host); got != rt {
+			t.Errorf("localNodeAt(%q) = %p, want %p", host, got, rt)
+		}
+	}
+	for _, host := range []string{"10.0.0.1:21999", "127.0.0.1:21998", "127.0.0.1"} {
+		if got := localNodeAt(host); got != nil {
+			t.Errorf("localNodeAt(%q) = %p, want nil", host, got)
+		}
+	}
+	unregisterLocalNode(rt)
+	if got := localNodeAt("127.0.0.1:21999"); got != nil {
+		t.Errorf("after unregister: localNodeAt = %p, want nil", got)
+	}
+}
+
+// Test_HeightCache_AddNodePeersBothEnds has A AddNode B by address and
+// checks B, with CacheHeight on, sees the blocks A mines rather than its
+// cached height.
+func Test_HeightCache_AddNodePeersBothEnds(t *testing.T) {
+	newNode := func(name, host string) *Regtest {
+		rt, err := New(&Config{
+			Host:        host,
+			User:        "user",
+			Pass:        "pass",
+			DataDir:     filepath.Join(t.TempDir(), name),
+			CacheHeight: true,
+		})
+		if err != nil {
+			t.Fatalf("New %s: %v", name, err)
+		}
+		t.Cleanup(func() { _ = rt.Stop(); _ = rt.Cleanup() })
+		if err := rt.Start(); err != nil {
+			t.Fatalf("Start %s: %v", name, err)
+		}
+		return rt
+	}
+	a := newNode("a", "127.0.0.1:21340")
+	b := newNode("b", "127.0.0.1:21350")
+
+	// Prime B's cache before it has any peers.
+	start, err := b.GetBlockCount()
+	if err != nil {
+		t.Fatalf("b.GetBlockCount: %v", err)
+	}
+	if err := a.AddNode(fmt.Sprintf("127.0.0.1:%d", b.P2PPort())); err != nil {
+		t.Fatalf("AddNode: %v", err)
+	}
+	deadline := time.Now().Add(15 * time.Second)
+	for {
+		n, err := b.GetConnectionCount()
+		if err != nil {
+			t.Fatalf("b.GetConnectionCount: %v", err)
+		}
+		if n >= 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("nodes never connected")
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+
+	if err := a.Warp(3, "bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl"); err != nil {
+		t.Fatalf("a.Warp: %v", err)
+	}
+	deadline = time.Now().Add(15 * time.Second)
+	for {
+		got, err := b.GetBlockCount()
+		if err != nil {
+			t.Fatalf("b.GetBlockCount: %v", err)
+		}
+		if got == start+3 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("b.GetBlockCount stuck at %d, want %d", got, start+3)
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+}
+
+// Test_HeightCache_WarpAndInvalidate runs a node with CacheHeight on and
+// checks that Warp keeps the cache in step with bitcoind, that blocks mined
+// behind the library's back are invisible until InvalidateHeightCache, and
+// that the next read after invalidation matches the real tip.
+func Test_HeightCache_WarpAndInvalidate(t *testing.T) {
+	rt, err := New(&Config{
+		Host:        "127.0.0.1:21010",
+		User:        "user",
+		Pass:        "pass",
+		DataDir:     filepath.Join(t.TempDir(), "regtest"),
+		CacheHeight: true,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Stop(); _ = rt.Cleanup() })
+
+	if err := rt.EnsureWallet("cache"); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	addr, err := rt.GenerateBech32("cache")
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+
+	start, err := rt.GetBlockCount()
+	if err != nil {
+		t.Fatalf("GetBlockCount: %v", err)
+	}
+	if err := rt.Warp(5, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	got, err := rt.GetBlockCount()
+	if err != nil {
+		t.Fatalf("GetBlockCount after Warp: %v", err)
+	}
+	if got != start+5 {
+		t.Errorf("cached height = %d, want %d", got, start+5)
+	}
+
+	// Mine one block behind the library's back: the cache can't see it.
+	if _, err := rt.rawRPC(context.Background(), "generatetoaddress", 1, addr); err != nil {
+		t.Fatalf("generatetoaddress: %v", err)
+	}
+	if got, _ := rt.GetBlockCount(); got != start+5 {
+		t.Errorf("expected stale cached height %d, got %d", start+5, got)
+	}
+
+	rt.InvalidateHeightCache()
+	if got, err := rt.GetBlockCount(); err != nil {
+		t.Fatalf("GetBlockCount after invalidate: %v", err)
+	} else if got != start+6 {
+		t.Errorf("height after invalidate = %d, want %d", got, start+6)
+	}
+}
+
+// Test_MempoolEntry_Decode pins the getrawmempool true JSON mapping,
+// including the BTC → satoshi conversion of the nested "fees" object.
+func Test_MempoolEntry_Decode(t *testing.T) {
+	const raw = `{
+		"vsize": 141, "weight": 561, "time": 1700000000, "height": 101,
+		"descendantcount": 2, "descendantsize": 282,
+		"ancestorcount": 1, "ancestorsize": 141,
+		"depends": [], "spentby": ["bb"], "bip125-replaceable": true,
+		"fees": {"base": 0.00000282, "modified": 0.00001282,
+		         "ancestor": 0.00001282, "descendant": 0.00001564}
+	}`
+	var e rawMempoolEntry
+	if err := json.Unmarshal([]byte(raw), &e); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	got, err := e.toEntry()
+	if err != nil {
+		t.Fatalf("toEntry: %v", err)
+	}
+	if got.VSize != 141 || got.Weight != 561 || got.Height != 101 {
+		t.Errorf("size/height fields = %+v", got)
+	}
+	if got.Fee != 282 || got.ModifiedFee != 1282 || got.AncestorFee != 1282 || got.DescendantFee != 1564 {
+		t.Errorf("fees = %v/%v/%v/%v, want 282/1282/1282/1564",
+			got.Fee, got.ModifiedFee, got.AncestorFee, got.DescendantFee)
+	}
+	if got.AncestorCount != 1 || got.DescendantCount != 2 || got.DescendantSize != 282 {
+		t.Errorf("package stats = %+v", got)
+	}
+	if len(got.SpentBy) != 1 || got.SpentBy[0] != "bb" || !got.BIP125Replaceable {
+		t.Errorf("spentby/bip125 = %v/%v", got.SpentBy, got.BIP125Replaceable)
+	}
+}
+
+// Test_UAComment_Subversion starts a node with Config.UAComment and checks
+// the comment shows up in getnetworkinfo's subversion without confusing
+// Variant detection.
+func Test_UAComment_Subversion(t *testing.T) {
+	rt, err := New(&Config{
+		Host:      "127.0.0.1:21020",
+		User:      "user",
+		Pass:      "pass",
+		DataDir:   filepath.Join(t.TempDir(), "regtest"),
+		UAComment: "node-a",
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Stop(); _ = rt.Cleanup() })
+
+	resp, err := rt.rawRPC(context.Background(), "getnetworkinfo")
+	if err != nil {
+		t.Fatalf("getnetworkinfo: %v", err)
+	}
+	var info struct {
+		Subversion string `json:"subversion"`
+	}
+	if err := json.Unmarshal(resp, &info); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !strings.Contains(info.Subversion, "(node-a)") {
+		t.Errorf("subversion = %q, want it to contain (node-a)", info.Subversion)
+	}
+
+	v, err := rt.Variant()
+	if err != nil {
+		t.Fatalf("Variant: %v", err)
+	}
+	if v == VariantUnknown {
+		t.Errorf("Variant = %v with UAComment set, want Core or Inquisition", v)
+	}
+}
+
+// Test_ComputeTxID checks ComputeTxID against the mainnet genesis coinbase
+// and confirms witness data does not affect the result.
+func Test_ComputeTxID(t *testing.T) {
+	genesisCoinbase := chaincfg.MainNetParams.GenesisBlock.Transactions[0]
+	const want = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+	if got := ComputeTxID(genesisCoinbase); got.String() != want {
+		t.Errorf("genesis coinbase txid = %s, want %s", got, want)
+	}
+
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{1}, 0), nil, nil))
+	tx.AddTxOut(wire.NewTxOut(1000, []byte{0x51}))
+	before := *ComputeTxID(tx)
+	tx.TxIn[0].Witness = wire.TxWitness{bytes.Repeat([]byte{0xab}, 72)}
+	if after := *ComputeTxID(tx); after != before {
+		t.Errorf("txid changed after adding witness: %s -> %s", before, after)
+	}
+}
+
+// Test_VirtualSize pins Weight and VirtualSize for a legacy and a segwit
+// transaction, where witness bytes count a quarter towards vsize.
+func Test_VirtualSize(t *testing.T) {
+	genesisCoinbase := chaincfg.MainNetParams.GenesisBlock.Transactions[0]
+	if w, vs := Weight(genesisCoinbase), VirtualSize(genesisCoinbase); w != 816 || vs != 204 {
+		t.Errorf("genesis coinbase weight, vsize = %d, %d; want 816, 204", w, vs)
+	}
+
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{1}, 0), nil, nil))
+	tx.AddTxOut(wire.NewTxOut(1000, []byte{0x51}))
+	if w, vs := Weight(tx), VirtualSize(tx); w != 244 || vs != 61 {
+		t.Errorf("no-witness weight, vsize = %d, %d; want 244, 61", w, vs)
+	}
+	// Marker, flag, item count, length prefix and 72 bytes: 76 witness
+	// bytes, so weight 61*3 + 137 = 320 and vsize 80.
+	tx.TxIn[0].Witness = wire.TxWitness{bytes.Repeat([]byte{0xab}, 72)}
+	if w, vs := Weight(tx), VirtualSize(tx); w != 320 || vs != 80 {
+		t.Errorf("witness weight, vsize = %d, %d; want 320, 80", w, vs)
+	}
+	// One more witness byte rounds vsize up.
+	tx.TxIn[0].Witness[0] = append(tx.TxIn[0].Witness[0], 0xab)
+	if w, vs := Weight(tx), VirtualSize(tx); w != 321 || vs != 81 {
+		t.Errorf("witness+1 weight, vsize = %d, %d; want 321, 81", w, vs)
+	}
+}
+
+// Test_InputsFromOutPoints checks the outpoint to TransactionInput
+// conversion, including hash byte order.
+func Test_InputsFromOutPoints(t *testing.T) {
+	genesis := chaincfg.RegressionNetParams.GenesisHash
+	ops := []wire.OutPoint{{Hash: *genesis, Index: 3}, {Hash: chainhash.Hash{1}, Index: 0}}
+	got := InputsFromOutPoints(ops)
+	want := []btcjson.TransactionInput{
+		{Txid: "0f9188f13cb7b2c71f2a335e3a4fc328bf5beb436012afca590b1a11466e2206", Vout: 3},
+		{Txid: chainhash.Hash{1}.String(), Vout: 0},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("InputsFromOutPoints = %v, want %v", got, want)
+	}
+	if got := InputsFromOutPoints(nil); got == nil || len(got) != 0 {
+		t.Errorf("InputsFromOutPoints(nil) = %#v, want empty non-nil", got)
+	}
+}
+
+func Test_ValueConserved(t *testing.T) {
+	const coin = 100_000_000
+	for _, tc := range []struct {
+		from, to int64
+		want     int64
+	}{
+		{0, 0, 0},
+		{5, 3, 0},
+		{0, 1, 50 * coin},
+		{0, 101, 101 * 50 * coin},
+		{148, 150, 50*coin + 25*coin},
+	} {
+		if got := BlockSubsidies(tc.from, tc.to); got != tc.want {
+			t.Errorf("BlockSubsidies(%d, %d) = %d, want %d", tc.from, tc.to, got, tc.want)
+		}
+	}
+
+	// Regtest's subsidy runs out after 33 halvings (height 4950).
+	if a, b := BlockSubsidies(0, 4950), BlockSubsidies(0, 100_000); a != b {
+		t.Errorf("BlockSubsidies past the last halving grew from %d to %d", a, b)
+	}
+
+	if err := CheckValueConserved(1_000, 6_000, 5_000); err != nil {
+		t.Errorf("CheckValueConserved: %v", err)
+	}
+	err := CheckValueConserved(1_000, 5_900, 5_000)
+	if err == nil || !strings.Contains(err.Error(), "off by -100") {
+		t.Errorf("CheckValueConserved shortfall = %v, want off by -100", err)
+	}
+}
+
+// Test_UnknownDebugCategories checks the warn-only category filter.
+func Test_UnknownDebugCategories(t *testing.T) {
+	got := unknownDebugCategories([]string{"mempool", "bogus", "net", "validaton", "all"})
+	want := []string{"bogus", "validaton"}
+	if !slices.Equal(got, want) {
+		t.Errorf("unknownDebugCategories = %v, want %v", got, want)
+	}
+	if got := unknownDebugCategories(nil); got != nil {
+		t.Errorf("unknownDebugCategories(nil) = %v, want nil", got)
+	}
+}
+
+// Test_DebugCategories_UnknownDoesNotFail confirms an unknown category is a
+// warning, not a New error, and that Config() hands back a copy.
+func Test_DebugCategories_UnknownDoesNotFail(t *testing.T) {
+	cats := []string{"mempool", "not-a-category"}
+	rt, err := New(&Config{
+		Host:            "127.0.0.1:21030",
+		User:            "user",
+		Pass:            "pass",
+		DataDir:         filepath.Join(t.TempDir(), "regtest"),
+		DebugCategories: cats,
+	})
+	if err != nil {
+		t.Fatalf("New with unknown category should succeed, got %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Cleanup() })
+
+	cats[0] = "mutated"
+	if got := rt.Config().DebugCategories; got[0] != "mempool" {
+		t.Errorf("DebugCategories aliased caller slice: %v", got)
+	}
+}
+
+// Test_CoinbaseScriptSig pins the BIP34 prefix and the 100-byte limit.
+func Test_CoinbaseScriptSig(t *testing.T) {
+	got, err := coinbaseScriptSig(5, nil)
+	if err != nil {
+		t.Fatalf("coinbaseScriptSig(5, nil): %v", err)
+	}
+	// OP_5 then OP_0 for the empty push: still meets the 2-byte minimum.
+	if want := []byte{0x55, 0x00}; !bytes.Equal(got, want) {
+		t.Errorf("coinbaseScriptSig(5, nil) = %x, want %x", got, want)
+	}
+
+	// Height 200 pushes 3 bytes; a 95-byte payload needs OP_PUSHDATA1.
+	if got, err := coinbaseScriptSig(200, make([]byte, 95)); err != nil {
+		t.Errorf("95 bytes at height 200 should fit: %v", err)
+	} else if len(got) != maxCoinbaseScriptSig {
+		t.Errorf("len = %d, want %d", len(got), maxCoinbaseScriptSig)
+	}
+	if _, err := coinbaseScriptSig(200, make([]byte, 96)); err == nil {
+		t.Error("96 bytes at height 200 should exceed the limit")
+	}
+}
+
+// Test_String_Disconnected checks String is safe and RPC-free before Start.
+func Test_String_Disconnected(t *testing.T) {
+	rt := &Regtest{config: &Config{Host: "127.0.0.1:21040", DataDir: "/tmp/x"}}
+	want := "regtest{host=127.0.0.1:21040 datadir=/tmp/x state=disconnected}"
+	if got := rt.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+// Test_BlocksOnly_LocalRelay starts a node with Config.BlocksOnly and checks
+// bitcoind reports transaction relay off (getnetworkinfo.localrelay).
+func Test_BlocksOnly_LocalRelay(t *testing.T) {
+	rt, err := New(&Config{
+		Host:       "127.0.0.1:21050",
+		User:       "user",
+		Pass:       "pass",
+		DataDir:    filepath.Join(t.TempDir(), "regtest"),
+		BlocksOnly: true,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Stop(); _ = rt.Cleanup() })
+
+	resp, err := rt.rawRPC(context.Background(), "getnetworkinfo")
+	if err != nil {
+		t.Fatalf("getnetworkinfo: %v", err)
+	}
+	var info struct {
+		LocalRelay bool `json:"localrelay"`
+	}
+	if err := json.Unmarshal(resp, &info); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if info.LocalRelay {
+		t.Error("localrelay = true, want false under -blocksonly")
+	}
+}
+
+// Test_SyncMockTime_Validation covers the pre-RPC checks: nothing is sent
+// unless every node is non-nil and connected.
+func Test_SyncMockTime_Validation(t *testing.T) {
+	disconnected := &Regtest{config: DefaultConfig()}
+	cases := []struct {
+		name  string
+		nodes []*Regtest
+		unix  int64
+	}{
+		{"empty", nil, 1_700_000_000},
+		{"nil-entry", []*Regtest{nil}, 1_700_000_000},
+		{"zero-time", []*Regtest{disconnected}, 0},
+		{"beyond-cap", []*Regtest{disconnected}, maxMockTime + 1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := SyncMockTime(tc.nodes, tc.unix); err == nil {
+				t.Error("expected validation error")
+			}
+		})
+	}
+	if err := SyncMockTime([]*Regtest{disconnected}, 1_700_000_000); !errors.Is(err, errNotConnected) {
+		t.Errorf("disconnected node: err = %v, want errNotConnected", err)
+	}
+	if err := SkewMockTime(nil, time.Hour); err == nil {
+		t.Error("SkewMockTime(nil) should reject")
+	}
+}
+
+// Test_SkewMockTime_RejectsFutureBlock syncs two nodes, skews one three
+// hours ahead, and checks the other rejects a block it mines as
+// time-too-new.
+func Test_SkewMockTime_RejectsFutureBlock(t *testing.T) {
+	newNode := func(port int) *Regtest {
+		rt, err := New(&Config{
+			Host:    fmt.Sprintf("127.0.0.1:%d", port),
+			User:    "user",
+			Pass:    "pass",
+			DataDir: filepath.Join(t.TempDir(), "regtest"),
+		})
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		if err := rt.Start(); err != nil {
+			t.Fatalf("Start: %v", err)
+		}
+		t.Cleanup(func() { _ = rt.Stop(); _ = rt.Cleanup() })
+		return rt
+	}
+	a, b := newNode(21060), newNode(21070)
+
+	base := time.Now().Unix()
+	if err := SyncMockTime([]*Regtest{a, b}, base); err != nil {
+		t.Fatalf("SyncMockTime: %v", err)
+	}
+	if err := SkewMockTime(b, 3*time.Hour); err != nil {
+		t.Fatalf("SkewMockTime: %v", err)
+	}
+
+	if err := b.EnsureWallet("skew"); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	addr, err := b.GenerateBech32("skew")
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := b.Warp(1, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	hash, err := b.GetBestBlockHash()
+	if err != nil {
+		t.Fatalf("GetBestBlockHash: %v", err)
+	}
+	block, err := b.GetBlock(hash)
+	if err != nil {
+		t.Fatalf("GetBlock: %v", err)
+	}
+	if got, want := block.Header.Timestamp.Unix(), base+3*3600; got < want {
+		t.Errorf("skewed block time = %d, want >= %d", got, want)
+	}
+
+	err = a.SubmitBlock(block)
+	if err == nil || !strings.Contains(err.Error(), "time-too-new") {
+		t.Errorf("SubmitBlock on unskewed node: err = %v, want time-too-new", err)
+	}
+}
+
+// Test_IsReplaceable pins the BIP125 nSequence threshold.
+func Test_IsReplaceable(t *testing.T) {
+	mk := func(seqs ...uint32) *wire.MsgTx {
+		tx := wire.NewMsgTx(2)
+		for _, s := range seqs {
+			in := wire.NewTxIn(&wire.OutPoint{}, nil, nil)
+			in.Sequence = s
+			tx.AddTxIn(in)
+		}
+		return tx
+	}
+	cases := []struct {
+		name string
+		tx   *wire.MsgTx
+		want bool
+	}{
+		{"final", mk(0xffffffff), false},
+		{"locktime-enabled-only", mk(0xfffffffe), false},
+		{"signaling", mk(0xfffffffd), true},
+		{"zero", mk(0), true},
+		{"one-of-many", mk(0xffffffff, 0xfffffffe, 0xfffffffd), true},
+		{"no-inputs", mk(), false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := IsReplaceable(tc.tx); got != tc.want {
+				t.Errorf("IsReplaceable = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// Test_New_NegativeMempoolLimits pins that negative Limit* values fail at
+// New rather than producing a flag bitcoind rejects at Start.
+func Test_New_NegativeMempoolLimits(t *testing.T) {
+	cases := []struct {
+		name string
+		cfg  Config
+	}{
+		{"ancestor-count", Config{LimitAncestorCount: -1}},
+		{"descendant-count", Config{LimitDescendantCount: -1}},
+		{"ancestor-size", Config{LimitAncestorSize: -1}},
+		{"descendant-size", Config{LimitDescendantSize: -1}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := New(&tc.cfg); err == nil {
+				t.Error("expected validation error, got nil")
+			}
+		})
+	}
+}
+
+// Test_LimitAncestorCount_ChainRejected lowers -limitancestorcount to 2 and
+// checks a third chained wallet spend is refused, where the default limit
+// of 25 would allow it.
+func Test_LimitAncestorCount_ChainRejected(t *testing.T) {
+	rt, err := New(&Config{
+		Host:               "127.0.0.1:21080",
+		User:               "user",
+		Pass:               "pass",
+		DataDir:            filepath.Join(t.TempDir(), "regtest"),
+		LimitAncestorCount: 2,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Stop(); _ = rt.Cleanup() })
+
+	if err := rt.EnsureWallet("limits"); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	addr, err := rt.GenerateBech32("limits")
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	// One mature coinbase: every send has to chain off the previous change.
+	if err := rt.Warp(101, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := rt.SendToAddress(addr, 1_000_000); err != nil {
+			t.Fatalf("SendToAddress %d: %v", i, err)
+		}
+	}
+	if _, err := rt.SendToAddress(addr, 1_000_000); err == nil {
+		t.Error("third chained send should exceed -limitancestorcount=2")
+	}
+}
+
+// Test_FundOptions_JSON pins the walletcreatefundedpsbt option keys and that
+// zero values are omitted so the wallet defaults apply.
+func Test_FundOptions_JSON(t *testing.T) {
+	b, err := json.Marshal(FundOptions{})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if string(b) != "{}" {
+		t.Errorf("zero FundOptions = %s, want {}", b)
+	}
+
+	pos, rbf := 1, false
+	b, err = json.Marshal(FundOptions{
+		ChangePosition:         &pos,
+		FeeRate:                2.5,
+		SubtractFeeFromOutputs: []int{0},
+		Replaceable:            &rbf,
+	})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	want := `{"changePosition":1,"fee_rate":2.5,"subtractFeeFromOutputs":[0],"replaceable":false}`
+	if string(b) != want {
+		t.Errorf("FundOptions = %s, want %s", b, want)
+	}
+}
+
+// Test_DecodePSBTAnalysis checks the analyzepsbt decoding, including the
+// BTC → sat and BTC/kvB → sat/vB conversions.
+func Test_DecodePSBTAnalysis(t *testing.T) {
+	const raw = `{
+		"inputs": [{
+			"has_utxo": true, "is_final": false, "next": "signer",
+			"missing": {"signatures": ["aa"], "redeemscript": "bb"}
+		}],
+		"estimated_vsize": 141, "estimated_feerate": 0.00005000,
+		"fee": 0.00000705, "next": "signer"
+	}`
+	got, err := decodePSBTAnalysis([]byte(raw))
+	if err != nil {
+		t.Fatalf("decodePSBTAnalysis: %v", err)
+	}
+	if got.Fee != 705 || got.EstimatedFeeRate != 5 || got.EstimatedVSize != 141 {
+		t.Errorf("fee/rate/vsize = %v/%v/%v, want 705/5/141", got.Fee, got.EstimatedFeeRate, got.EstimatedVSize)
+	}
+	if got.Next != "signer" || len(got.Inputs) != 1 {
+		t.Fatalf("next/inputs = %q/%d", got.Next, len(got.Inputs))
+	}
+	in := got.Inputs[0]
+	if !in.HasUTXO || in.IsFinal || in.Next != "signer" {
+		t.Errorf("input = %+v", in)
+	}
+	if len(in.Missing.Signatures) != 1 || in.Missing.Signatures[0] != "aa" || in.Missing.RedeemScript != "bb" {
+		t.Errorf("missing = %+v", in.Missing)
+	}
+}
+
+// Test_New_ConfFileValidation checks New rejects a missing or directory
+// ConfFile and resolves a relative one to an absolute path.
+func Test_New_ConfFileValidation(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := New(&Config{ConfFile: filepath.Join(dir, "missing.conf")}); err == nil {
+		t.Error("missing ConfFile should be rejected")
+	}
+	if _, err := New(&Config{ConfFile: dir}); err == nil {
+		t.Error("directory ConfFile should be rejected")
+	}
+
+	conf := filepath.Join(dir, "bitcoin.conf")
+	if err := os.WriteFile(conf, []byte("uacomment=x\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd: %v", err)
+	}
+	rel, err := filepath.Rel(wd, conf)
+	if err != nil {
+		t.Skipf("no relative path to temp dir: %v", err)
+	}
+	rt, err := New(&Config{ConfFile: rel})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if got := rt.Config().ConfFile; !filepath.IsAbs(got) || filepath.Base(got) != "bitcoin.conf" {
+		t.Errorf("ConfFile = %q, want absolute path to bitcoin.conf", got)
+	}
+}
+
+// Test_ConfFile_LibraryFlagsWin starts a node with a ConfFile that sets a
+// wrong RPC password and a user-agent comment: the comment must apply, and
+// the library's -rpcpassword must override the file's so Start connects.
+func Test_ConfFile_LibraryFlagsWin(t *testing.T) {
+	conf := filepath.Join(t.TempDir(), "bitcoin.conf")
+	body := "uacomment=fromconf\n[regtest]\nrpcpassword=wrong\n"
+	if err := os.WriteFile(conf, []byte(body), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	rt, err := New(&Config{
+		Host:     "127.0.0.1:21090",
+		User:     "user",
+		Pass:     "pass",
+		DataDir:  filepath.Join(t.TempDir(), "regtest"),
+		ConfFile: conf,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	resp, err := rt.rawRPC(context.Background(), "getnetworkinfo")
+	if err != nil {
+		t.Fatalf("getnetworkinfo: %v", err)
+	}
+	var info struct {
+		Subversion string `json:"subversion"`
+	}
+	if err := json.Unmarshal(resp, &info); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !strings.Contains(info.Subversion, "(fromconf)") {
+		t.Errorf("subversion = %q, want it to contain (fromconf)", info.Subversion)
+	}
+}
+
+// Test_FormatVersion pins the getnetworkinfo version decoding used in
+// ErrUnsupportedVersion messages.
+func Test_FormatVersion(t *testing.T) {
+	cases := map[int]string{
+		240000: "24.0.0",
+		270100: "27.1.0",
+		290201: "29.2.1",
+	}
+	for v, want := range cases {
+		if got := formatVersion(v); got != want {
+			t.Errorf("formatVersion(%d) = %q, want %q", v, got, want)
+		}
+	}
+}
+
+// Test_ScriptForAddress checks the scriptPubKey round-trips to the same
+// address and that non-regtest addresses are rejected.
+func Test_ScriptForAddress(t *testing.T) {
+	const addr = "bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl"
+	script, err := ScriptForAddress(addr)
+	if err != nil {
+		t.Fatalf("ScriptForAddress: %v", err)
+	}
+	if len(script) != 22 || script[0] != txscript.OP_0 || script[1] != 0x14 {
+		t.Fatalf("script = %x, want a P2WPKH script", script)
+	}
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(script, &chaincfg.RegressionNetParams)
+	if err != nil {
+		t.Fatalf("ExtractPkScriptAddrs: %v", err)
+	}
+	if len(addrs) != 1 || addrs[0].EncodeAddress() != addr {
+		t.Errorf("script decodes to %v, want %s", addrs, addr)
+	}
+
+	for _, bad := range []string{"", "not-an-address", "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq"} {
+		if _, err := ScriptForAddress(bad); err == nil {
+			t.Errorf("ScriptForAddress(%q) should fail", bad)
+		}
+	}
+}
+
+// Test_PersistMempoolV1_SaveFormat starts a node with PersistMempoolV1 and
+// checks SaveMempool writes the v1 format (leading little-endian version 1).
+func Test_PersistMempoolV1_SaveFormat(t *testing.T) {
+	rt, err := New(&Config{
+		Host:             "127.0.0.1:21100",
+		User:             "user",
+		Pass:             "pass",
+		DataDir:          filepath.Join(t.TempDir(), "regtest"),
+		PersistMempoolV1: true,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	err = rt.Start()
+	if errors.Is(err, ErrUnsupportedVersion) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	path, err := rt.SaveMempool()
+	if err != nil {
+		t.Fatalf("SaveMempool: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if len(data) < 8 {
+		t.Fatalf("mempool.dat is %d bytes, want at least a version header", len(data))
+	}
+	if v := binary.LittleEndian.Uint64(data[:8]); v != 1 {
+		t.Errorf("mempool.dat version = %d, want 1", v)
+	}
+}
+
+// Test_LastStartCommand_FailedStart points BinaryPath at a bitcoind that
+// exits non-zero and checks the failed Start still records the full argv,
+// including flags rendered from Config.
+func Test_LastStartCommand_FailedStart(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"bitcoind", "bitcoin-cli"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\nexit 1\n"), 0o700); err != nil {
+			t.Fatalf("WriteFile %s: %v", name, err)
+		}
+	}
+	bitcoind := filepath.Join(dir, "bitcoind")
+	rt, err := New(&Config{
+		Host:       "127.0.0.1:21110",
+		User:       "user",
+		Pass:       "pass",
+		DataDir:    filepath.Join(t.TempDir(), "regtest"),
+		BinaryPath: bitcoind,
+		UAComment:  "probe",
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Cleanup() })
+
+	if got := rt.LastStartCommand(); got != nil {
+		t.Errorf("LastStartCommand before Start = %v, want nil", got)
+	}
+	if err := rt.Start(); err == nil {
+		t.Fatal("Start should fail with a bitcoind that exits 1")
+	}
+	got := rt.LastStartCommand()
+	if len(got) == 0 || got[0] != bitcoind {
+		t.Fatalf("LastStartCommand = %v, want it to start with %s", got, bitcoind)
+	}
+	for _, want := range []string{"-regtest", "-rpcport=21110", "-uacomment=probe"} {
+		if !slices.Contains(got, want) {
+			t.Errorf("LastStartCommand = %v, missing %s", got, want)
+		}
+	}
+}
+
+// Test_FundingBlocks checks SetupFundedChain's funding-block count against
+// the regtest subsidy schedule (50 BTC, halving every 150 blocks).
+func Test_FundingBlocks(t *testing.T) {
+	const btc = 100_000_000
+	cases := []struct {
+		sats int64
+		want int64
+	}{
+		{1, 1},
+		{50 * btc, 1},
+		{50*btc + 1, 2},
+		{149 * 50 * btc, 149},
+		{149*50*btc + 25*btc, 150},
+	}
+	for _, tc := range cases {
+		got, err := fundingBlocks(tc.sats)
+		if err != nil {
+			t.Fatalf("fundingBlocks(%d): %v", tc.sats, err)
+		}
+		if got != tc.want {
+			t.Errorf("fundingBlocks(%d) = %d, want %d", tc.sats, got, tc.want)
+		}
+	}
+	if _, err := fundingBlocks(21_000_000 * btc); err == nil {
+		t.Error("more than the total regtest subsidy should fail")
+	}
+}
+
+// Test_FreezeChain checks the frozen flag gates block production before any
+// RPC is attempted, and that UnfreezeChain lifts it.
+func Test_FreezeChain(t *testing.T) {
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	const miner = "bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl"
+
+	rt.FreezeChain()
+	if !rt.IsChainFrozen() {
+		t.Fatal("IsChainFrozen = false after FreezeChain")
+	}
+	if err := rt.Warp(1, miner); !errors.Is(err, ErrChainFrozen) {
+		t.Errorf("Warp while frozen: err = %v, want ErrChainFrozen", err)
+	}
+	if err := rt.MineToHeight(5, miner); !errors.Is(err, errNotConnected) {
+		// MineToHeight reads the height first, so before Start it fails there.
+		t.Errorf("MineToHeight before Start: err = %v, want errNotConnected", err)
+	}
+	if err := rt.SubmitBlock(wire.NewMsgBlock(&wire.BlockHeader{})); !errors.Is(err, ErrChainFrozen) {
+		t.Errorf("SubmitBlock while frozen: err = %v, want ErrChainFrozen", err)
+	}
+	if _, err := rt.WarpWithTransactions(miner, nil); !errors.Is(err, ErrChainFrozen) {
+		t.Errorf("WarpWithTransactions while frozen: err = %v, want ErrChainFrozen", err)
+	}
+
+	rt.UnfreezeChain()
+	if rt.IsChainFrozen() {
+		t.Fatal("IsChainFrozen = true after UnfreezeChain")
+	}
+	if err := rt.Warp(1, miner); !errors.Is(err, errNotConnected) {
+		t.Errorf("Warp after unfreeze: err = %v, want errNotConnected", err)
+	}
+}
+
+// Test_EnsureStarted_StartsThenAdopts checks EnsureStarted starts a node
+// when none is running, and that a second instance with the same Config
+// adopts the running node instead of failing on the busy port.
+func Test_EnsureStarted_StartsThenAdopts(t *testing.T) {
+	cfg := &Config{
+		Host:    "127.0.0.1:21120",
+		User:    "user",
+		Pass:    "pass",
+		DataDir: filepath.Join(t.TempDir(), "regtest"),
+	}
+	owner, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { _ = owner.Cleanup() })
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	if err := owner.EnsureStarted(ctx); err != nil {
+		t.Fatalf("EnsureStarted (start): %v", err)
+	}
+	defer owner.Stop()
+
+	adopter, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { _ = adopter.Cleanup() })
+	if err := adopter.EnsureStarted(ctx); err != nil {
+		t.Fatalf("EnsureStarted (adopt): %v", err)
+	}
+	if _, err := adopter.GetBlockCount(); err != nil {
+		t.Errorf("adopted node GetBlockCount: %v", err)
+	}
+	// Idempotent on an already-connected instance.
+	if err := owner.EnsureStarted(ctx); err != nil {
+		t.Errorf("EnsureStarted again: %v", err)
+	}
+}
+
+// Test_EnsureStarted_AdoptSetsUpWallets checks an instance that adopts a
+// running node loads its Config.Wallets and mines to maturity, as Start would.
+func Test_EnsureStarted_AdoptSetsUpWallets(t *testing.T) {
+	cfg := &Config{
+		Host:    "127.0.0.1:21370",
+		User:    "user",
+		Pass:    "pass",
+		DataDir: filepath.Join(t.TempDir(), "regtest"),
+	}
+	owner, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { _ = owner.Cleanup() })
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := owner.StartContext(ctx); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer owner.Stop()
+
+	adoptCfg := *cfg
+	adoptCfg.Wallets = []string{"adopted"}
+	adoptCfg.MineToMaturity = true
+	adopter, err := New(&adoptCfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { _ = adopter.Cleanup() })
+	if err := adopter.EnsureStarted(ctx); err != nil {
+		t.Fatalf("EnsureStarted (adopt): %v", err)
+	}
+
+	raw, err := adopter.rawRPC(ctx, "listwallets")
+	if err != nil {
+		t.Fatalf("listwallets: %v", err)
+	}
+	var loaded []string
+	if err := json.Unmarshal(raw, &loaded); err != nil {
+		t.Fatalf("unmarshal listwallets: %v", err)
+	}
+	if !slices.Contains(loaded, "adopted") {
+		t.Errorf("loaded wallets = %v, want adopted among them", loaded)
+	}
+	if h, err := adopter.GetBlockCount(); err != nil || h != 101 {
+		t.Errorf("height after adopt = %d, %v; want 101", h, err)
+	}
+}
+
+// Test_New_ChangeTypeValidation checks New accepts the four wallet address
+// types for ChangeType and rejects anything else.
+func Test_New_ChangeTypeValidation(t *testing.T) {
+	for _, ct := range []string{"", "legacy", "p2sh-segwit", "bech32", "bech32m"} {
+		if err := (&Config{ChangeType: ct}).validate(); err != nil {
+			t.Errorf("ChangeType %q: %v", ct, err)
+		}
+	}
+	for _, ct := range []string{"p2pkh", "Bech32", "taproot"} {
+		if _, err := New(&Config{ChangeType: ct}); err == nil {
+			t.Errorf("ChangeType %q should be rejected", ct)
+		}
+	}
+}
+
+// Test_New_AssumeValidValidation checks New accepts "0" and a block hash for
+// AssumeValid and rejects anything else.
+func Test_New_AssumeValidValidation(t *testing.T) {
+	valid := []string{"", "0", strings.Repeat("ab", 32)}
+	for _, av := range valid {
+		if err := (&Config{AssumeValid: av}).validate(); err != nil {
+			t.Errorf("AssumeValid %q: %v", av, err)
+		}
+	}
+	for _, av := range []string{"1", "none", strings.Repeat("ab", 31), strings.Repeat("zz", 32)} {
+		if _, err := New(&Config{AssumeValid: av}); err == nil {
+			t.Errorf("AssumeValid %q should be rejected", av)
+		}
+	}
+}
+
+// Test_New_MaxTxFeeValidation checks New rejects negative and non-finite
+// MaxTxFee and BlockMinTxFee values.
+func Test_New_MaxTxFeeValidation(t *testing.T) {
+	for _, fee := range []float64{0, 0.0001, 1} {
+		if err := (&Config{MaxTxFee: fee, BlockMinTxFee: fee}).validate(); err != nil {
+			t.Errorf("fee %v: %v", fee, err)
+		}
+	}
+	for _, fee := range []float64{-0.1, math.NaN(), math.Inf(1)} {
+		if _, err := New(&Config{MaxTxFee: fee}); err == nil {
+			t.Errorf("MaxTxFee %v should be rejected", fee)
+		}
+		if _, err := New(&Config{BlockMinTxFee: fee}); err == nil {
+			t.Errorf("BlockMinTxFee %v should be rejected", fee)
+		}
+	}
+}
+
+// Test_MaxTxFee_WalletLimit starts a node with a tiny MaxTxFee and checks a
+// wallet send whose fee would exceed it is refused.
+func Test_MaxTxFee_WalletLimit(t *testing.T) {
+	rt, err := New(&Config{
+		Host:     "127.0.0.1:21180",
+		User:     "user",
+		Pass:     "pass",
+		DataDir:  filepath.Join(t.TempDir(), "regtest"),
+		MaxTxFee: 0.0001,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet("maxfee"); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	addr, err := rt.GenerateBech32("maxfee")
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := rt.Warp(101, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	if _, err := rt.SendToAddress(addr, 100_000); err != nil {
+		t.Fatalf("SendToAddress at default fee rate: %v", err)
+	}
+
+	// 0.01 BTC/kvB puts a ~140 vB send at ~0.0014 BTC, over the 0.0001 cap.
+	if _, err := rt.SetTxFee(0.01); err != nil {
+		t.Fatalf("SetTxFee: %v", err)
+	}
+	_, err = rt.SendToAddress(addr, 100_000)
+	if err == nil || !strings.Contains(err.Error(), "exceeds maximum") {
+		t.Errorf("SendToAddress above MaxTxFee: err = %v, want a max-fee error", err)
+	}
+}
+
+// Test_CreateStuckTransaction runs the stuck -> bump -> confirm flow on a
+// node whose BlockMinTxFee (10 sat/vB) is above the stuck tx's 1 sat/vB.
+func Test_CreateStuckTransaction(t *testing.T) {
+	rt, err := New(&Config{
+		Host:          "127.0.0.1:21330",
+		User:          "user",
+		Pass:          "pass",
+		DataDir:       filepath.Join(t.TempDir(), "regtest"),
+		BlockMinTxFee: 0.0001,
+		Wallets:       []string{"stuck"},
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if _, err := rt.WarpToWallet(101, "stuck"); err != nil {
+		t.Fatalf("WarpToWallet: %v", err)
+	}
+	addr, err := rt.GenerateBech32("stuck")
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	const miner = "bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl"
+
+	stuck, err := rt.CreateStuckTransaction(addr, 100_000)
+	if err != nil {
+		t.Fatalf("CreateStuckTransaction: %v", err)
+	}
+	if err := rt.Warp(3, miner); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	if _, err := rt.mempoolEntry(context.Background(), stuck); err != nil {
+		t.Fatalf("stuck tx left the mempool: %v", err)
+	}
+
+	bumped, err := rt.BumpFee(stuck, 20)
+	if err != nil {
+		t.Fatalf("BumpFee: %v", err)
+	}
+	if bumped.IsEqual(stuck) {
+		t.Fatal("BumpFee returned the original txid")
+	}
+	if err := rt.Warp(1, miner); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	tx, err := rt.Client().GetRawTransactionVerbose(bumped)
+	if err != nil {
+		t.Fatalf("GetRawTransactionVerbose: %v", err)
+	}
+	if tx.Confirmations != 1 {
+		t.Errorf("bumped tx has %d confirmations, want 1", tx.Confirmations)
+	}
+}
+
+// Test_AssertDeploymentActiveAt forces CSV to activate at height 150 with
+// -testactivationheight and checks only 150 is accepted as the activation
+// height.
+func Test_AssertDeploymentActiveAt(t *testing.T) {
+	rt, err := New(&Config{
+		Host:      "127.0.0.1:21190",
+		User:      "user",
+		Pass:      "pass",
+		DataDir:   filepath.Join(t.TempDir(), "regtest"),
+		ExtraArgs: []string{"-testactivationheight=csv@150"},
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	const miner = "bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl"
+	if err := rt.Warp(151, miner); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+
+	rt.AssertDeploymentActiveAt(t, "csv", 150)
+	if err := rt.CheckDeploymentActiveAt("csv", 149); err == nil || !strings.Contains(err.Error(), "not active") {
+		t.Errorf("height 149: err = %v, want not-active error", err)
+	}
+	if err := rt.CheckDeploymentActiveAt("csv", 151); err == nil || !strings.Contains(err.Error(), "already active") {
+		t.Errorf("height 151: err = %v, want already-active error", err)
+	}
+	if err := rt.CheckDeploymentActiveAt("no-such-fork", 150); !errors.Is(err, ErrUnknownDeployment) {
+		t.Errorf("unknown deployment: err = %v, want ErrUnknownDeployment", err)
+	}
+	if err := rt.CheckDeploymentActiveAt("csv", 0); err == nil {
+		t.Error("height 0 should error")
+	}
+}
+
+// Test_ChangeType_LegacyChange starts a node with ChangeType "legacy" and
+// checks a SendToAddress to a bech32 address produces P2PKH change.
+func Test_ChangeType_LegacyChange(t *testing.T) {
+	rt, err := New(&Config{
+		Host:       "127.0.0.1:21130",
+		User:       "user",
+		Pass:       "pass",
+		DataDir:    filepath.Join(t.TempDir(), "regtest"),
+		ChangeType: "legacy",
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.EnsureWallet("change"); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	addr, err := rt.GenerateBech32("change")
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := rt.Warp(101, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	dest, err := rt.GenerateBech32("dest")
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	txid, err := rt.SendToAddress(dest, 100_000)
+	if err != nil {
+		t.Fatalf("SendToAddress: %v", err)
+	}
+
+	resp, err := rt.rawRPC(context.Background(), "getrawtransaction", txid.String())
+	if err != nil {
+		t.Fatalf("getrawtransaction: %v", err)
+	}
+	var txHex string
+	if err := json.Unmarshal(resp, &txHex); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	raw, err := hex.DecodeString(txHex)
+	if err != nil {
+		t.Fatalf("DecodeString: %v", err)
+	}
+	var tx wire.MsgTx
+	if err := tx.Deserialize(bytes.NewReader(raw)); err != nil {
+		t.Fatalf("Deserialize: %v", err)
+	}
+	destScript, err := ScriptForAddress(dest)
+	if err != nil {
+		t.Fatalf("ScriptForAddress: %v", err)
+	}
+	var sawChange bool
+	for _, out := range tx.TxOut {
+		if bytes.Equal(out.PkScript, destScript) {
+			continue
+		}
+		sawChange = true
+		if !txscript.IsPayToPubKeyHash(out.PkScript) {
+			t.Errorf("change script %x is not P2PKH", out.PkScript)
+		}
+	}
+	if !sawChange {
+		t.Error("expected a change output")
+	}
+}
+
+// Test_FeeHistogram pins bucket placement: lower bounds are inclusive, upper
+// bounds exclusive, sub-1 sat/vB rates land in the first bucket, rates past
+// the last edge in the open-ended top bucket, and empty buckets are dropped.
+func Test_FeeHistogram(t *testing.T) {
+	pool := map[string]MempoolEntry{
+		"a": {VSize: 200, Fee: 20},      // 0.1 sat/vB
+		"b": {VSize: 100, Fee: 100},     // 1 sat/vB (inclusive lower bound)
+		"c": {VSize: 150, Fee: 299},     // ~1.99 sat/vB
+		"d": {VSize: 100, Fee: 1000},    // 10 sat/vB
+		"e": {VSize: 100, Fee: 500_000}, // 5000 sat/vB
+		"f": {VSize: 0, Fee: 100},       // skipped
+	}
+	got := feeHistogram(pool)
+	want := []FeeBucket{
+		{MinFeeRate: 0, MaxFeeRate: 1, Count: 1, VSize: 200, Fee: 20},
+		{MinFeeRate: 1, MaxFeeRate: 2, Count: 2, VSize: 250, Fee: 399},
+		{MinFeeRate: 10, MaxFeeRate: 12, Count: 1, VSize: 100, Fee: 1000},
+		{MinFeeRate: 1000, MaxFeeRate: math.Inf(1), Count: 1, VSize: 100, Fee: 500_000},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("feeHistogram =\n%+v\nwant\n%+v", got, want)
+	}
+	if got := feeHistogram(nil); got == nil || len(got) != 0 {
+		t.Errorf("feeHistogram(nil) = %#v, want empty non-nil slice", got)
+	}
+}
+
+// Test_MultiNode_GetBlockFromPeer builds a stale one-block fork on rt2 while
+// rt1 mines a longer chain, hands rt1 only the fork's header, and checks
+// GetBlockFromPeer makes rt1 download the block from rt2.
+func Test_MultiNode_GetBlockFromPeer(t *testing.T) {
+	newNode := func(port, dir string) *Regtest {
+		rt, err := New(&Config{
+			Host:    "127.0.0.1:" + port,
+			User:    "user",
+			Pass:    "pass",
+			DataDir: filepath.Join(t.TempDir(), dir),
+		})
+		if err != nil {
+			t.Fatalf("New %s: %v", dir, err)
+		}
+		t.Cleanup(func() { _ = rt.Stop(); _ = rt.Cleanup() })
+		if err := rt.Start(); err != nil {
+			t.Fatalf("Start %s: %v", dir, err)
+		}
+		return rt
+	}
+	rt1 := newNode("21140", "rt1")
+	rt2 := newNode("21150", "rt2")
+	const miner = "bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl"
+
+	// Unconnected, rt2 mines a 1-block fork and rt1 a heavier 2-block chain.
+	if err := rt2.Warp(1, miner); err != nil {
+		t.Fatalf("rt2.Warp: %v", err)
+	}
+	stale, err := rt2.GetBestBlockHash()
+	if err != nil {
+		t.Fatalf("rt2.GetBestBlockHash: %v", err)
+	}
+	if err := rt1.Warp(2, miner); err != nil {
+		t.Fatalf("rt1.Warp: %v", err)
+	}
+	block, err := rt2.GetBlock(stale)
+	if err != nil {
+		t.Fatalf("rt2.GetBlock: %v", err)
+	}
+	var hdr bytes.Buffer
+	if err := block.Header.Serialize(&hdr); err != nil {
+		t.Fatalf("Serialize header: %v", err)
+	}
+	if _, err := rt1.rawRPC(context.Background(), "submitheader", hex.EncodeToString(hdr.Bytes())); err != nil {
+		t.Fatalf("rt1 submitheader: %v", err)
+	}
+
+	if err := rt1.Connect(rt2); err != nil {
+		t.Fatalf("rt1.Connect(rt2): %v", err)
+	}
+	var peerID int
+	deadline := time.Now().Add(15 * time.Second)
+	for {
+		resp, err := rt1.rawRPC(context.Background(), "getpeerinfo")
+		if err != nil {
+			t.Fatalf("rt1 getpeerinfo: %v", err)
+		}
+		var peers []struct {
+			ID int `json:"id"`
+		}
+		if err := json.Unmarshal(resp, &peers); err != nil {
+			t.Fatalf("unmarshal getpeerinfo: %v", err)
+		}
+		if len(peers) > 0 {
+			peerID = peers[0].ID
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("rt1 never connected to rt2")
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+
+	if err := rt1.GetBlockFromPeer(stale, peerID); err != nil {
+		t.Fatalf("GetBlockFromPeer: %v", err)
+	}
+	deadline = time.Now().Add(15 * time.Second)
+	for {
+		got, err := rt1.GetBlock(stale)
+		if err == nil {
+			if got.BlockHash() != *stale {
+				t.Fatalf("GetBlock returned %s, want %s", got.BlockHash(), stale)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("stale block never arrived on rt1: %v", err)
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+
+	if err := rt1.GetBlockFromPeer(stale, -1); err == nil {
+		t.Error("negative peerID should error")
+	}
+	if err := rt1.GetBlockFromPeer(nil, peerID); err == nil {
+		t.Error("nil hash should error")
+	}
+}
+
+// Test_SoftForksFromDeployments pins the getdeploymentinfo fallback of
+// GetSoftForks: type and active carry over, and the height is only kept for
+// active deployments.
+func Test_SoftForksFromDeployments(t *testing.T) {
+	got := softForksFromDeployments(map[string]Deployment{
+		"segwit":    {Type: "buried", Active: true, Height: 0},
+		"csv":       {Type: "buried", Active: true, Height: 432},
+		"testdummy": {Type: "bip9", Active: false, Height: 144, BIP9: &BIP9Info{Status: "started"}},
+	})
+	want := map[string]SoftForkInfo{
+		"segwit":    {Type: "buried", Active: true},
+		"csv":       {Type: "buried", Active: true, Height: 432},
+		"testdummy": {Type: "bip9"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(got), len(want))
+	}
+	for name, w := range want {
+		if got[name] != w {
+			t.Errorf("%s = %+v, want %+v", name, got[name], w)
+		}
+	}
+}
+
+// Test_ReuseDataDir_StopKeepsDataDir runs the manager script's stop path
+// (no node on the port) and checks ReuseDataDir keeps the datadir while the
+// default removes it.
+func Test_ReuseDataDir_StopKeepsDataDir(t *testing.T) {
+	for _, reuse := range []bool{true, false} {
+		t.Run(fmt.Sprintf("reuse=%v", reuse), func(t *testing.T) {
+			dataDir := filepath.Join(t.TempDir(), "regtest")
+			if err := os.MkdirAll(dataDir, 0o755); err != nil {
+				t.Fatalf("MkdirAll: %v", err)
+			}
+			marker := filepath.Join(dataDir, "marker")
+			if err := os.WriteFile(marker, nil, 0o600); err != nil {
+				t.Fatalf("WriteFile: %v", err)
+			}
+			rt, err := New(&Config{
+				Host:         "127.0.0.1:21170",
+				User:         "user",
+				Pass:         "pass",
+				DataDir:      dataDir,
+				ReuseDataDir: reuse,
+			})
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			t.Cleanup(func() { _ = rt.Cleanup() })
+			if err := rt.Stop(); err != nil {
+				t.Fatalf("Stop: %v", err)
+			}
+			_, err = os.Stat(marker)
+			if reuse && err != nil {
+				t.Errorf("ReuseDataDir: datadir not kept: %v", err)
+			}
+			if !reuse && !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("default: datadir should be removed, stat err = %v", err)
+			}
+		})
+	}
+}
+
+// Test_ReuseDataDir_ResumesChain builds a chain, stops the node, and checks
+// a fresh instance with the same Config resumes at the same tip.
+func Test_ReuseDataDir_ResumesChain(t *testing.T) {
+	dataDir := filepath.Join(t.TempDir(), "regtest")
+	cfg := &Config{
+		Host:         "127.0.0.1:21160",
+		User:         "user",
+		Pass:         "pass",
+		DataDir:      dataDir,
+		ReuseDataDir: true,
+	}
+	first, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { _ = first.Cleanup() })
+	if err := first.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if err := first.Warp(5, "bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl"); err != nil {
+		_ = first.Stop()
+		t.Fatalf("Warp: %v", err)
+	}
+	tip, err := first.GetBestBlockHash()
+	if err != nil {
+		_ = first.Stop()
+		t.Fatalf("GetBestBlockHash: %v", err)
+	}
+	if err := first.Stop(); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+
+	second, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New (resume): %v", err)
+	}
+	t.Cleanup(func() { _ = second.Cleanup() })
+	if err := second.Start(); err != nil {
+		t.Fatalf("Start (resume): %v", err)
+	}
+	defer second.Stop()
+	height, err := second.GetBlockCount()
+	if err != nil {
+		t.Fatalf("GetBlockCount: %v", err)
+	}
+	if height != 5 {
+		t.Errorf("resumed height = %d, want 5", height)
+	}
+	resumed, err := second.GetBestBlockHash()
+	if err != nil {
+		t.Fatalf("GetBestBlockHash: %v", err)
+	}
+	if !resumed.IsEqual(tip) {
+		t.Errorf("resumed tip = %s, want %s", resumed, tip)
+	}
+}
+
+// fakeNode is a Node mock: the embedded interface stays nil and only the
+// methods a test needs are overridden.
+type fakeNode struct {
+	Node
+	height int64
+}
+
+func (f *fakeNode) GetBlockCount() (int64, error) { return f.height, nil }
+
+// Test_Node_Mockable checks both *Regtest and a partial mock satisfy Node,
+// so code written against Node can be exercised without bitcoind.
+func Test_Node_Mockable(t *testing.T) {
+	heightOf := func(n Node) (int64, error) { return n.GetBlockCount() }
+
+	got, err := heightOf(&fakeNode{height: 101})
+	if err != nil || got != 101 {
+		t.Errorf("fake GetBlockCount = %d, %v; want 101, nil", got, err)
+	}
+
+	rt, err := New(nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Cleanup() })
+	if _, err := heightOf(rt); !errors.Is(err, errNotConnected) {
+		t.Errorf("Regtest GetBlockCount before Start: err = %v, want errNotConnected", err)
+	}
+}
+
+// Test_NewMaybe_BitcoindMissing checks New fails with ErrBitcoindNotFound
+// when bitcoind can't be resolved, while NewMaybe succeeds and defers the
+// same error to Start.
+func Test_NewMaybe_BitcoindMissing(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	missing := filepath.Join(t.TempDir(), "bitcoind")
+
+	for _, cfg := range []*Config{nil, {BinaryPath: missing}} {
+		if _, err := New(cfg); !errors.Is(err, ErrBitcoindNotFound) {
+			t.Errorf("New(%+v): err = %v, want ErrBitcoindNotFound", cfg, err)
+		}
+
+		rt, err := NewMaybe(cfg)
+		if err != nil {
+			t.Fatalf("NewMaybe(%+v): %v", cfg, err)
+		}
+		t.Cleanup(func() { _ = rt.Cleanup() })
+		if err := rt.Start(); !errors.Is(err, ErrBitcoindNotFound) {
+			t.Errorf("Start after NewMaybe(%+v): err = %v, want ErrBitcoindNotFound", cfg, err)
+		}
+	}
+
+	if _, err := NewMaybe(&Config{ChangeType: "p2pkh"}); err == nil {
+		t.Error("NewMaybe should still reject an invalid Config")
+	}
+}
+
+// Test_ManagedPIDs checks KillOrphans' process filter: only regtest bitcoind
+// command lines whose datadir passes the marker check are selected.
+func Test_ManagedPIDs(t *testing.T) {
+	ps := strings.Join([]string{
+		"  101 /usr/local/bin/bitcoind -regtest -datadir=/tmp/managed -server -daemon",
+		"  102 bitcoind -regtest -datadir=/tmp/foreign -server",
+		"  103 /opt/inq/bin/bitcoind-inquisition -regtest -datadir=/tmp/managed2",
+		"  104 bitcoind -datadir=/tmp/managed",
+		"  105 bash bitcoind_manager.sh start /tmp/managed 18443 user pass",
+		"  106 bitcoin-cli -regtest -datadir=/tmp/managed getblockcount",
+		"  abc bitcoind -regtest -datadir=/tmp/managed",
+		"",
+	}, "\n")
+	managed := func(dir string) bool { return dir == "/tmp/managed" || dir == "/tmp/managed2" }
+	if got, want := managedPIDs(ps, managed), []int{101, 103}; !slices.Equal(got, want) {
+		t.Errorf("managedPIDs = %v, want %v", got, want)
+	}
+
+	dir := t.TempDir()
+	if isManagedDataDir(dir) {
+		t.Error("datadir without marker reported as managed")
+	}
+	if err := os.WriteFile(filepath.Join(dir, managedMarker), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if !isManagedDataDir(dir) {
+		t.Error("datadir with marker not reported as managed")
+	}
+}
+
+// Test_KillOrphans starts a node, abandons it, and checks KillOrphans
+// terminates it.
+func Test_KillOrphans(t *testing.T) {
+	rt, err := New(&Config{
+		Host:    "127.0.0.1:21200",
+		User:    "user",
+		Pass:    "pass",
+		DataDir: filepath.Join(t.TempDir(), "regtest"),
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer rt.Stop()
+
+	n, err := KillOrphans()
+	if err != nil {
+		t.Fatalf("KillOrphans: %v", err)
+	}
+	if n < 1 {
+		t.Errorf("KillOrphans = %d, want at least 1", n)
+	}
+	if running, err := rt.IsRunning(); err != nil || running {
+		t.Errorf("IsRunning after KillOrphans = %v, %v; want false", running, err)
+	}
+}
+
+// Test_New_MaxConnectionsValidation checks New rejects a negative
+// MaxConnections.
+func Test_New_MaxConnectionsValidation(t *testing.T) {
+	if err := (&Config{MaxConnections: 16}).validate(); err != nil {
+		t.Errorf("MaxConnections 16: %v", err)
+	}
+	if _, err := New(&Config{MaxConnections: -1}); err == nil {
+		t.Error("MaxConnections -1 should be rejected")
+	}
+}
+
+// Test_MaxConnections_RefusesInbound caps rt1 at 11 connections, which
+// leaves no inbound slots, and checks via GetNetworkInfo that rt2 can't
+// connect to it while rt1 can still connect out to rt2.
+func Test_MaxConnections_RefusesInbound(t *testing.T) {
+	newNode := func(port, dir string, maxConns int) *Regtest {
+		rt, err := New(&Config{
+			Host:           "127.0.0.1:" + port,
+			User:           "user",
+			Pass:           "pass",
+			DataDir:        filepath.Join(t.TempDir(), dir),
+			MaxConnections: maxConns,
+		})
+		if err != nil {
+			t.Fatalf("New %s: %v", dir, err)
+		}
+		t.Cleanup(func() { _ = rt.Stop(); _ = rt.Cleanup() })
+		if err := rt.Start(); err != nil {
+			t.Fatalf("Start %s: %v", dir, err)
+		}
+		return rt
+	}
+	rt1 := newNode("21210", "rt1", 11)
+	rt2 := newNode("21220", "rt2", 0)
+
+	// rt1's inbound limit is 0, so this connection is dropped on accept.
+	_ = rt2.Connect(rt1)
+	if err := rt1.Connect(rt2); err != nil {
+		t.Fatalf("rt1.Connect: %v", err)
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		info, err := rt2.GetNetworkInfo()
+		if err != nil {
+			t.Fatalf("rt2.GetNetworkInfo: %v", err)
+		}
+		if info.ConnectionsIn >= 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("rt2 never saw rt1's outbound connection: %+v", info)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	info, err := rt1.GetNetworkInfo()
+	if err != nil {
+		t.Fatalf("rt1.GetNetworkInfo: %v", err)
+	}
+	if info.ConnectionsIn != 0 {
+		t.Errorf("rt1 ConnectionsIn = %d, want 0 with MaxConnections 11", info.ConnectionsIn)
+	}
+	if info.ConnectionsOut != 1 || info.Connections != 1 {
+		t.Errorf("rt1 connections = %d (out %d), want 1 outbound", info.Connections, info.ConnectionsOut)
+	}
+	if info.Version == 0 || info.Subversion == "" {
+		t.Errorf("rt1 version info missing: %+v", info)
+	}
+}
+
+// Test_Config_Env checks Env validation and that its entries override the
+// inherited environment but not the variables the library sets.
+func Test_Config_Env(t *testing.T) {
+	if _, err := New(&Config{Env: []string{"=x"}}); err == nil {
+		t.Error("Env entry without a key should be rejected")
+	}
+	if _, err := New(&Config{Env: []string{"NOEQUALS"}}); err == nil {
+		t.Error("Env entry without '=' should be rejected")
+	}
+
+	t.Setenv("REGTEST_ENV_PROBE", "inherited")
+	rt, err := New(&Config{Env: []string{
+		"REGTEST_ENV_PROBE=override",
+		"BITCOIND_BIN=/nonexistent",
+		"EMPTY_OK=",
+	}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	lookup := func(env []string, key string) string {
+		val := ""
+		for _, kv := range env {
+			if k, v, _ := strings.Cut(kv, "="); k == key {
+				val = v // last entry wins, as in exec.Cmd
+			}
+		}
+		return val
+	}
+	env := rt.scriptEnv(false)
+	if got := lookup(env, "REGTEST_ENV_PROBE"); got != "override" {
+		t.Errorf("REGTEST_ENV_PROBE = %q, want override", got)
+	}
+	if got := lookup(env, "BITCOIND_BIN"); got != rt.bitcoindPath {
+		t.Errorf("BITCOIND_BIN = %q, want library's %q", got, rt.bitcoindPath)
+	}
+	if got := rt.Config().Env; !slices.Equal(got, []string{"REGTEST_ENV_PROBE=override", "BITCOIND_BIN=/nonexistent", "EMPTY_OK="}) {
+		t.Errorf("Config().Env = %v", got)
+	}
+}
+
+// Test_FastPrune checks that with FastPrune a manual pruneblockchain frees
+// block files after a few hundred blocks.
+func Test_FastPrune(t *testing.T) {
+	rt, err := New(&Config{
+		Host:      "127.0.0.1:21230",
+		User:      "user",
+		Pass:      "pass",
+		DataDir:   filepath.Join(t.TempDir(), "fastprune"),
+		ExtraArgs: []string{"-prune=1", "-txindex=0"},
+		FastPrune: true,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = rt.Cleanup() }()
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer func() { _ = rt.Stop() }()
+
+	// ~250 regtest blocks fit in a 64 KiB file; 600 fills two and leaves
+	// heights up to 312 outside the 288-block keep window.
+	if err := rt.Warp(600, "bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl"); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	raw, err := rt.rawRPC(context.Background(), "pruneblockchain", 300)
+	if err != nil {
+		t.Fatalf("pruneblockchain: %v", err)
+	}
+	var pruned int64
+	if err := json.Unmarshal(raw, &pruned); err != nil {
+		t.Fatalf("decode pruneblockchain: %v", err)
+	}
+	if pruned <= 0 {
+		t.Errorf("pruneblockchain(300) pruned up to %d, want > 0 with FastPrune", pruned)
+	}
+	info, err := rt.GetBlockChainInfo()
+	if err != nil {
+		t.Fatalf("GetBlockChainInfo: %v", err)
+	}
+	if !info.Pruned {
+		t.Error("GetBlockChainInfo().Pruned = false, want true")
+	}
+}
+
+// Test_ValidateDescriptorChecksum runs the BIP380 checksum test vectors.
+func Test_ValidateDescriptorChecksum(t *testing.T) {
+	for _, tc := range []struct {
+		desc    string
+		wantErr string
+	}{
+		{desc: "raw(deadbeef)#89f8spxm"},
+		{desc: "raw(deadbeef)", wantErr: "no checksum"},
+		{desc: "raw(deadbeef)#", wantErr: "must be 8 characters"},
+		{desc: "raw(deadbeef)#89f8spxmx", wantErr: "must be 8 characters"},
+		{desc: "raw(deadbeef)#89f8spxn", wantErr: `expected "89f8spxm"`},
+		{desc: "raw(deedbeef)#89f8spxm", wantErr: "is wrong"},
+		{desc: "raw(dead\u00e9beef)#89f8spxm", wantErr: "invalid descriptor character"},
+	} {
+		err := ValidateDescriptorChecksum(tc.desc)
+		switch {
+		case tc.wantErr == "" && err != nil:
+			t.Errorf("%q: unexpected error %v", tc.desc, err)
+		case tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)):
+			t.Errorf("%q: error = %v, want containing %q", tc.desc, err, tc.wantErr)
+		}
+	}
+}
+
+// Test_UTXOSetsEqual mines diverging chains on two unconnected nodes, checks
+// they compare unequal, then syncs them and checks they compare equal.
+func Test_UTXOSetsEqual(t *testing.T) {
+	newNode := func(port, dir string) *Regtest {
+		rt, err := New(&Config{
+			Host:    "127.0.0.1:" + port,
+			User:    "user",
+			Pass:    "pass",
+			DataDir: filepath.Join(t.TempDir(), dir),
+		})
+		if err != nil {
+			t.Fatalf("New %s: %v", dir, err)
+		}
+		t.Cleanup(func() { _ = rt.Stop(); _ = rt.Cleanup() })
+		if err := rt.Start(); err != nil {
+			t.Fatalf("Start %s: %v", dir, err)
+		}
+		return rt
+	}
+	rt1 := newNode("21240", "rt1")
+	rt2 := newNode("21250", "rt2")
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	if _, err := UTXOSetsEqual(rt1, nil); err == nil {
+		t.Error("UTXOSetsEqual with a nil node should fail")
+	}
+	cmp, err := UTXOSetsEqual(rt1, rt2)
+	if err != nil || !cmp.Equal || cmp.HashA == "" || cmp.HashA != cmp.HashB || cmp.Height != 0 {
+		t.Errorf("fresh nodes: UTXOSetsEqual = %+v, %v; want equal non-empty hashes at height 0", cmp, err)
+	}
+
+	if err := rt2.EnsureWallet(minerWallet); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	other, err := rt2.GenerateBech32("other")
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	const miner = "bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl"
+	if err := rt1.Warp(1, miner); err != nil {
+		t.Fatalf("Warp rt1: %v", err)
+	}
+	if _, err := UTXOSetsEqual(rt1, rt2); err == nil || !strings.Contains(err.Error(), "different heights") {
+		t.Errorf("heights 1 and 0: err = %v, want different heights", err)
+	}
+	if err := rt2.Warp(1, other); err != nil {
+		t.Fatalf("Warp rt2: %v", err)
+	}
+	cmp, err = UTXOSetsEqual(rt1, rt2)
+	if err != nil || cmp.Equal || cmp.HashA == cmp.HashB || cmp.Height != 1 {
+		t.Errorf("diverged coinbases: UTXOSetsEqual = %+v, %v; want differing hashes at height 1", cmp, err)
+	}
+	if info, err := rt2.GetTxOutSetInfo(); cmp != nil && (err != nil || info.Hash != cmp.HashB) {
+		t.Errorf("HashB = %s, want rt2's set hash %+v (%v)", cmp.HashB, info, err)
+	}
+
+	// rt1's longer chain replaces rt2's block once they connect.
+	if err := rt1.Warp(1, miner); err != nil {
+		t.Fatalf("Warp rt1: %v", err)
+	}
+	tip, err := rt1.GetBestBlockHash()
+	if err != nil {
+		t.Fatalf("GetBestBlockHash: %v", err)
+	}
+	if err := rt2.Connect(rt1); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	if err := rt2.WaitForTip(ctx, tip); err != nil {
+		t.Fatalf("WaitForTip: %v", err)
+	}
+	cmp, err = UTXOSetsEqual(rt1, rt2)
+	if err != nil || !cmp.Equal || cmp.HashA != cmp.HashB || cmp.Height != 2 {
+		t.Errorf("synced nodes: UTXOSetsEqual = %+v, %v; want equal hashes at height 2", cmp, err)
+	}
+	info, err := rt1.GetTxOutSetInfo()
+	if err != nil {
+		t.Fatalf("GetTxOutSetInfo: %v", err)
+	}
+	if cmp != nil && info.Hash != cmp.HashA {
+		t.Errorf("HashA = %s, want rt1's set hash %s", cmp.HashA, info.Hash)
+	}
+	if info.Height != 2 || info.BestBlock != tip.String() || info.TxOuts != 2 || info.TotalAmount != 100*btcutil.SatoshiPerBitcoin || info.Hash == "" {
+		t.Errorf("GetTxOutSetInfo = %+v", info)
+	}
+}
+
+// Test_New_MinimumChainWorkValidation checks which chain-work strings New
+// accepts.
+func Test_New_MinimumChainWorkValidation(t *testing.T) {
+	for _, ok := range []string{"", "0x100", "100", "00000000000000000000000000000000000000000000000000000000DEADbeef"} {
+		if err := (&Config{MinimumChainWork: ok}).validate(); err != nil {
+			t.Errorf("MinimumChainWork %q: %v", ok, err)
+		}
+	}
+	for _, bad := range []string{"0x", "xyz", "0x10g", "-100", strings.Repeat("f", 65)} {
+		if _, err := New(&Config{MinimumChainWork: bad}); err == nil {
+			t.Errorf("MinimumChainWork %q should be rejected", bad)
+		}
+	}
+}
+
+// Test_MinimumChainWork checks the node stays in initial block download
+// until its chain reaches MinimumChainWork.
+func Test_MinimumChainWork(t *testing.T) {
+	rt, err := New(&Config{
+		Host:             "127.0.0.1:21260",
+		User:             "user",
+		Pass:             "pass",
+		DataDir:          filepath.Join(t.TempDir(), "minchainwork"),
+		MinimumChainWork: "0x100",
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = rt.Cleanup() }()
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer func() { _ = rt.Stop() }()
+
+	const miner = "bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl"
+	ibd := func() bool {
+		t.Helper()
+		info, err := rt.GetBlockChainInfo()
+		if err != nil {
+			t.Fatalf("GetBlockChainInfo: %v", err)
+		}
+		return info.InitialBlockDownload
+	}
+	// 0x100 = 256 = 2*(127+1): height 126 is one block short.
+	if err := rt.Warp(126, miner); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	if !ibd() {
+		t.Error("InitialBlockDownload = false at height 126, want true below MinimumChainWork")
+	}
+	if err := rt.Warp(1, miner); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	if ibd() {
+		t.Error("InitialBlockDownload = true at height 127, want false once MinimumChainWork is reached")
+	}
+}
+
+// Test_New_RPCSerialVersionValidation checks New accepts only 0 and 1 and
+// that Config() hands out its own copy of the value.
+func Test_New_RPCSerialVersionValidation(t *testing.T) {
+	for _, v := range []int{-1, 2} {
+		if _, err := New(&Config{RPCSerialVersion: &v}); err == nil {
+			t.Errorf("RPCSerialVersion %d should be rejected", v)
+		}
+	}
+	v := 0
+	cfg := &Config{RPCSerialVersion: &v}
+	if err := cfg.validate(); err != nil {
+		t.Fatalf("RPCSerialVersion 0: %v", err)
+	}
+	c := cfg.clone()
+	*c.RPCSerialVersion = 1
+	if v != 0 {
+		t.Error("clone shares RPCSerialVersion with the original")
+	}
+}
+
+// Test_RPCSerialVersion checks RPCSerialVersion 0 strips witness data from
+// getrawtransaction. Skips on Core 28+, which removed the flag.
+func Test_RPCSerialVersion(t *testing.T) {
+	rt, err := New(&Config{
+		Host:             "127.0.0.1:21290",
+		User:             "user",
+		Pass:             "pass",
+		DataDir:          filepath.Join(t.TempDir(), "serialversion"),
+		RPCSerialVersion: new(int),
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = rt.Cleanup() }()
+	if err := rt.Start(); err != nil {
+		if errors.Is(err, ErrUnsupportedVersion) {
+			t.Skipf("bitcoind does not support -rpcserialversion: %v", err)
+		}
+		t.Fatalf("Start: %v", err)
+	}
+	defer func() { _ = rt.Stop() }()
+
+	if err := rt.EnsureWallet("serialversion"); err != nil {
+		t.Fatalf("EnsureWallet: %v", err)
+	}
+	addr, err := rt.GenerateBech32("serialversion")
+	if err != nil {
+		t.Fatalf("GenerateBech32: %v", err)
+	}
+	if err := rt.Warp(101, addr); err != nil {
+		t.Fatalf("Warp: %v", err)
+	}
+	txid, err := rt.SendToAddress(addr, 1_000_000)
+	if err != nil {
+		t.Fatalf("SendToAddress: %v", err)
+	}
+	raw, err := rt.rawRPC(context.Background(), "getrawtransaction", txid.String())
+	if err != nil {
+		t.Fatalf("getrawtransaction: %v", err)
+	}
+	var txHex string
+	if err := json.Unmarshal(raw, &txHex); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	txBytes, err := hex.DecodeString(txHex)
+	if err != nil {
+		t.Fatalf("decode hex: %v", err)
+	}
+	var tx wire.MsgTx
+	if err := tx.Deserialize(bytes.NewReader(txBytes)); err != nil {
+		t.Fatalf("Deserialize: %v", err)
+	}
+	if tx.HasWitness() {
+		t.Error("getrawtransaction returned witness data with RPCSerialVersion 0")
+	}
+	if got := tx.TxHash(); !got.IsEqual(txid) {
+		t.Errorf("stripped tx hashes to %s, want %s", got, txid)
+	}
+}
+
+// Test_New_WalletsValidation checks which Wallets / MineToMaturity
+// combinations New accepts.
+func Test_New_WalletsValidation(t *testing.T) {
+	for _, ok := range []Config{
+		{},
+		{Wallets: []string{"alice"}},
+		{Wallets: []string{"alice", "bob"}, MineToMaturity: true},
+	} {
+		if err := ok.validate(); err != nil {
+			t.Errorf("validate(%+v): %v", ok, err)
+		}
+	}
+	for _, bad := range []Config{
+		{Wallets: []string{""}},
+		{Wallets: []string{"alice", "alice"}},
+		{MineToMaturity: true},
+	} {
+		if _, err := New(&bad); err == nil {
+			t.Errorf("New(%+v) should fail", bad)
+		}
+	}
+}
+
+// Test_Config_Wallets checks StartContext creates Config.Wallets and funds
+// the first with MineToMaturity.
+func Test_Config_Wallets(t *testing.T) {
+	rt, err := New(&Config{
+		Host:           "127.0.0.1:21320",
+		User:           "user",
+		Pass:           "pass",
+		DataDir:        filepath.Join(t.TempDir(), "wallets"),
+		Wallets:        []string{"alice", "bob"},
+		MineToMaturity: true,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = rt.Cleanup() }()
+	if err := rt.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer func() { _ = rt.Stop() }()
+
+	ctx := context.Background()
+	raw, err := rt.rawRPC(ctx, "listwallets")
+	if err != nil {
+		t.Fatalf("listwallets: %v", err)
+	}
+	var loaded []string
+	if err := json.Unmarshal(raw, &loaded); err != nil {
+		t.Fatalf("unmarshal listwallets: %v", err)
+	}
+	slices.Sort(loaded)
+	if !slices.Equal(loaded, []string{"alice", "bob"}) {
+		t.Errorf("loaded wallets = %v, want [alice bob]", loaded)
+	}
+	if h, err := rt.GetBlockCount(); err != nil || h != 101 {
+		t.Errorf("height after Start = %d, %v; want 101", h, err)
+	}
+	for wallet, want := range map[string]float64{"alice": 50, "bob": 0} {
+		raw, err := rt.walletRawRPC(ctx, wallet, "getbalance")
+		if err != nil {
+			t.Fatalf("getbalance %s: %v", wallet, err)
+		}
+		var got float64
+		if err := json.Unmarshal(raw, &got); err != nil {
+			t.Fatalf("unmarshal getbalance: %v", err)
+		}
+		if got != want {
+			t.Errorf("%s balance = %v BTC, want %v", wallet, got, want)
+		}
+	}
+}
+
+// Test_RecordReplay records calls through the recording proxy against a
+// fake JSON-RPC server and replays the trace, checking the wallet endpoint,
+// results and errors survive the round trip.
+func Test_RecordReplay(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		served []string // "path method" per call
+	)
+	fake := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var call struct {
+			Method string          `json:"method"`
+			ID     json.RawMessage `json:"id"`
+		}
+		if err := json.NewDecoder(req.Body).Decode(&call); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		served = append(served, req.URL.Path+" "+call.Method)
+		mu.Unlock()
+		switch call.Method {
+		case "getblockcount":
+			fmt.Fprintf(w, `{"result":7,"error":null,"id":%s}`, call.ID)
+		case "getbalance":
+			fmt.Fprintf(w, `{"result":1.5,"error":null,"id":%s}`, call.ID)
+		default:
+			fmt.Fprintf(w, `{"result":null,"error":{"code":-32601,"message":"Method not found"},"id":%s}`, call.ID)
+		}
+	}))
+	defer fake.Close()
+
+	rt, err := New(&Config{Host: strings.TrimPrefix(fake.URL, "http://"), User: "user", Pass: "pass"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = rt.Cleanup() }()
+	// Connect to the fake server without starting bitcoind.
+	if err := rt.connectClient(); err != nil {
+		t.Fatalf("connectClient: %v", err)
+	}
+	defer func() {
+		if c, err := rt.lockedClient(); err == nil {
+			c.Shutdown()
+		}
+	}()
+
+	if err := rt.StartRecording(nil); err == nil {
+		t.Error("StartRecording(nil) should fail")
+	}
+	var trace bytes.Buffer
+	if err := rt.StartRecording(&trace); err != nil {
+		t.Fatalf("StartRecording: %v", err)
+	}
+	if err := rt.StartRecording(&trace); err == nil {
+		t.Error("second StartRecording should fail")
+	}
+	ctx := context.Background()
+	if h, err := rt.GetBlockCount(); err != nil || h != 7 {
+		t.Fatalf("GetBlockCount = %d, %v; want 7", h, err)
+	}
+	if _, err := rt.rawRPC(ctx, "bogus", 1, "x"); err == nil {
+		t.Fatal("bogus call should fail")
+	}
+	if _, err := rt.walletRawRPC(ctx, "w1", "getbalance"); err != nil {
+		t.Fatalf("getbalance: %v", err)
+	}
+	if err := rt.StopRecording(); err != nil {
+		t.Fatalf("StopRecording: %v", err)
+	}
+	if _, err := rt.GetBlockCount(); err != nil {
+		t.Fatalf("GetBlockCount after StopRecording: %v", err)
+	}
+
+	var calls []RecordedCall
+	dec := json.NewDecoder(bytes.NewReader(trace.Bytes()))
+	for dec.More() {
+		var c RecordedCall
+		if err := dec.Decode(&c); err != nil {
+			t.Fatalf("decode trace: %v", err)
+		}
+		calls = append(calls, c)
+	}
+	if len(calls) != 3 {
+		t.Fatalf("recorded %d calls, want 3:\n%s", len(calls), trace.String())
+	}
+	if c := calls[0]; c.Method != "getblockcount" || string(c.Result) != "7" || c.Error != nil || c.Wallet != "" {
+		t.Errorf("call 0 = %+v", c)
+	}
+	if c := calls[1]; c.Method != "bogus" || len(c.Params) != 2 || string(c.Params[1]) != `"x"` || c.Error == nil || c.Error.Code != -32601 {
+		t.Errorf("call 1 = %+v", c)
+	}
+	if c := calls[2]; c.Method != "getbalance" || c.Wallet != "w1" || string(c.Result) != "1.5" {
+		t.Errorf("call 2 = %+v", c)
+	}
+
+	mu.Lock()
+	served = nil
+	mu.Unlock()
+	if err := rt.ReplayRPC(bytes.NewReader(trace.Bytes())); err != nil {
+		t.Fatalf("ReplayRPC: %v", err)
+	}
+	mu.Lock()
+	got := slices.Clone(served)
+	mu.Unlock()
+	want := []string{"/ getblockcount", "/ bogus", "/wallet/w1 getbalance"}
+	if !slices.Equal(got, want) {
+		t.Errorf("replayed %v, want %v", got, want)
+	}
+
+	// A call that succeeded when recorded but fails now stops the replay.
+	err = rt.ReplayRPC(strings.NewReader(`{"method":"bogus","params":[]}` + "\n" + `{"method":"getblockcount","params":[]}`))
+	if err == nil || !strings.Contains(err.Error(), "call 0 (bogus)") {
+		t.Errorf("ReplayRPC of a newly failing call: err = %v", err)
+	}
+	if err := rt.ReplayRPC(strings.NewReader("{not json")); err == nil {
+		t.Error("ReplayRPC of a malformed trace should fail")
+	}
+}
+
+// Test_ReplayRPC_CacheAndFreeze replays a generatetoaddress trace against a
+// fake bitcoind and checks the replay drops a primed height cache and is
+// refused while the chain is frozen.
+func Test_ReplayRPC_CacheAndFreeze(t *testing.T) {
+	var height, generated atomic.Int64
+	fake := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var call struct {
+			Method string          `json:"method"`
+			ID     json.RawMessage `json:"id"`
+		}
+		_ = json.NewDecoder(req.Body).Decode(&call)
+		result := "null"
+		switch call.Method {
+		case "getblockcount":
+			result = fmt.Sprint(height.Load())
+		case "generatetoaddress":
+			generated.Add(1)
+			height.Add(1)
+			result = `[]`
+		}
+		fmt.Fprintf(w, `{"result":%s,"error":null,"id":%s}`, result, call.ID)
+	}))
+	defer fake.Close()
+
+	rt, err := New(&Config{Host: strings.TrimPrefix(fake.URL, "http://"), User: "user", Pass: "pass", CacheHeight: true})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = rt.Cleanup() }()
+	if err := rt.connectClient(); err != nil {
+		t.Fatalf("connectClient: %v", err)
+	}
+	defer func() {
+		if c, err := rt.lockedClient(); err == nil {
+			c.Shutdown()
+		}
+	}()
+
+	const trace = `{"method":"generatetoaddress","params":[1,"bcrt1qvhadhnxjjeczwgm7y54m2dplur6q2895gtnthl"],"result":[]}` + "\n"
+	if h, err := rt.GetBlockCount(); err != nil || h != 0 {
+		t.Fatalf("GetBlockCount = %d, %v; want 0", h, err)
+	}
+	if err := rt.ReplayRPC(strings.NewReader(trace)); err != nil {
+		t.Fatalf("ReplayRPC: %v", err)
+	}
+	if h, err := rt.GetBlockCount(); err != nil || h != 1 {
+		t.Errorf("GetBlockCount after replay = %d, %v; want 1", h, err)
+	}
+
+	rt.FreezeChain()
+	if err := rt.ReplayRPC(strings.NewReader(trace)); !errors.Is(err, ErrChainFrozen) {
+		t.Errorf("ReplayRPC while frozen = %v, want ErrChainFrozen", err)
+	}
+	if n := generated.Load(); n != 1 {
+		t.Errorf("server saw %d generatetoaddress calls, want 1", n)
+	}
+}
+
+// Test_WaitForConnection points the client at a fake RPC server that
+// refuses the first few calls, then at a dead endpoint.
+func Test_WaitForConnection(t *testing.T) {
+	var calls atomic.Int32
+	fake := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var call struct {
+			ID json.RawMessage `json:"id"`
+		}
+		_ = json.NewDecoder(req.Body).Decode(&call)
+		if calls.Add(1) <= 3 {
+			http.Error(w, "warming up", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintf(w, `{"result":3,"error":null,"id":%s}`, call.ID)
+	}))
+	defer fake.Close()
+
+	rt, err := New(&Config{Host: strings.TrimPrefix(fake.URL, "http://"), User: "user", Pass: "pass"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = rt.Cleanup() }()
+	if err := rt.WaitForConnection(context.Background()); !errors.Is(err, errNotConnected) {
+		t.Fatalf("WaitForConnection before connect = %v, want errNotConnected", err)
+	}
+	if err := rt.connectClient(); err != nil {
+		t.Fatalf("connectClient: %v", err)
+	}
+	defer func() {
+		if c, err := rt.lockedClient(); err == nil {
+			c.Shutdown()
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := rt.WaitForConnection(ctx); err != nil {
+		t.Fatalf("WaitForConnection: %v", err)
+	}
+	if n := calls.Load(); n != 4 {
+		t.Errorf("server saw %d calls, want 4", n)
+	}
+
+	fake.Close()
+	ctx, cancel = context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	err = rt.WaitForConnection(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "last:") {
+		t.Errorf("WaitForConnection on dead endpoint = %v, want deadline error with last RPC error", err)
+	}
+}
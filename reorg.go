@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 )
@@ -45,6 +46,7 @@ func (r *Regtest) InvalidateBlockContext(ctx context.Context, hash *chainhash.Ha
 	_, err = runWithContext(ctx, func() (struct{}, error) {
 		return struct{}{}, client.InvalidateBlock(hash)
 	})
+	r.InvalidateHeightCache()
 	if err != nil {
 		return fmt.Errorf("invalidateblock %s: %w", hash, err)
 	}
@@ -83,6 +85,7 @@ func (r *Regtest) ReconsiderBlockContext(ctx context.Context, hash *chainhash.Ha
 	_, err = runWithContext(ctx, func() (struct{}, error) {
 		return struct{}{}, client.ReconsiderBlock(hash)
 	})
+	r.InvalidateHeightCache()
 	if err != nil {
 		return fmt.Errorf("reconsiderblock %s: %w", hash, err)
 	}
@@ -115,6 +118,7 @@ func (r *Regtest) PreciousBlockContext(ctx context.Context, hash *chainhash.Hash
 	// preciousblock takes a hex-encoded block hash. The hash bytes need
 	// big-endian (display) ordering, which chainhash.Hash.String() provides.
 	raw, err := r.rawRPC(ctx, "preciousblock", hash.String())
+	r.InvalidateHeightCache()
 	if err != nil {
 		return fmt.Errorf("preciousblock %s: %w", hash, err)
 	}
@@ -128,3 +132,51 @@ func (r *Regtest) PreciousBlockContext(ctx context.Context, hash *chainhash.Hash
 	}
 	return nil
 }
+
+// WaitForTip polls getbestblockhash at ~100ms intervals until the node's tip
+// equals hash or ctx expires. Height alone is ambiguous in reorg tests (two
+// forks can share a height); waiting on the tip hash confirms the node
+// converged on a specific chain.
+//
+// WaitForTip does not mine or reorg anything itself — callers drive chain
+// progress (peers syncing, ReconsiderBlock, SubmitBlock, ...) in parallel.
+//
+// Parameters:
+//   - ctx: bounds the wait; use context.WithTimeout to cap it.
+//   - hash: expected tip hash (must be non-nil)
+//
+// Returns:
+//   - error: validation error for nil hash; errNotConnected before Start;
+//     wrapped RPC error; on expiry an error wrapping ctx.Err() that names
+//     the last tip seen.
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer cancel()
+//	if err := rt2.WaitForTip(ctx, forkTip); err != nil {
+//	    t.Fatalf("rt2 did not reorg onto the fork: %v", err)
+//	}
+func (r *Regtest) WaitForTip(ctx context.Context, hash *chainhash.Hash) error {
+	if hash == nil {
+		return fmt.Errorf("hash must not be nil")
+	}
+	const interval = 100 * time.Millisecond
+	for {
+		tip, err := r.GetBestBlockHashContext(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("waiting for tip %s: %w", hash, ctx.Err())
+			}
+			return err
+		}
+		if tip.IsEqual(hash) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for tip %s (last seen %s): %w", hash, tip, ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+}
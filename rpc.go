@@ -3,7 +3,10 @@ package regtest
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
+	"time"
 
 	"github.com/btcsuite/btcd/rpcclient"
 )
@@ -60,9 +63,129 @@ func (r *Regtest) GetBlockCountContext(ctx context.Context) (int64, error) {
 	if err != nil {
 		return 0, err
 	}
-	return runWithContext(ctx, func() (int64, error) {
+	if h, ok := r.cachedHeight(); ok {
+		return h, nil
+	}
+	gen := r.heightGeneration()
+	h, err := runWithContext(ctx, func() (int64, error) {
 		return client.GetBlockCount()
 	})
+	if err != nil {
+		return 0, err
+	}
+	r.storeHeight(h, gen)
+	return h, nil
+}
+
+// InvalidateHeightCache drops the height cached under Config.CacheHeight so
+// the next GetBlockCount issues a fresh getblockcount. Call it after moving
+// the tip through a path the library can't observe — Client() calls, a
+// manual bitcoin-cli, or blocks relayed from a peer connected outside this
+// library. A no-op when CacheHeight is off.
+//
+// Example:
+//
+//	rt.Client().Generate(1) // bypasses Warp
+//	rt.InvalidateHeightCache()
+//	h, _ := rt.GetBlockCount()
+func (r *Regtest) InvalidateHeightCache() {
+	r.heightMu.Lock()
+	defer r.heightMu.Unlock()
+	r.heightValid = false
+	r.heightGen++
+}
+
+// cachedHeight returns the cached tip height when Config.CacheHeight is on,
+// the cache has been primed, and the node has not been peered.
+func (r *Regtest) cachedHeight() (int64, bool) {
+	if !r.config.CacheHeight {
+		return 0, false
+	}
+	r.heightMu.Lock()
+	defer r.heightMu.Unlock()
+	if !r.heightValid || r.peered {
+		return 0, false
+	}
+	return r.height, true
+}
+
+// heightGeneration snapshots the cache generation, which every mining
+// call, invalidation and peering bumps. Take it before issuing
+// getblockcount and hand it to storeHeight.
+func (r *Regtest) heightGeneration() uint64 {
+	r.heightMu.Lock()
+	defer r.heightMu.Unlock()
+	return r.heightGen
+}
+
+// storeHeight primes the cache with a height freshly read from bitcoind.
+// The write is dropped while a mining call is in flight, or when the
+// generation moved since gen was taken: either way the reading may already
+// include blocks that endMining is about to add, or predate ones it added.
+func (r *Regtest) storeHeight(h int64, gen uint64) {
+	if !r.config.CacheHeight {
+		return
+	}
+	r.heightMu.Lock()
+	defer r.heightMu.Unlock()
+	if r.mining > 0 || gen != r.heightGen {
+		return
+	}
+	r.height = h
+	r.heightValid = true
+}
+
+// beginMining marks a block-producing RPC as in flight. Call it before
+// issuing the RPC, and endMining or abortMining once it has finished.
+func (r *Regtest) beginMining() {
+	r.heightMu.Lock()
+	defer r.heightMu.Unlock()
+	r.mining++
+	r.heightGen++
+}
+
+// endMining closes a beginMining whose RPC mined n blocks, bumping a
+// primed cache by n. An unprimed cache stays unprimed: without a known base
+// the sum would be meaningless.
+func (r *Regtest) endMining(n int64) {
+	r.heightMu.Lock()
+	defer r.heightMu.Unlock()
+	r.mining--
+	r.heightGen++
+	if r.heightValid {
+		r.height += n
+	}
+}
+
+// abortMining closes a beginMining whose block count is unknown (the RPC
+// failed, or moved the tip by an amount the caller can't tell), dropping
+// the cache.
+func (r *Regtest) abortMining() {
+	r.heightMu.Lock()
+	defer r.heightMu.Unlock()
+	r.mining--
+	r.heightGen++
+	r.heightValid = false
+}
+
+// markPeered disables height caching for the rest of the node's lifetime:
+// once a peer is attached, blocks can arrive without the library noticing.
+func (r *Regtest) markPeered() {
+	r.heightMu.Lock()
+	defer r.heightMu.Unlock()
+	r.peered = true
+	r.heightValid = false
+	r.heightGen++
+}
+
+// resetHeightCache clears all cache state. Called on Start and Stop, since
+// a fresh process starts with a fresh chain and no peers.
+func (r *Regtest) resetHeightCache() {
+	r.heightMu.Lock()
+	defer r.heightMu.Unlock()
+	r.heightValid = false
+	r.peered = false
+	r.heightGen++
 }
 
 // HealthCheck performs a minimal RPC round-trip (getblockcount) to confirm
@@ -97,6 +220,48 @@ func (r *Regtest) HealthCheckContext(ctx context.Context) error {
 	return nil
 }
 
+// WaitForConnection blocks until the node answers getblockcount through
+// this instance's RPC client, polling every 100ms. StartContext already calls
+// it before returning; use it directly after adopting a node, or when
+// bitcoind was started by other means.
+//
+// Parameters:
+//   - ctx: bounds the wait; checked between polls
+//
+// Returns:
+//   - error: errNotConnected before Start; on ctx expiry, ctx.Err() together
+//     with the last RPC error seen.
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer cancel()
+//	if err := rt.WaitForConnection(ctx); err != nil {
+//	    t.Fatalf("node never answered: %v", err)
+//	}
+func (r *Regtest) WaitForConnection(ctx context.Context) error {
+	const interval = 100 * time.Millisecond
+	for {
+		gen := r.heightGeneration()
+		raw, err := r.rawRPC(ctx, "getblockcount")
+		if errors.Is(err, errNotConnected) {
+			return err
+		}
+		if err == nil {
+			var height int64
+			if err = json.Unmarshal(raw, &height); err == nil {
+				r.storeHeight(height, gen)
+				return nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for RPC connection: %w (last: %v)", ctx.Err(), err)
+		case <-time.After(interval):
+		}
+	}
+}
+
 // lockedClient returns the current RPC client under read-lock, or errNotConnected
 // if Start() has not been called (or Stop() cleared the client). The returned
 // client is safe to use after the lock is released because *rpcclient.Client is
@@ -119,7 +284,55 @@ func (r *Regtest) rawRPC(ctx context.Context, method string, args ...any) (json.
 	if err != nil {
 		return nil, err
 	}
+	params, err := marshalParams(method, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return runWithContext(ctx, func() (json.RawMessage, error) {
+		resp, err := client.RawRequest(method, params)
+		if err != nil {
+			return nil, fmt.Errorf("rawRPC %q failed: %w", method, err)
+		}
+		return resp, nil
+	})
+}
+
+// walletRawRPC is rawRPC against the /wallet/<name> endpoint, so the call
+// targets that wallet even when several are loaded. An empty wallet falls
+// back to rawRPC (bitcoind's single-loaded-wallet default). Each call uses a
+// short-lived client because rpcclient fixes the URL path per client.
+func (r *Regtest) walletRawRPC(ctx context.Context, wallet, method string, args ...any) (json.RawMessage, error) {
+	if wallet == "" {
+		return r.rawRPC(ctx, method, args...)
+	}
+	if _, err := r.lockedClient(); err != nil {
+		return nil, err
+	}
+	params, err := marshalParams(method, args)
+	if err != nil {
+		return nil, err
+	}
+	cfg := r.dialConfig()
+	cfg.Host += "/wallet/" + url.PathEscape(wallet)
+	client, err := rpcclient.New(cfg, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create wallet RPC client: %w", err)
+	}
+
+	return runWithContext(ctx, func() (json.RawMessage, error) {
+		defer client.Shutdown()
+		resp, err := client.RawRequest(method, params)
+		if err != nil {
+			return nil, fmt.Errorf("rawRPC %q (wallet %q) failed: %w", method, wallet, err)
+		}
+		return resp, nil
+	})
+}
 
+// marshalParams JSON-encodes rawRPC arguments; json.RawMessage values pass
+// through unchanged.
+func marshalParams(method string, args []any) ([]json.RawMessage, error) {
 	params := make([]json.RawMessage, len(args))
 	for i, a := range args {
 		if rm, ok := a.(json.RawMessage); ok {
@@ -132,14 +345,7 @@ func (r *Regtest) rawRPC(ctx context.Context, method string, args ...any) (json.
 		}
 		params[i] = b
 	}
-
-	return runWithContext(ctx, func() (json.RawMessage, error) {
-		resp, err := client.RawRequest(method, params)
-		if err != nil {
-			return nil, fmt.Errorf("rawRPC %q failed: %w", method, err)
-		}
-		return resp, nil
-	})
+	return params, nil
 }
 
 // runWithContext runs fn in a goroutine and returns its result, or ctx.Err()
@@ -11,7 +11,12 @@
 # BITCOIND_BIN and BITCOIN_CLI_BIN environment variables (set by the Go side
 # from Config.BinaryPath, or auto-detected to bitcoind-inquisition / bitcoind
 # on PATH). When unset the literal names are used, so the script still works
-# when invoked directly by humans.
+# when invoked directly by humans. REGTEST_CMD_FILE, when set, receives the
+# bitcoind argv used by start. REGTEST_REUSE_DATADIR=1 (Config.ReuseDataDir)
+# keeps the datadir: start runs on the existing chain state and stop leaves
+# it on disk. REGTEST_P2P_PORT (Config.P2PPort) overrides the P2P port,
+# which otherwise is the RPC port + 1. Every datadir start uses gets a
+# .go-regtest marker file, which the Go side's KillOrphans keys on.
 
 BITCOIND="${BITCOIND_BIN:-bitcoind}"
 BITCOIN_CLI="${BITCOIN_CLI_BIN:-bitcoin-cli}"
@@ -39,37 +44,49 @@ start_bitcoind() {
         exit 1
     fi
     
-    # Clean up existing datadir
-    if [ -d "$DATADIR" ]; then
+    # Clean up existing datadir, unless the caller is resuming it
+    if [ "$REGTEST_REUSE_DATADIR" = "1" ]; then
+        echo "Reusing existing datadir..."
+    elif [ -d "$DATADIR" ]; then
         echo "Cleaning up existing datadir..."
         rm -rf "$DATADIR"
     fi
     
-    # Create datadir
+    # Create datadir, marked as managed by go-regtest so KillOrphans can
+    # tell its bitcoind processes from unrelated ones
     mkdir -p "$DATADIR"
+    touch "$DATADIR/.go-regtest"
     
-    # Calculate P2P port (RPC_PORT + 1)
-    P2P_PORT=$((RPC_PORT + 1))
+    # P2P port: REGTEST_P2P_PORT (Config.P2PPort) when set, else RPC_PORT + 1
+    P2P_PORT="${REGTEST_P2P_PORT:-$((RPC_PORT + 1))}"
     
     # Start bitcoind. Args after the fixed positional set (EXTRA_ARGS) are
     # forwarded verbatim from Config.ExtraArgs on the Go side. Wrap in `if !`
     # so unknown-flag errors fail fast instead of waiting for the polling
     # loop to time out.
+    CMD=(
+        "$BITCOIND"
+        -regtest
+        -datadir="$DATADIR"
+        -server
+        -rpcuser="$RPC_USER"
+        -rpcpassword="$RPC_PASS"
+        -rpcport="$RPC_PORT"
+        -port="$P2P_PORT"
+        -rpcbind=127.0.0.1
+        -rpcallowip=127.0.0.1
+        -fallbackfee=0.0002
+        -txindex
+        -daemon
+        "${EXTRA_ARGS[@]}"
+    )
+    # When REGTEST_CMD_FILE is set (by the Go side), record the exact argv,
+    # NUL-separated, before launching so it survives a failed start.
+    if [ -n "$REGTEST_CMD_FILE" ]; then
+        printf '%s\0' "${CMD[@]}" > "$REGTEST_CMD_FILE"
+    fi
     echo "Starting bitcoind ($BITCOIND) in regtest mode..."
-    if ! "$BITCOIND" \
-        -regtest \
-        -datadir="$DATADIR" \
-        -server \
-        -rpcuser="$RPC_USER" \
-        -rpcpassword="$RPC_PASS" \
-        -rpcport="$RPC_PORT" \
-        -port="$P2P_PORT" \
-        -rpcbind=127.0.0.1 \
-        -rpcallowip=127.0.0.1 \
-        -fallbackfee=0.0002 \
-        -txindex \
-        -daemon \
-        "${EXTRA_ARGS[@]}"; then
+    if ! "${CMD[@]}"; then
         echo "ERROR: bitcoind exited non-zero on launch (likely invalid flag)"
         exit 1
     fi
@@ -94,7 +111,7 @@ stop_bitcoind() {
     if ! is_running; then
         echo "bitcoind is not running"
         # Clean up datadir anyway
-        if [ -d "$DATADIR" ]; then
+        if [ "$REGTEST_REUSE_DATADIR" != "1" ] && [ -d "$DATADIR" ]; then
             echo "Cleaning up datadir..."
             rm -rf "$DATADIR"
         fi
@@ -124,8 +141,8 @@ stop_bitcoind() {
         fi
     fi
     
-    # Clean up datadir
-    if [ -d "$DATADIR" ]; then
+    # Clean up datadir, unless the caller is keeping it for reuse
+    if [ "$REGTEST_REUSE_DATADIR" != "1" ] && [ -d "$DATADIR" ]; then
         echo "Cleaning up datadir..."
         rm -rf "$DATADIR"
     fi
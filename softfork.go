@@ -86,9 +86,9 @@ func parseSoftForkStatus(s string) SoftForkStatus {
 	}
 }
 
-// ErrUnknownDeployment is returned by DeploymentStatus when the named
-// deployment is not present in bitcoind's getdeploymentinfo response.
-// This is the signal a test should use to t.Skip when a soft-fork specific
+// ErrUnknownDeployment is returned by DeploymentStatus and GetDeployment
+// when the named deployment is not present in bitcoind's getdeploymentinfo
+// response. This is the signal a test should use to t.Skip when a soft-fork specific
 // test is run against a bitcoind binary that doesn't know that deployment
 // (e.g. running an APO test against mainline Core).
 var ErrUnknownDeployment = errors.New("unknown deployment")
@@ -235,14 +235,11 @@ func (r *Regtest) DeploymentStatus(name string) (SoftForkStatus, error) {
 
 // DeploymentStatusContext is the context-aware variant of DeploymentStatus.
 func (r *Regtest) DeploymentStatusContext(ctx context.Context, name string) (SoftForkStatus, error) {
-	info, err := r.GetDeploymentInfoContext(ctx)
+	info, err := r.GetDeploymentContext(ctx, name)
 	if err != nil {
 		return SoftForkUnknown, err
 	}
-	d, ok := info.Deployments[name]
-	if !ok {
-		return SoftForkUnknown, fmt.Errorf("%w: %q", ErrUnknownDeployment, name)
-	}
+	d := info.Deployments[name]
 	// Buried deployments don't carry a BIP9 sub-object; they're hard-coded
 	// active.
 	if d.BIP9 == nil {
@@ -283,9 +280,11 @@ func (r *Regtest) waitForDeployment(ctx context.Context, name string, target Sof
 }
 
 // renderExtraArgs builds the slice of bitcoind flags to forward on Start.
-// It composes Config.ExtraArgs with one -vbparams=... per VBParam and
-// -acceptnonstdtxn=1 when AcceptNonstdTxn is true. The order is stable:
-// ExtraArgs first, then VBParams in declaration order, then AcceptNonstdTxn.
+// It composes Config.ExtraArgs with one -vbparams=... per VBParam,
+// -acceptnonstdtxn=1 when AcceptNonstdTxn is true, and the flags for the
+// remaining typed Config fields. The order is stable: ExtraArgs first, then
+// VBParams in declaration order, then AcceptNonstdTxn, then the other typed
+// fields in Config declaration order.
 //
 // VBParams render in the 3-field form (deployment:start:timeout) unless
 // MinActivationHeight is non-zero, in which case the 4-field form
@@ -306,6 +305,81 @@ func (c *Config) renderExtraArgs() []string {
 	if c.AcceptNonstdTxn {
 		args = append(args, "-acceptnonstdtxn=1")
 	}
+	if c.ExternalSigner != "" {
+		args = append(args, "-signer="+c.ExternalSigner)
+	}
+	if c.UAComment != "" {
+		args = append(args, "-uacomment="+c.UAComment)
+	}
+	for _, cat := range c.DebugCategories {
+		args = append(args, "-debug="+cat)
+	}
+	if c.BlocksOnly {
+		args = append(args, "-blocksonly=1")
+	}
+	args = append(args, c.renderMempoolLimits()...)
+	if c.ConfFile != "" {
+		args = append(args, "-conf="+c.ConfFile)
+	}
+	if c.PersistMempoolV1 {
+		args = append(args, "-persistmempoolv1=1")
+	}
+	if c.ChangeType != "" {
+		args = append(args, "-changetype="+c.ChangeType)
+	}
+	return append(args, c.renderTuningArgs()...)
+}
+
+// renderTuningArgs renders the flags for the validation, fee, connection and
+// RPC tuning fields declared from AssumeValid on, in Config declaration
+// order.
+func (c *Config) renderTuningArgs() []string {
+	var args []string
+	if c.AssumeValid != "" {
+		args = append(args, "-assumevalid="+c.AssumeValid)
+	}
+	if c.MaxTxFee > 0 {
+		args = append(args, fmt.Sprintf("-maxtxfee=%.8f", c.MaxTxFee))
+	}
+	if c.BlockMinTxFee > 0 {
+		args = append(args, fmt.Sprintf("-blockmintxfee=%.8f", c.BlockMinTxFee))
+	}
+	if c.MaxConnections > 0 {
+		args = append(args, fmt.Sprintf("-maxconnections=%d", c.MaxConnections))
+	}
+	if c.FastPrune {
+		args = append(args, "-fastprune=1")
+	}
+	if c.MinimumChainWork != "" {
+		args = append(args, "-minimumchainwork="+c.MinimumChainWork)
+	}
+	if c.RPCSerialVersion != nil {
+		args = append(args, fmt.Sprintf("-rpcserialversion=%d", *c.RPCSerialVersion))
+		if *c.RPCSerialVersion == 0 {
+			args = append(args, "-deprecatedrpc=serialversion")
+		}
+	}
+	return args
+}
+
+// renderMempoolLimits renders the -limit* package-policy flags for the
+// Limit* Config fields that are set (> 0), in Config declaration order.
+func (c *Config) renderMempoolLimits() []string {
+	limits := []struct {
+		flag  string
+		value int
+	}{
+		{"-limitancestorcount", c.LimitAncestorCount},
+		{"-limitdescendantcount", c.LimitDescendantCount},
+		{"-limitancestorsize", c.LimitAncestorSize},
+		{"-limitdescendantsize", c.LimitDescendantSize},
+	}
+	var args []string
+	for _, l := range limits {
+		if l.value > 0 {
+			args = append(args, fmt.Sprintf("%s=%d", l.flag, l.value))
+		}
+	}
 	return args
 }
 
@@ -346,3 +420,121 @@ func (r *Regtest) GetDeploymentInfoContext(ctx context.Context) (*DeploymentInfo
 	}
 	return &info, nil
 }
+
+// GetDeployment returns getdeploymentinfo narrowed to a single deployment:
+// the evaluated tip hash and height, and a Deployments map holding only
+// name. bitcoind has no single-deployment form of the RPC, so the full
+// response is fetched and filtered. This is the focused call to poll while
+// activating one specific fork.
+//
+// Parameters:
+//   - name: deployment name as known to bitcoind (e.g. "testdummy",
+//     "taproot", "anyprevout")
+//
+// Returns:
+//   - *DeploymentInfo: Hash, Height and the single named deployment
+//   - error: ErrUnknownDeployment (errors.Is compatible) when the node
+//     doesn't report the deployment; errNotConnected before Start;
+//     otherwise the wrapped RPC or unmarshal error.
+//
+// Example:
+//
+//	info, err := rt.GetDeployment("testdummy")
+//	if err != nil {
+//	    return err
+//	}
+//	d := info.Deployments["testdummy"]
+//	if d.BIP9 != nil && d.BIP9.Statistics != nil {
+//	    fmt.Printf("%d/%d signaling\n", d.BIP9.Statistics.Count, d.BIP9.Statistics.Threshold)
+//	}
+func (r *Regtest) GetDeployment(name string) (*DeploymentInfo, error) {
+	return r.GetDeploymentContext(context.Background(), name)
+}
+
+// GetDeploymentContext is the context-aware variant of GetDeployment.
+func (r *Regtest) GetDeploymentContext(ctx context.Context, name string) (*DeploymentInfo, error) {
+	info, err := r.GetDeploymentInfoContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	d, ok := info.Deployments[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownDeployment, name)
+	}
+	info.Deployments = map[string]Deployment{name: d}
+	return info, nil
+}
+
+// SoftForkInfo is the compact per-deployment summary returned by
+// GetSoftForks.
+type SoftForkInfo struct {
+	// Type is "buried" or "bip9".
+	Type string `json:"type"`
+	// Active reports whether the deployment is enforced at the tip.
+	Active bool `json:"active"`
+	// Height is the activation height; zero when not active.
+	Height int64 `json:"height"`
+}
+
+// GetSoftForks returns the softforks section of getblockchaininfo — type,
+// active flag and activation height per deployment — for a quick "is
+// segwit/taproot active" check in conditional test setup.
+//
+// Bitcoin Core 23 moved this section out of getblockchaininfo into
+// getdeploymentinfo. When getblockchaininfo carries no softforks object,
+// GetSoftForks falls back to getdeploymentinfo and summarizes it in the
+// same shape, so callers see the same result on old and new nodes.
+//
+// Returns:
+//   - map[string]SoftForkInfo: deployment name → summary
+//   - error: errNotConnected before Start; otherwise wrapped RPC or
+//     unmarshal error.
+//
+// Example:
+//
+//	forks, err := rt.GetSoftForks()
+//	if err != nil {
+//	    return err
+//	}
+//	if !forks["taproot"].Active {
+//	    t.Skip("taproot not active")
+//	}
+func (r *Regtest) GetSoftForks() (map[string]SoftForkInfo, error) {
+	return r.GetSoftForksContext(context.Background())
+}
+
+// GetSoftForksContext is the context-aware variant of GetSoftForks.
+func (r *Regtest) GetSoftForksContext(ctx context.Context) (map[string]SoftForkInfo, error) {
+	raw, err := r.rawRPC(ctx, "getblockchaininfo")
+	if err != nil {
+		return nil, fmt.Errorf("getblockchaininfo: %w", err)
+	}
+	var chain struct {
+		SoftForks map[string]SoftForkInfo `json:"softforks"`
+	}
+	if err := json.Unmarshal(raw, &chain); err != nil {
+		return nil, fmt.Errorf("unmarshal getblockchaininfo: %w", err)
+	}
+	if chain.SoftForks != nil {
+		return chain.SoftForks, nil
+	}
+	info, err := r.GetDeploymentInfoContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return softForksFromDeployments(info.Deployments), nil
+}
+
+// softForksFromDeployments reduces getdeploymentinfo entries to the
+// SoftForkInfo summary.
+func softForksFromDeployments(deployments map[string]Deployment) map[string]SoftForkInfo {
+	out := make(map[string]SoftForkInfo, len(deployments))
+	for name, d := range deployments {
+		sf := SoftForkInfo{Type: d.Type, Active: d.Active}
+		if d.Active {
+			sf.Height = d.Height
+		}
+		out[name] = sf
+	}
+	return out
+}
@@ -70,6 +70,9 @@ func (r *Regtest) SetMockTimeContext(ctx context.Context, unix int64) error {
 	if _, err := r.rawRPC(ctx, "setmocktime", unix); err != nil {
 		return fmt.Errorf("SetMockTime: %w", err)
 	}
+	r.mockMu.Lock()
+	r.mockTime = unix
+	r.mockMu.Unlock()
 	return nil
 }
 
@@ -202,3 +205,132 @@ func (r *Regtest) WarpTimeContext(ctx context.Context, duration time.Duration, m
 	}
 	return postInfo.MedianTime, nil
 }
+
+// GetMedianTimePast returns the chain tip's median time past: the median
+// timestamp of the last 11 blocks, read from getblockchaininfo's mediantime.
+// Time-based nLockTime (BIP113) and CSV are evaluated against MTP, not the
+// tip's timestamp or the wall clock, so assert timelock boundaries against
+// this value after SetMockTime, MineWithTimestamp or WarpTime.
+//
+// Returns:
+//   - int64: median time past as Unix seconds
+//   - error: errNotConnected before Start; otherwise wrapped RPC error.
+//
+// Example:
+//
+//	mtp, err := rt.GetMedianTimePast()
+//	if err != nil {
+//	    return err
+//	}
+//	tx.LockTime = uint32(mtp + 3600) // final once MTP passes an hour ahead
+func (r *Regtest) GetMedianTimePast() (int64, error) {
+	return r.GetMedianTimePastContext(context.Background())
+}
+
+// GetMedianTimePastContext is the context-aware variant of GetMedianTimePast.
+func (r *Regtest) GetMedianTimePastContext(ctx context.Context) (int64, error) {
+	info, err := r.GetBlockChainInfoContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return info.MedianTime, nil
+}
+
+// currentMockTime returns the mocktime last set through this instance, or
+// the wall clock when none has been set since Start. bitcoind has no RPC to
+// read mocktime back, so the instance tracks it.
+func (r *Regtest) currentMockTime() int64 {
+	r.mockMu.Lock()
+	defer r.mockMu.Unlock()
+	if r.mockTime != 0 {
+		return r.mockTime
+	}
+	return time.Now().Unix()
+}
+
+// SyncMockTime sets the same mocktime on every node, e.g. to line up a
+// multi-node setup before SkewMockTime pushes one of them off. Convenience
+// wrapper around SyncMockTimeContext using context.Background().
+//
+// All arguments are validated and every node's connection checked before
+// any setmocktime is sent, so a nil or stopped node fails the call without
+// touching the others. Once RPCs start, a failing node aborts the loop and
+// the nodes before it keep the new time; the error names the failing index.
+//
+// Parameters:
+//   - nodes: instances to update (must be non-empty, no nil entries)
+//   - unix: target time, with the same bounds as SetMockTime
+//
+// Returns:
+//   - error: validation error; errNotConnected for a node not started;
+//     otherwise the wrapped setmocktime error of the first failing node.
+//
+// Example:
+//
+//	now := time.Now().Unix()
+//	if err := regtest.SyncMockTime([]*regtest.Regtest{a, b}, now); err != nil {
+//	    return err
+//	}
+func SyncMockTime(nodes []*Regtest, unix int64) error {
+	return SyncMockTimeContext(context.Background(), nodes, unix)
+}
+
+// SyncMockTimeContext is the context-aware variant of SyncMockTime.
+func SyncMockTimeContext(ctx context.Context, nodes []*Regtest, unix int64) error {
+	if len(nodes) == 0 {
+		return fmt.Errorf("SyncMockTime: at least one node required")
+	}
+	if unix <= 0 || unix > maxMockTime {
+		return fmt.Errorf("SyncMockTime: unix must be in (0, %d], got %d", maxMockTime, unix)
+	}
+	for i, n := range nodes {
+		if n == nil {
+			return fmt.Errorf("SyncMockTime: nodes[%d] is nil", i)
+		}
+		if _, err := n.lockedClient(); err != nil {
+			return fmt.Errorf("SyncMockTime: nodes[%d]: %w", i, err)
+		}
+	}
+	for i, n := range nodes {
+		if err := n.SetMockTimeContext(ctx, unix); err != nil {
+			return fmt.Errorf("SyncMockTime: nodes[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// SkewMockTime moves node's clock by offset relative to its current
+// mocktime — the value last set through SetMockTime, SyncMockTime,
+// MineWithTimestamp or a previous SkewMockTime, or the wall clock if none
+// has been set since Start. Use it to desync one node from its peers, e.g.
+// to check that blocks it mines more than two hours ahead are rejected
+// elsewhere with "time-too-new". Convenience wrapper around
+// SkewMockTimeContext using context.Background().
+//
+// Parameters:
+//   - node: instance to skew (must be non-nil)
+//   - offset: signed shift; negative values move the clock back
+//
+// Returns:
+//   - error: validation error for a nil node or a resulting time outside
+//     SetMockTime's bounds; errNotConnected before Start; otherwise the
+//     wrapped setmocktime error.
+//
+// Example:
+//
+//	// b now runs three hours ahead of a.
+//	if err := regtest.SkewMockTime(b, 3*time.Hour); err != nil {
+//	    return err
+//	}
+func SkewMockTime(node *Regtest, offset time.Duration) error {
+	return SkewMockTimeContext(context.Background(), node, offset)
+}
+
+// SkewMockTimeContext is the context-aware variant of SkewMockTime.
+func SkewMockTimeContext(ctx context.Context, node *Regtest, offset time.Duration) error {
+	if node == nil {
+		return fmt.Errorf("SkewMockTime: node must not be nil")
+	}
+	target := node.currentMockTime() + int64(offset/time.Second)
+	return node.SetMockTimeContext(ctx, target)
+}
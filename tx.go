@@ -5,8 +5,12 @@ import (
 	"context"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"strings"
 
+	"github.com/btcsuite/btcd/blockchain"
 	"github.com/btcsuite/btcd/btcjson"
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/chaincfg"
@@ -90,6 +94,437 @@ func (r *Regtest) SendToAddressContext(ctx context.Context, addressStr string, s
 	return txid, nil
 }
 
+// SendToAddressWithFeeRate is SendToAddress at a fixed fee rate instead of
+// the wallet's default, so fee assertions hold across Core versions and
+// fallback-fee settings. The rate goes in sendtoaddress's fee_rate
+// argument (Core 21+); btcd's rpcclient only sends positional parameters,
+// so the arguments before it are passed as their defaults.
+//
+// Parameters:
+//   - addressStr: destination regtest address
+//   - sats: amount to send in satoshis (must be > 0)
+//   - feeRateSatPerVB: fee rate in sat/vB (must be > 0; at most 3 decimal
+//     places are honoured)
+//
+// Returns:
+//   - *chainhash.Hash: txid of the broadcast transaction
+//   - error: validation error for a non-positive amount or rate or a bad
+//     address; errNotConnected before Start; otherwise wrapped RPC error
+//     (e.g. a rate below the minimum relay fee, insufficient funds).
+//
+// Example:
+//
+//	txid, err := rt.SendToAddressWithFeeRate(addr, 100_000, 5)
+//	if err != nil {
+//	    return err
+//	}
+//	rate, _ := rt.MempoolTxFeeRate(txid) // ~5
+func (r *Regtest) SendToAddressWithFeeRate(addressStr string, sats int64, feeRateSatPerVB float64) (*chainhash.Hash, error) {
+	return r.SendToAddressWithFeeRateContext(context.Background(), addressStr, sats, feeRateSatPerVB)
+}
+
+// SendToAddressWithFeeRateContext is the context-aware variant of
+// SendToAddressWithFeeRate.
+func (r *Regtest) SendToAddressWithFeeRateContext(ctx context.Context, addressStr string, sats int64, feeRateSatPerVB float64) (*chainhash.Hash, error) {
+	if sats <= 0 {
+		return nil, fmt.Errorf("amount must be greater than 0")
+	}
+	if !(feeRateSatPerVB > 0) || math.IsInf(feeRateSatPerVB, 0) {
+		return nil, fmt.Errorf("fee rate must be a positive sat/vB value, got %v", feeRateSatPerVB)
+	}
+	return r.sendWithFeeRate(ctx, addressStr, sats, feeRateSatPerVB, nil)
+}
+
+// sendWithFeeRate issues sendtoaddress at feeRateSatPerVB. replaceable is
+// the BIP125 signaling argument; nil leaves it to the wallet's -walletrbf.
+func (r *Regtest) sendWithFeeRate(ctx context.Context, addressStr string, sats int64, feeRateSatPerVB float64, replaceable *bool) (*chainhash.Hash, error) {
+	address, err := btcutil.DecodeAddress(addressStr, &chaincfg.RegressionNetParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode address: %w", err)
+	}
+
+	// address, amount, comment, comment_to, subtractfeefromamount,
+	// replaceable, conf_target, estimate_mode, avoid_reuse, fee_rate.
+	raw, err := r.rawRPC(ctx, "sendtoaddress", address.EncodeAddress(), btcutil.Amount(sats).ToBTC(),
+		"", "", false, replaceable, nil, nil, nil, feeRateSatPerVB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send to address: %w", err)
+	}
+	return parseTxid(raw, "sendtoaddress")
+}
+
+// parseTxid decodes a JSON txid string returned by method.
+func parseTxid(raw json.RawMessage, method string) (*chainhash.Hash, error) {
+	var txidStr string
+	if err := json.Unmarshal(raw, &txidStr); err != nil {
+		return nil, fmt.Errorf("unmarshal %s: %w", method, err)
+	}
+	txid, err := chainhash.NewHashFromStr(txidStr)
+	if err != nil {
+		return nil, fmt.Errorf("parse txid %q: %w", txidStr, err)
+	}
+	return txid, nil
+}
+
+// stuckFeeRate is the fee rate of CreateStuckTransaction, in sat/vB: the
+// default minimum relay fee, so the tx enters the mempool but no higher
+// Config.BlockMinTxFee lets it into a block.
+const stuckFeeRate = 1
+
+// CreateStuckTransaction sends sats from the loaded wallet to toAddress in
+// a BIP125-signaling transaction paying 1 sat/vB — enough to relay, and
+// nothing more. On a node started with Config.BlockMinTxFee above 0.00001
+// BTC/kvB (e.g. 0.0001 for 10 sat/vB) the transaction is never mined, so it
+// sits in the mempool until BumpFee, or a replacement, pays the block
+// minimum. Under the default BlockMinTxFee it confirms in the next block
+// like any other.
+//
+// Parameters:
+//   - toAddress: destination regtest address
+//   - sats: amount to send in satoshis (must be > 0)
+//
+// Returns:
+//   - *chainhash.Hash: txid of the low-fee transaction
+//   - error: validation error for a non-positive amount or bad address;
+//     errNotConnected before Start; otherwise wrapped RPC error.
+//
+// Example:
+//
+//	// Config{BlockMinTxFee: 0.0001}
+//	txid, err := rt.CreateStuckTransaction(addr, 100_000)
+//	if err != nil {
+//	    return err
+//	}
+//	_ = rt.Warp(1, miner) // txid is still unconfirmed
+//	bumped, err := rt.BumpFee(txid, 20)
+func (r *Regtest) CreateStuckTransaction(toAddress string, sats int64) (*chainhash.Hash, error) {
+	return r.CreateStuckTransactionContext(context.Background(), toAddress, sats)
+}
+
+// CreateStuckTransactionContext is the context-aware variant of
+// CreateStuckTransaction.
+func (r *Regtest) CreateStuckTransactionContext(ctx context.Context, toAddress string, sats int64) (*chainhash.Hash, error) {
+	if sats <= 0 {
+		return nil, fmt.Errorf("amount must be greater than 0")
+	}
+	replaceable := true
+	return r.sendWithFeeRate(ctx, toAddress, sats, stuckFeeRate, &replaceable)
+}
+
+// BumpFee replaces a wallet transaction still in the mempool with one
+// paying a higher fee (wallet bumpfee), taking the extra from its change.
+// The original must be BIP125-replaceable, or the node must run full RBF
+// (Core 28+).
+//
+// Parameters:
+//   - txid: the wallet transaction to replace (must be non-nil)
+//   - feeRateSatPerVB: fee rate of the replacement in sat/vB; 0 lets the
+//     wallet pick one (its fee estimate or -fallbackfee, at least the
+//     original's rate plus the incremental relay fee)
+//
+// Returns:
+//   - *chainhash.Hash: txid of the replacement
+//   - error: validation error for a nil txid or negative rate;
+//     errNotConnected before Start; otherwise wrapped RPC error (e.g. the
+//     tx is confirmed, not replaceable, or has no change to pay from).
+//
+// Example:
+//
+//	bumped, err := rt.BumpFee(stuck, 20)
+//	if err != nil {
+//	    return err
+//	}
+//	_ = rt.Warp(1, miner) // bumped confirms
+func (r *Regtest) BumpFee(txid *chainhash.Hash, feeRateSatPerVB float64) (*chainhash.Hash, error) {
+	return r.BumpFeeContext(context.Background(), txid, feeRateSatPerVB)
+}
+
+// BumpFeeContext is the context-aware variant of BumpFee.
+func (r *Regtest) BumpFeeContext(ctx context.Context, txid *chainhash.Hash, feeRateSatPerVB float64) (*chainhash.Hash, error) {
+	if txid == nil {
+		return nil, fmt.Errorf("txid must not be nil")
+	}
+	if feeRateSatPerVB < 0 || math.IsNaN(feeRateSatPerVB) || math.IsInf(feeRateSatPerVB, 0) {
+		return nil, fmt.Errorf("fee rate must be a sat/vB value >= 0, got %v", feeRateSatPerVB)
+	}
+	opts := map[string]any{}
+	if feeRateSatPerVB > 0 {
+		opts["fee_rate"] = feeRateSatPerVB
+	}
+	raw, err := r.rawRPC(ctx, "bumpfee", txid.String(), opts)
+	if err != nil {
+		return nil, fmt.Errorf("bumpfee %s: %w", txid, err)
+	}
+	var res struct {
+		TxID json.RawMessage `json:"txid"`
+	}
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return nil, fmt.Errorf("unmarshal bumpfee: %w", err)
+	}
+	return parseTxid(res.TxID, "bumpfee")
+}
+
+// CreateSpendableUTXO sends sats from the loaded wallet to a fresh address
+// of its own, mines one block to miner to confirm it, and returns the
+// outpoint of that output — not the change. The result is a confirmed,
+// wallet-owned UTXO of a known amount, the starting point for tests that
+// build transactions by hand.
+//
+// Parameters:
+//   - sats: amount of the output in satoshis (must be > 0)
+//   - miner: address receiving the confirming block's reward
+//
+// Returns:
+//   - wire.OutPoint: the confirmed output of exactly sats
+//   - error: validation error for non-positive sats; ErrChainFrozen while
+//     the chain is frozen; errNotConnected before Start; otherwise wrapped
+//     address, send, mining or lookup error.
+//
+// Example:
+//
+//	op, err := rt.CreateSpendableUTXO(50_000, minerAddr)
+//	if err != nil {
+//	    return err
+//	}
+//	tx.AddTxIn(wire.NewTxIn(&op, nil, nil))
+func (r *Regtest) CreateSpendableUTXO(sats int64, miner string) (wire.OutPoint, error) {
+	return r.CreateSpendableUTXOContext(context.Background(), sats, miner)
+}
+
+// CreateSpendableUTXOContext is the context-aware variant of
+// CreateSpendableUTXO.
+func (r *Regtest) CreateSpendableUTXOContext(ctx context.Context, sats int64, miner string) (wire.OutPoint, error) {
+	if sats <= 0 {
+		return wire.OutPoint{}, fmt.Errorf("amount must be greater than 0")
+	}
+	addr, err := r.GenerateBech32Context(ctx, "")
+	if err != nil {
+		return wire.OutPoint{}, err
+	}
+	op, err := r.sendAndConfirm(ctx, addr, sats, miner)
+	if err != nil {
+		return wire.OutPoint{}, err
+	}
+	return *op, nil
+}
+
+// allAddressTypes are the wallet address types SendToAllAddressTypes pays,
+// in output-script age order.
+var allAddressTypes = []string{"legacy", "p2sh-segwit", "bech32", "bech32m"}
+
+// SendToAllAddressTypes pays satsEach to a fresh loaded-wallet address of
+// every type — legacy (P2PKH), p2sh-segwit (P2SH-P2WPKH), bech32 (P2WPKH)
+// and bech32m (P2TR) — in a single sendmany, then mines one block to miner
+// to confirm it. The result is a wallet-owned UTXO of each script type for
+// spend-path coverage tests.
+//
+// Parameters:
+//   - satsEach: amount of each output in satoshis (must be > 0)
+//   - miner: address receiving the confirming block's reward
+//
+// Returns:
+//   - *chainhash.Hash: txid of the confirmed transaction
+//   - map[string]string: address type → the address it paid
+//   - error: validation error for non-positive satsEach or empty miner;
+//     ErrChainFrozen while the chain is frozen; errNotConnected before
+//     Start; otherwise wrapped address, send or mining error.
+//
+// Example:
+//
+//	txid, addrs, err := rt.SendToAllAddressTypes(100_000, miner)
+//	if err != nil {
+//	    return err
+//	}
+//	taproot := addrs["bech32m"]
+func (r *Regtest) SendToAllAddressTypes(satsEach int64, miner string) (*chainhash.Hash, map[string]string, error) {
+	return r.SendToAllAddressTypesContext(context.Background(), satsEach, miner)
+}
+
+// SendToAllAddressTypesContext is the context-aware variant of
+// SendToAllAddressTypes.
+func (r *Regtest) SendToAllAddressTypesContext(ctx context.Context, satsEach int64, miner string) (*chainhash.Hash, map[string]string, error) {
+	if satsEach <= 0 {
+		return nil, nil, fmt.Errorf("amount must be greater than 0")
+	}
+	if miner == "" {
+		return nil, nil, fmt.Errorf("miner must be provided")
+	}
+	addrs := make(map[string]string, len(allAddressTypes))
+	amounts := make(map[string]float64, len(allAddressTypes))
+	for _, addrType := range allAddressTypes {
+		addr, err := r.generateAddress(ctx, "", addrType)
+		if err != nil {
+			return nil, nil, err
+		}
+		addrs[addrType] = addr
+		amounts[addr] = btcutil.Amount(satsEach).ToBTC()
+	}
+
+	raw, err := r.rawRPC(ctx, "sendmany", "", amounts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("sendmany: %w", err)
+	}
+	txid, err := parseTxid(raw, "sendmany")
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := r.WarpContext(ctx, 1, miner); err != nil {
+		return nil, nil, fmt.Errorf("confirm tx %s: %w", txid, err)
+	}
+	return txid, addrs, nil
+}
+
+// changeDustLimit is the smallest P2WPKH change output SpendUTXO creates:
+// bitcoind's dust threshold for that output type at the default
+// -dustrelayfee of 3 sat/vB.
+const changeDustLimit = 294
+
+// SpendUTXO spends exactly the output input — no coin selection — paying
+// sats to toAddress and the rest, less the fee, to a fresh bech32 change
+// address of the loaded wallet, then signs with the wallet and broadcasts.
+// The fee is feeRateSatVB times the signed vsize plus one vbyte, rounded
+// up — the extra vbyte covers a signature a byte longer on the final
+// signing pass than on the sizing one; change below the dust limit (294
+// sats) is left to the fee instead. Use it to build
+// exact transaction graphs from UTXOs the test already holds.
+//
+// Parameters:
+//   - input: a wallet-owned, unspent output; a mempool output is fine
+//   - toAddress: recipient address
+//   - sats: amount to pay toAddress (must be > 0)
+//   - feeRateSatVB: fee rate in sat/vB (must be > 0 and finite)
+//
+// Returns:
+//   - *chainhash.Hash: txid of the broadcast transaction
+//   - error: validation error; an error for a spent or unknown input, or
+//     one whose value can't cover sats plus the fee; errNotConnected before
+//     Start; otherwise wrapped signing or broadcast error.
+//
+// Example:
+//
+//	utxo, _ := rt.CreateSpendableUTXO(1_000_000, miner)
+//	txid, err := rt.SpendUTXO(utxo, dest, 400_000, 5)
+func (r *Regtest) SpendUTXO(input wire.OutPoint, toAddress string, sats int64, feeRateSatVB float64) (*chainhash.Hash, error) {
+	return r.SpendUTXOContext(context.Background(), input, toAddress, sats, feeRateSatVB)
+}
+
+// SpendUTXOContext is the context-aware variant of SpendUTXO.
+func (r *Regtest) SpendUTXOContext(ctx context.Context, input wire.OutPoint, toAddress string, sats int64, feeRateSatVB float64) (*chainhash.Hash, error) {
+	if sats <= 0 {
+		return nil, fmt.Errorf("amount must be greater than 0")
+	}
+	if !(feeRateSatVB > 0) || math.IsInf(feeRateSatVB, 0) {
+		return nil, fmt.Errorf("fee rate must be a positive sat/vB value, got %v", feeRateSatVB)
+	}
+	destScript, err := ScriptForAddress(toAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := r.GetTxOutContext(ctx, &input.Hash, input.Index, true)
+	if err != nil {
+		return nil, fmt.Errorf("utxo %s: %w", input, err)
+	}
+	if out == nil {
+		return nil, fmt.Errorf("utxo %s: spent or does not exist", input)
+	}
+	value, err := btcutil.NewAmount(out.Value)
+	if err != nil {
+		return nil, fmt.Errorf("utxo %s: failed to convert amount %v: %w", input, out.Value, err)
+	}
+	if int64(value) < sats {
+		return nil, fmt.Errorf("utxo %s holds %v, less than the %v to send", input, value, btcutil.Amount(sats))
+	}
+
+	raw, err := r.rawRPC(ctx, "getrawchangeaddress", "bech32")
+	if err != nil {
+		return nil, fmt.Errorf("getrawchangeaddress: %w", err)
+	}
+	var changeAddr string
+	if err := json.Unmarshal(raw, &changeAddr); err != nil {
+		return nil, fmt.Errorf("unmarshal getrawchangeaddress: %w", err)
+	}
+	changeScript, err := ScriptForAddress(changeAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := value.ToBTC()
+	prevTxns := []btcjson.RawTxWitnessInput{{
+		Txid:         input.Hash.String(),
+		Vout:         input.Index,
+		ScriptPubKey: out.ScriptPubKey.Hex,
+		Amount:       &amount,
+	}}
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(&input, nil, nil))
+	tx.AddTxOut(wire.NewTxOut(sats, destScript))
+	tx.AddTxOut(wire.NewTxOut(int64(value)-sats, changeScript))
+
+	// Sign once to learn the real vsize, then set the change and sign
+	// again.
+	signed, err := r.signWithWallet(ctx, tx, prevTxns)
+	if err != nil {
+		return nil, fmt.Errorf("utxo %s: %w", input, err)
+	}
+	fee := int64(math.Ceil(float64(txVirtualSize(signed)+1) * feeRateSatVB))
+	change := int64(value) - sats - fee
+	if change < 0 {
+		return nil, fmt.Errorf("utxo %s holds %v, not enough for %v plus a %v fee",
+			input, value, btcutil.Amount(sats), btcutil.Amount(fee))
+	}
+	if change < changeDustLimit {
+		tx.TxOut = tx.TxOut[:1]
+	} else {
+		tx.TxOut[1].Value = change
+	}
+	signed, err = r.signWithWallet(ctx, tx, prevTxns)
+	if err != nil {
+		return nil, fmt.Errorf("utxo %s: %w", input, err)
+	}
+	return r.BroadcastTransactionContext(ctx, signed)
+}
+
+// sendAndConfirm sends sats to address from the loaded wallet, mines one
+// block to miner, and returns the outpoint paying address exactly sats.
+func (r *Regtest) sendAndConfirm(ctx context.Context, address string, sats int64, miner string) (*wire.OutPoint, error) {
+	script, err := ScriptForAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	txid, err := r.SendToAddressContext(ctx, address, sats)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.WarpContext(ctx, 1, miner); err != nil {
+		return nil, fmt.Errorf("confirm funding tx %s: %w", txid, err)
+	}
+
+	resp, err := r.rawRPC(ctx, "getrawtransaction", txid.String())
+	if err != nil {
+		return nil, fmt.Errorf("getrawtransaction %s: %w", txid, err)
+	}
+	var txHex string
+	if err := json.Unmarshal(resp, &txHex); err != nil {
+		return nil, fmt.Errorf("unmarshal getrawtransaction: %w", err)
+	}
+	txBytes, err := hex.DecodeString(txHex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode tx hex: %w", err)
+	}
+	var tx wire.MsgTx
+	if err := tx.Deserialize(bytes.NewReader(txBytes)); err != nil {
+		return nil, fmt.Errorf("failed to deserialize funding tx: %w", err)
+	}
+	for i := uint32(0); int(i) < len(tx.TxOut); i++ {
+		out := tx.TxOut[i]
+		if bytes.Equal(out.PkScript, script) && out.Value == sats {
+			return wire.NewOutPoint(txid, i), nil
+		}
+	}
+	return nil, fmt.Errorf("funding tx %s has no %d sat output to %s", txid, sats, address)
+}
+
 // GetTxOut retrieves information about a specific transaction output (UTXO).
 // This is useful for checking if an output exists, is unspent, and getting
 // details about its value and script.
@@ -141,6 +576,233 @@ func (r *Regtest) GetTxOutContext(ctx context.Context, txid *chainhash.Hash, vou
 	return res, nil
 }
 
+// ErrNotCoinbase is returned by CoinbaseMaturityRemaining when the output
+// exists but was not created by a coinbase transaction.
+var ErrNotCoinbase = errors.New("output is not a coinbase output")
+
+// CoinbaseMaturityRemaining returns how many more blocks must be mined
+// before the coinbase output op can be spent. A coinbase output is
+// spendable by a transaction in block coinbaseHeight+100 or later; since a
+// mempool transaction is validated against the next block, the output can
+// be broadcast once it has 100 confirmations, so the result is
+// max(0, 100 - confirmations).
+//
+// Parameters:
+//   - op: outpoint of a confirmed coinbase output
+//
+// Returns:
+//   - int: blocks left to mine before op is spendable; 0 when mature
+//   - error: ErrNotCoinbase for a non-coinbase output; an error for a spent
+//     or unknown output; errNotConnected before Start; otherwise wrapped
+//     RPC error.
+//
+// Example:
+//
+//	left, err := rt.CoinbaseMaturityRemaining(wire.OutPoint{Hash: *cbTxid})
+//	if err != nil {
+//	    return err
+//	}
+//	if left > 0 {
+//	    rt.Warp(int64(left), miner)
+//	}
+func (r *Regtest) CoinbaseMaturityRemaining(op wire.OutPoint) (int, error) {
+	return r.CoinbaseMaturityRemainingContext(context.Background(), op)
+}
+
+// CoinbaseMaturityRemainingContext is the context-aware variant of
+// CoinbaseMaturityRemaining.
+func (r *Regtest) CoinbaseMaturityRemainingContext(ctx context.Context, op wire.OutPoint) (int, error) {
+	out, err := r.GetTxOutContext(ctx, &op.Hash, op.Index, false)
+	if err != nil {
+		return 0, fmt.Errorf("utxo %s: %w", op, err)
+	}
+	if out == nil {
+		return 0, fmt.Errorf("utxo %s: spent or does not exist", op)
+	}
+	if !out.Coinbase {
+		return 0, fmt.Errorf("utxo %s: %w", op, ErrNotCoinbase)
+	}
+	remaining := coinbaseMaturity - out.Confirmations
+	if remaining <= 0 {
+		return 0, nil
+	}
+	return int(remaining), nil
+}
+
+// OutputScript returns the raw scriptPubKey bytes of the unspent output op,
+// saving the hex decode of GetTxOut's ScriptPubKey.Hex when building or
+// inspecting a manual spend.
+//
+// Parameters:
+//   - op: outpoint of the output
+//   - includeMempool: also consider outputs created, and spends made, by
+//     mempool transactions
+//
+// Returns:
+//   - []byte: the output's scriptPubKey
+//   - error: an error for a spent or unknown output; errNotConnected before
+//     Start; otherwise wrapped RPC or hex-decode error.
+//
+// Example:
+//
+//	script, err := rt.OutputScript(utxo, false)
+//	if err != nil {
+//	    return err
+//	}
+//	class := txscript.GetScriptClass(script)
+func (r *Regtest) OutputScript(op wire.OutPoint, includeMempool bool) ([]byte, error) {
+	return r.OutputScriptContext(context.Background(), op, includeMempool)
+}
+
+// OutputScriptContext is the context-aware variant of OutputScript.
+func (r *Regtest) OutputScriptContext(ctx context.Context, op wire.OutPoint, includeMempool bool) ([]byte, error) {
+	out, err := r.GetTxOutContext(ctx, &op.Hash, op.Index, includeMempool)
+	if err != nil {
+		return nil, fmt.Errorf("utxo %s: %w", op, err)
+	}
+	if out == nil {
+		return nil, fmt.Errorf("utxo %s: spent or does not exist", op)
+	}
+	script, err := hex.DecodeString(out.ScriptPubKey.Hex)
+	if err != nil {
+		return nil, fmt.Errorf("utxo %s: failed to decode scriptPubKey: %w", op, err)
+	}
+	return script, nil
+}
+
+// GetSpentOutputs returns the outputs tx's inputs consume, in input order,
+// with their values — enough to compute the fee of any transaction, wallet
+// or not, as the sum of the returned amounts minus tx's outputs. tx need not
+// be broadcast: its prevouts may be unspent, in the mempool, or already
+// spent (by tx itself once confirmed).
+//
+// Each prevout is read from its funding transaction via getrawtransaction,
+// which finds confirmed transactions through the -txindex the manager
+// script always enables; when that fails (e.g. -txindex=0 in ExtraArgs) it
+// falls back to gettxout, which only sees unspent outputs. A coinbase
+// transaction spends nothing and yields an empty slice.
+//
+// Parameters:
+//   - tx: transaction whose inputs to resolve (must be non-nil)
+//
+// Returns:
+//   - []UTXO: one entry per input; Confirmations counts the funding tx's
+//     confirmations (0 in the mempool)
+//   - error: validation error for a nil tx; errNotConnected before Start;
+//     an error naming the input whose prevout can't be found; otherwise
+//     wrapped RPC or decode error.
+//
+// Example:
+//
+//	spent, err := rt.GetSpentOutputs(tx)
+//	if err != nil {
+//	    return err
+//	}
+//	var fee btcutil.Amount
+//	for _, u := range spent {
+//	    fee += u.Amount
+//	}
+//	for _, out := range tx.TxOut {
+//	    fee -= btcutil.Amount(out.Value)
+//	}
+func (r *Regtest) GetSpentOutputs(tx *wire.MsgTx) ([]UTXO, error) {
+	return r.GetSpentOutputsContext(context.Background(), tx)
+}
+
+// GetSpentOutputsContext is the context-aware variant of GetSpentOutputs.
+func (r *Regtest) GetSpentOutputsContext(ctx context.Context, tx *wire.MsgTx) ([]UTXO, error) {
+	if tx == nil {
+		return nil, fmt.Errorf("tx must not be nil")
+	}
+	if _, err := r.lockedClient(); err != nil {
+		return nil, err
+	}
+	if blockchain.IsCoinBaseTx(tx) {
+		return []UTXO{}, nil
+	}
+	funding := make(map[chainhash.Hash]*fundingTx)
+	out := make([]UTXO, 0, len(tx.TxIn))
+	for i, in := range tx.TxIn {
+		op := in.PreviousOutPoint
+		u, err := r.spentOutput(ctx, op, funding)
+		if err != nil {
+			return nil, fmt.Errorf("input %d (%s): %w", i, op, err)
+		}
+		out = append(out, u)
+	}
+	return out, nil
+}
+
+// fundingTx is the part of a verbose getrawtransaction spentOutput reads.
+type fundingTx struct {
+	Vin []struct {
+		Coinbase string `json:"coinbase"`
+	} `json:"vin"`
+	Vout []struct {
+		Value        float64 `json:"value"`
+		ScriptPubKey struct {
+			Address string `json:"address"`
+		} `json:"scriptPubKey"`
+	} `json:"vout"`
+	Confirmations int64 `json:"confirmations"`
+}
+
+// spentOutput resolves op from its funding transaction, or from gettxout
+// when getrawtransaction can't find that. Lookups are cached in funding,
+// misses as nil.
+func (r *Regtest) spentOutput(ctx context.Context, op wire.OutPoint, funding map[chainhash.Hash]*fundingTx) (UTXO, error) {
+	prev, ok := funding[op.Hash]
+	if !ok {
+		resp, err := r.rawRPC(ctx, "getrawtransaction", op.Hash.String(), true)
+		if err == nil {
+			prev = new(fundingTx)
+			if err := json.Unmarshal(resp, prev); err != nil {
+				return UTXO{}, fmt.Errorf("unmarshal getrawtransaction: %w", err)
+			}
+		} else if errors.Is(err, errNotConnected) || ctx.Err() != nil {
+			return UTXO{}, err
+		}
+		funding[op.Hash] = prev
+	}
+
+	if prev == nil {
+		txOut, err := r.GetTxOutContext(ctx, &op.Hash, op.Index, true)
+		if err != nil {
+			return UTXO{}, err
+		}
+		if txOut == nil {
+			return UTXO{}, fmt.Errorf("prevout not found (no -txindex and not unspent)")
+		}
+		amt, err := btcutil.NewAmount(txOut.Value)
+		if err != nil {
+			return UTXO{}, fmt.Errorf("converting prevout value %v: %w", txOut.Value, err)
+		}
+		return UTXO{
+			OutPoint:      op,
+			Amount:        amt,
+			Coinbase:      txOut.Coinbase,
+			Confirmations: txOut.Confirmations,
+			Address:       txOut.ScriptPubKey.Address,
+		}, nil
+	}
+
+	if int(op.Index) >= len(prev.Vout) {
+		return UTXO{}, fmt.Errorf("funding tx has only %d outputs", len(prev.Vout))
+	}
+	vout := prev.Vout[op.Index]
+	amt, err := btcutil.NewAmount(vout.Value)
+	if err != nil {
+		return UTXO{}, fmt.Errorf("converting prevout value %v: %w", vout.Value, err)
+	}
+	return UTXO{
+		OutPoint:      op,
+		Amount:        amt,
+		Coinbase:      len(prev.Vin) > 0 && prev.Vin[0].Coinbase != "",
+		Confirmations: prev.Confirmations,
+		Address:       vout.ScriptPubKey.Address,
+	}, nil
+}
+
 // ScanTxOutSetForAddress scans the entire UTXO set for outputs to a specific address.
 // This operation searches through all unspent transaction outputs on the blockchain
 // to find those belonging to the given address. Unlike wallet-based methods, this
@@ -213,13 +875,22 @@ func (r *Regtest) SignRawTransactionWithWallet(tx *wire.MsgTx) (*wire.MsgTx, err
 // SignRawTransactionWithWalletContext is the context-aware variant of
 // SignRawTransactionWithWallet.
 func (r *Regtest) SignRawTransactionWithWalletContext(ctx context.Context, tx *wire.MsgTx) (*wire.MsgTx, error) {
+	return r.signWithWallet(ctx, tx, nil)
+}
+
+// signWithWallet issues signrawtransactionwithwallet, passing prevTxns for
+// inputs the node can't look up itself (e.g. parents not yet broadcast).
+func (r *Regtest) signWithWallet(ctx context.Context, tx *wire.MsgTx, prevTxns []btcjson.RawTxWitnessInput) (*wire.MsgTx, error) {
 	var buf bytes.Buffer
 	if err := tx.Serialize(&buf); err != nil {
 		return nil, fmt.Errorf("failed to serialize transaction: %w", err)
 	}
-	txHex := hex.EncodeToString(buf.Bytes())
+	args := []any{hex.EncodeToString(buf.Bytes())}
+	if len(prevTxns) > 0 {
+		args = append(args, prevTxns)
+	}
 
-	resp, err := r.rawRPC(ctx, "signrawtransactionwithwallet", txHex)
+	resp, err := r.rawRPC(ctx, "signrawtransactionwithwallet", args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to sign transaction: %w", err)
 	}
@@ -248,6 +919,92 @@ func (r *Regtest) SignRawTransactionWithWalletContext(ctx context.Context, tx *w
 	return &signedTx, nil
 }
 
+// SignRawTransactionWithKey signs a raw transaction with explicit private
+// keys via signrawtransactionwithkey, independent of any wallet — the
+// external-key signing path. Inputs the keys can't sign are left untouched
+// and reported through the completeness flag rather than as an error, so a
+// partially signed multisig can be passed on to the next signer.
+//
+// prevTxns takes btcjson.RawTxWitnessInput rather than btcjson.RawTxInput
+// because segwit and taproot signatures commit to the spent amount, which
+// RawTxInput can't carry. Entries are only needed for outputs the node
+// can't look up itself (e.g. unconfirmed outputs not in its mempool);
+// scriptPubKey and amount are required, redeemScript/witnessScript for
+// P2SH/P2WSH.
+//
+// Parameters:
+//   - tx: transaction to sign (must be non-nil)
+//   - wifs: private keys to sign with (at least one)
+//   - prevTxns: previous outputs being spent; nil when the node can find them
+//
+// Returns:
+//   - *wire.MsgTx: the (possibly partially) signed transaction
+//   - bool: true when every input is fully signed
+//   - error: validation error for nil tx or no keys; errNotConnected before
+//     Start; otherwise wrapped RPC or decode error.
+//
+// Example:
+//
+//	wif, _ := btcutil.DecodeWIF("cV...")
+//	amount := 0.5
+//	signed, complete, err := rt.SignRawTransactionWithKey(tx, []*btcutil.WIF{wif},
+//	    []btcjson.RawTxWitnessInput{{Txid: prevTxid, Vout: 0, ScriptPubKey: spkHex, Amount: &amount}})
+//	if err != nil {
+//	    return err
+//	}
+//	if !complete {
+//	    return fmt.Errorf("missing signatures")
+//	}
+func (r *Regtest) SignRawTransactionWithKey(tx *wire.MsgTx, wifs []*btcutil.WIF, prevTxns []btcjson.RawTxWitnessInput) (*wire.MsgTx, bool, error) {
+	return r.SignRawTransactionWithKeyContext(context.Background(), tx, wifs, prevTxns)
+}
+
+// SignRawTransactionWithKeyContext is the context-aware variant of
+// SignRawTransactionWithKey.
+func (r *Regtest) SignRawTransactionWithKeyContext(ctx context.Context, tx *wire.MsgTx, wifs []*btcutil.WIF, prevTxns []btcjson.RawTxWitnessInput) (*wire.MsgTx, bool, error) {
+	if tx == nil {
+		return nil, false, fmt.Errorf("tx must not be nil")
+	}
+	if len(wifs) == 0 {
+		return nil, false, fmt.Errorf("at least one private key required")
+	}
+	keys := make([]string, len(wifs))
+	for i, wif := range wifs {
+		if wif == nil {
+			return nil, false, fmt.Errorf("wifs[%d] is nil", i)
+		}
+		keys[i] = wif.String()
+	}
+	if prevTxns == nil {
+		prevTxns = []btcjson.RawTxWitnessInput{}
+	}
+
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		return nil, false, fmt.Errorf("failed to serialize transaction: %w", err)
+	}
+	resp, err := r.rawRPC(ctx, "signrawtransactionwithkey", hex.EncodeToString(buf.Bytes()), keys, prevTxns)
+	if err != nil {
+		return nil, false, fmt.Errorf("signrawtransactionwithkey: %w", err)
+	}
+	var result struct {
+		Hex      string `json:"hex"`
+		Complete bool   `json:"complete"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, false, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	signedTxBytes, err := hex.DecodeString(result.Hex)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to decode signed tx hex: %w", err)
+	}
+	var signedTx wire.MsgTx
+	if err := signedTx.Deserialize(bytes.NewReader(signedTxBytes)); err != nil {
+		return nil, false, fmt.Errorf("failed to deserialize signed tx: %w", err)
+	}
+	return &signedTx, result.Complete, nil
+}
+
 // BroadcastTransaction broadcasts a signed transaction to the Bitcoin network
 // and returns the resulting transaction ID. See BroadcastTransactionContext
 // for details on why the raw sendrawtransaction RPC is used.
@@ -255,33 +1012,150 @@ func (r *Regtest) BroadcastTransaction(tx *wire.MsgTx) (*chainhash.Hash, error)
 	return r.BroadcastTransactionContext(context.Background(), tx)
 }
 
-// BroadcastTransactionContext is the context-aware variant of BroadcastTransaction.
-//
-// Uses the raw sendrawtransaction RPC rather than btcd's typed
-// SendRawTransaction wrapper, which fails against Bitcoin Core 26+ because the
-// "warnings" field changed from string to array.
-func (r *Regtest) BroadcastTransactionContext(ctx context.Context, tx *wire.MsgTx) (*chainhash.Hash, error) {
-	var buf bytes.Buffer
-	if err := tx.Serialize(&buf); err != nil {
-		return nil, fmt.Errorf("failed to serialize transaction: %w", err)
+// BroadcastTransactionContext is the context-aware variant of BroadcastTransaction.
+//
+// Uses the raw sendrawtransaction RPC rather than btcd's typed
+// SendRawTransaction wrapper, which fails against Bitcoin Core 26+ because the
+// "warnings" field changed from string to array.
+func (r *Regtest) BroadcastTransactionContext(ctx context.Context, tx *wire.MsgTx) (*chainhash.Hash, error) {
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		return nil, fmt.Errorf("failed to serialize transaction: %w", err)
+	}
+	txHex := hex.EncodeToString(buf.Bytes())
+
+	resp, err := r.rawRPC(ctx, "sendrawtransaction", txHex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to broadcast transaction: %w", err)
+	}
+
+	var txidStr string
+	if err := json.Unmarshal(resp, &txidStr); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal txid: %w", err)
+	}
+
+	txid, err := chainhash.NewHashFromStr(txidStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse txid: %w", err)
+	}
+	return txid, nil
+}
+
+// ExpectReject broadcasts tx via sendrawtransaction expecting bitcoind to
+// refuse it, and checks the reject reason contains wantReason. It
+// standardizes consensus/policy negative tests: nil means "rejected for
+// exactly the reason I meant", not merely "rejected".
+//
+// A tx that bitcoind accepts is now in the mempool; ExpectReject reports it
+// as an error but doesn't undo the broadcast.
+//
+// Parameters:
+//   - tx: transaction to broadcast (must be non-nil)
+//   - wantReason: substring the reject message must contain, e.g.
+//     "bad-txns-inputs-missingorspent", "min relay fee not met" (must be
+//     non-empty)
+//
+// Returns:
+//   - error: nil when bitcoind rejected tx with a message containing
+//     wantReason; an error when it accepted tx or rejected it for another
+//     reason (the message quotes the actual reason); validation error for
+//     bad arguments; errNotConnected before Start; transport errors are
+//     returned as-is.
+//
+// Example:
+//
+//	if err := rt.ExpectReject(doubleSpend, "bad-txns-inputs-missingorspent"); err != nil {
+//	    t.Fatal(err)
+//	}
+func (r *Regtest) ExpectReject(tx *wire.MsgTx, wantReason string) error {
+	return r.ExpectRejectContext(context.Background(), tx, wantReason)
+}
+
+// ExpectRejectContext is the context-aware variant of ExpectReject.
+func (r *Regtest) ExpectRejectContext(ctx context.Context, tx *wire.MsgTx, wantReason string) error {
+	if tx == nil {
+		return fmt.Errorf("tx must not be nil")
+	}
+	if wantReason == "" {
+		return fmt.Errorf("wantReason must not be empty")
+	}
+	txid, err := r.BroadcastTransactionContext(ctx, tx)
+	if err == nil {
+		return fmt.Errorf("tx %s was accepted, want rejection containing %q", txid, wantReason)
+	}
+	var rpcErr *btcjson.RPCError
+	if !errors.As(err, &rpcErr) {
+		return err
+	}
+	if !strings.Contains(rpcErr.Message, wantReason) {
+		return fmt.Errorf("tx %s rejected with %q, want reason containing %q", tx.TxHash(), rpcErr.Message, wantReason)
+	}
+	return nil
+}
+
+// alreadyBroadcast lists sendrawtransaction reject messages meaning the
+// transaction (or one with the same txid) is already in the mempool or the
+// chain.
+var alreadyBroadcast = []string{
+	"txn-already-in-mempool",
+	"txn-already-known",
+	"txn-same-nonwitness-data-in-mempool",
+	"already in block chain",
+	"already in utxo set",
+}
+
+// BroadcastIdempotent is BroadcastTransaction made safe to retry: when
+// bitcoind reports tx as already known — in the mempool, or confirmed with
+// outputs still in the UTXO set — it returns tx's txid instead of an error.
+// A confirmed tx whose outputs are all spent looks like a double spend to
+// sendrawtransaction ("bad-txns-inputs-missingorspent"); that case is
+// resolved through the transaction index, which the node always runs with,
+// and also counts as success.
+//
+// Parameters:
+//   - tx: signed transaction to broadcast (must be non-nil)
+//
+// Returns:
+//   - *chainhash.Hash: tx's txid, computed locally with ComputeTxID when
+//     bitcoind already had it
+//   - error: validation error for nil tx; errNotConnected before Start;
+//     otherwise the broadcast error for genuine rejections.
+//
+// Example:
+//
+//	txid, err := rt.BroadcastIdempotent(tx) // safe after a timed-out attempt
+//	if err != nil {
+//	    return err
+//	}
+func (r *Regtest) BroadcastIdempotent(tx *wire.MsgTx) (*chainhash.Hash, error) {
+	return r.BroadcastIdempotentContext(context.Background(), tx)
+}
+
+// BroadcastIdempotentContext is the context-aware variant of
+// BroadcastIdempotent.
+func (r *Regtest) BroadcastIdempotentContext(ctx context.Context, tx *wire.MsgTx) (*chainhash.Hash, error) {
+	if tx == nil {
+		return nil, fmt.Errorf("tx must not be nil")
 	}
-	txHex := hex.EncodeToString(buf.Bytes())
-
-	resp, err := r.rawRPC(ctx, "sendrawtransaction", txHex)
-	if err != nil {
-		return nil, fmt.Errorf("failed to broadcast transaction: %w", err)
+	txid, err := r.BroadcastTransactionContext(ctx, tx)
+	if err == nil {
+		return txid, nil
 	}
-
-	var txidStr string
-	if err := json.Unmarshal(resp, &txidStr); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal txid: %w", err)
+	var rpcErr *btcjson.RPCError
+	if !errors.As(err, &rpcErr) {
+		return nil, err
 	}
-
-	txid, err := chainhash.NewHashFromStr(txidStr)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse txid: %w", err)
+	for _, msg := range alreadyBroadcast {
+		if strings.Contains(rpcErr.Message, msg) {
+			return ComputeTxID(tx), nil
+		}
 	}
-	return txid, nil
+	if strings.Contains(rpcErr.Message, "bad-txns-inputs-missingorspent") {
+		if _, _, lookupErr := r.txDepth(ctx, ComputeTxID(tx)); lookupErr == nil {
+			return ComputeTxID(tx), nil
+		}
+	}
+	return nil, err
 }
 
 // CreateRawTransaction builds an unsigned transaction spending the given
@@ -325,6 +1199,33 @@ func (r *Regtest) CreateRawTransactionContext(ctx context.Context, inputs []btcj
 	return tx, nil
 }
 
+// InputFromOutPoint converts op to the btcjson.TransactionInput that
+// CreateRawTransaction takes. No RPC is issued.
+//
+// Example:
+//
+//	tx, err := rt.CreateRawTransaction(
+//	    []btcjson.TransactionInput{regtest.InputFromOutPoint(utxo)},
+//	    amounts, nil,
+//	)
+func InputFromOutPoint(op wire.OutPoint) btcjson.TransactionInput {
+	return btcjson.TransactionInput{Txid: op.Hash.String(), Vout: op.Index}
+}
+
+// InputsFromOutPoints converts each outpoint with InputFromOutPoint,
+// preserving order. No RPC is issued.
+//
+// Example:
+//
+//	tx, err := rt.CreateRawTransaction(regtest.InputsFromOutPoints(utxos), amounts, nil)
+func InputsFromOutPoints(ops []wire.OutPoint) []btcjson.TransactionInput {
+	inputs := make([]btcjson.TransactionInput, 0, len(ops))
+	for _, op := range ops {
+		inputs = append(inputs, InputFromOutPoint(op))
+	}
+	return inputs
+}
+
 // DecodeRawTransaction returns bitcoind's verbose decoding of a transaction:
 // txid/wtxid, version, locktime, and per-input/output details.
 //
@@ -560,3 +1461,584 @@ func (r *Regtest) TestMempoolAcceptContext(ctx context.Context, txs ...*wire.Msg
 	}
 	return out, nil
 }
+
+// SweepToScript spends every confirmed, spendable UTXO in the loaded wallet to
+// a single output paying script, less a fee at feeRateSatVB. Unlike
+// SendToAddress the destination is a raw scriptPubKey, so bare scripts with no
+// address form (covenant deposits, bare multisig, OP_RETURN-adjacent
+// experiments) can be funded directly. Nonstandard scripts are only relayed
+// when the node runs with Config.AcceptNonstdTxn.
+//
+// The fee is computed from the signed transaction's vsize plus one vbyte per
+// input, covering the occasional extra byte a re-signed ECDSA signature
+// needs, so the final fee rate is never below feeRateSatVB.
+//
+// Parameters:
+//   - script: destination scriptPubKey (must be non-empty)
+//   - feeRateSatVB: fee rate in sat/vB (must be > 0)
+//
+// Returns:
+//   - *chainhash.Hash: txid of the broadcast sweep
+//   - error: validation error for bad arguments or an empty wallet;
+//     errNotConnected before Start; otherwise wrapped RPC error.
+//
+// Example:
+//
+//	script, _ := txscript.NewScriptBuilder().AddOp(txscript.OP_TRUE).Script()
+//	txid, err := rt.SweepToScript(script, 2)
+func (r *Regtest) SweepToScript(script []byte, feeRateSatVB float64) (*chainhash.Hash, error) {
+	return r.SweepToScriptContext(context.Background(), script, feeRateSatVB)
+}
+
+// SweepToScriptContext is the context-aware variant of SweepToScript.
+func (r *Regtest) SweepToScriptContext(ctx context.Context, script []byte, feeRateSatVB float64) (*chainhash.Hash, error) {
+	if len(script) == 0 {
+		return nil, fmt.Errorf("script is empty")
+	}
+	if !(feeRateSatVB > 0) || math.IsInf(feeRateSatVB, 0) {
+		return nil, fmt.Errorf("fee rate must be a positive sat/vB value, got %v", feeRateSatVB)
+	}
+
+	resp, err := r.rawRPC(ctx, "listunspent")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unspent: %w", err)
+	}
+	var utxos []struct {
+		TxID      string  `json:"txid"`
+		Vout      uint32  `json:"vout"`
+		Amount    float64 `json:"amount"`
+		Spendable bool    `json:"spendable"`
+	}
+	if err := json.Unmarshal(resp, &utxos); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal listunspent: %w", err)
+	}
+
+	tx := wire.NewMsgTx(2)
+	var total btcutil.Amount
+	for _, u := range utxos {
+		if !u.Spendable {
+			continue
+		}
+		hash, err := chainhash.NewHashFromStr(u.TxID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse utxo txid %q: %w", u.TxID, err)
+		}
+		amt, err := btcutil.NewAmount(u.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert utxo amount %v: %w", u.Amount, err)
+		}
+		tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(hash, u.Vout), nil, nil))
+		total += amt
+	}
+	if len(tx.TxIn) == 0 {
+		return nil, fmt.Errorf("wallet has no spendable UTXOs to sweep")
+	}
+	tx.AddTxOut(wire.NewTxOut(int64(total), script))
+
+	// Sign once at zero fee to learn the real vsize, then set the output and
+	// sign again.
+	signed, err := r.SignRawTransactionWithWalletContext(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	vsize := txVirtualSize(signed) + int64(len(tx.TxIn))
+	fee := btcutil.Amount(math.Ceil(float64(vsize) * feeRateSatVB))
+	if fee >= total {
+		return nil, fmt.Errorf("fee %v exceeds swept total %v", fee, total)
+	}
+	tx.TxOut[0].Value = int64(total - fee)
+
+	signed, err = r.SignRawTransactionWithWalletContext(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	return r.BroadcastTransactionContext(ctx, signed)
+}
+
+// ErrBlockFilterIndexDisabled is returned by ScanBlocks when the node runs
+// without -blockfilterindex.
+var ErrBlockFilterIndexDisabled = errors.New("block filter index is not enabled (start bitcoind with -blockfilterindex=1)")
+
+// ScanBlocksResult is the result of a completed scanblocks scan.
+type ScanBlocksResult struct {
+	// FromHeight is the first height scanned.
+	FromHeight int64 `json:"from_height"`
+	// ToHeight is the last height scanned.
+	ToHeight int64 `json:"to_height"`
+	// RelevantBlocks lists the hashes of blocks whose filter matched any
+	// descriptor. Filters can give false positives; fetch the block to
+	// confirm a match.
+	RelevantBlocks []string `json:"relevant_blocks"`
+	// Completed is true when the scan covered the whole range (Bitcoin
+	// Core 26+; always false on 25.x, which doesn't report it).
+	Completed bool `json:"completed"`
+}
+
+// ScanBlocks searches the BIP158 block filters between startHeight and
+// stopHeight for blocks relevant to descriptors, via scanblocks. Unlike
+// ScanTxOutSetForAddress, which only sees the current UTXO set, it finds
+// historical blocks — including ones whose matching outputs were since
+// spent — the way a light client locates blocks to download.
+//
+// Needs Bitcoin Core 25.0+ and a node started with -blockfilterindex=1
+// (e.g. Config.ExtraArgs). scanblocks waits for the filter index to catch
+// up with the tip before scanning.
+//
+// Parameters:
+//   - descriptors: output descriptors to match, e.g. "addr(bcrt1q...)" or
+//     "wpkh(...)" (must be non-empty)
+//   - startHeight: first height to scan (must be >= 0)
+//   - stopHeight: last height to scan (must be >= startHeight), or -1 for
+//     the chain tip
+//
+// Returns:
+//   - *ScanBlocksResult: scanned range and matching block hashes
+//   - error: validation error for bad arguments; ErrUnsupportedVersion on
+//     nodes older than 25.0; ErrBlockFilterIndexDisabled without
+//     -blockfilterindex; errNotConnected before Start; otherwise wrapped
+//     RPC or unmarshal error.
+//
+// Example:
+//
+//	res, err := rt.ScanBlocks([]string{"addr(" + addr + ")"}, 0, -1)
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Println("relevant blocks:", res.RelevantBlocks)
+func (r *Regtest) ScanBlocks(descriptors []string, startHeight, stopHeight int) (*ScanBlocksResult, error) {
+	return r.ScanBlocksContext(context.Background(), descriptors, startHeight, stopHeight)
+}
+
+// ScanBlocksContext is the context-aware variant of ScanBlocks.
+func (r *Regtest) ScanBlocksContext(ctx context.Context, descriptors []string, startHeight, stopHeight int) (*ScanBlocksResult, error) {
+	if len(descriptors) == 0 {
+		return nil, fmt.Errorf("at least one descriptor is required")
+	}
+	if startHeight < 0 {
+		return nil, fmt.Errorf("startHeight must be >= 0, got %d", startHeight)
+	}
+	if stopHeight != -1 && stopHeight < startHeight {
+		return nil, fmt.Errorf("stopHeight %d is below startHeight %d", stopHeight, startHeight)
+	}
+	if err := r.requireVersion(ctx, 250000, "scanblocks"); err != nil {
+		return nil, err
+	}
+
+	// getindexinfo omits disabled indexes; check up front so the caller gets
+	// a sentinel instead of bitcoind's generic "Index is not enabled".
+	resp, err := r.rawRPC(ctx, "getindexinfo", "basic block filter index")
+	if err != nil {
+		return nil, fmt.Errorf("getindexinfo: %w", err)
+	}
+	var indexes map[string]json.RawMessage
+	if err := json.Unmarshal(resp, &indexes); err != nil {
+		return nil, fmt.Errorf("unmarshal getindexinfo: %w", err)
+	}
+	if _, ok := indexes["basic block filter index"]; !ok {
+		return nil, ErrBlockFilterIndexDisabled
+	}
+
+	args := []any{"start", descriptors, startHeight}
+	if stopHeight != -1 {
+		args = append(args, stopHeight)
+	}
+	resp, err = r.rawRPC(ctx, "scanblocks", args...)
+	if err != nil {
+		return nil, fmt.Errorf("scanblocks: %w", err)
+	}
+	var res ScanBlocksResult
+	if err := json.Unmarshal(resp, &res); err != nil {
+		return nil, fmt.Errorf("unmarshal scanblocks: %w", err)
+	}
+	return &res, nil
+}
+
+// ComputeTxID returns the txid of tx computed locally: the double-SHA256 of
+// its serialization without witness data, in the byte order chainhash
+// prints as the conventional big-endian hex. No RPC is issued, so dependent
+// transactions can be chained together offline before any of them is
+// broadcast. Because witnesses are excluded, the txid is stable across
+// signing for segwit inputs.
+//
+// Example:
+//
+//	parent := wire.NewMsgTx(2) // ... inputs and outputs
+//	child := wire.NewMsgTx(2)
+//	child.AddTxIn(wire.NewTxIn(wire.NewOutPoint(regtest.ComputeTxID(parent), 0), nil, nil))
+func ComputeTxID(tx *wire.MsgTx) *chainhash.Hash {
+	h := tx.TxHash()
+	return &h
+}
+
+// CreateTxChain builds length signed transactions where each spends output 0
+// of its parent, starting from fundingUTXO. Every transaction has a single
+// input and a single output paying back to the funding output's
+// scriptPubKey, so the loaded wallet can sign the whole chain. Children are
+// linked to their parents with ComputeTxID and signed with the parent's
+// output passed as a prevtx, so nothing is broadcast: submit the returned
+// transactions in order (or as a package) to exercise mempool
+// ancestor/descendant limits.
+//
+// Each link pays a fee of its signed vsize (plus one vbyte of signature
+// slack) times feeRateSatVB, so the output value shrinks along the chain.
+//
+// Parameters:
+//   - fundingUTXO: unspent output owned by the loaded wallet (confirmed or
+//     in the mempool)
+//   - length: number of transactions to build (must be >= 1)
+//   - feeRateSatVB: fee rate per link in sat/vB (must be > 0)
+//
+// Returns:
+//   - []*wire.MsgTx: the chain, parent first
+//   - error: validation error for bad arguments, an unknown or spent
+//     funding output, or a chain that runs out of value; errNotConnected
+//     before Start; otherwise wrapped RPC error.
+//
+// Example:
+//
+//	chain, err := rt.CreateTxChain(utxo, 25, 2)
+//	if err != nil {
+//	    return err
+//	}
+//	for _, tx := range chain {
+//	    if _, err := rt.BroadcastTransaction(tx); err != nil {
+//	        return err
+//	    }
+//	}
+func (r *Regtest) CreateTxChain(fundingUTXO wire.OutPoint, length int, feeRateSatVB float64) ([]*wire.MsgTx, error) {
+	return r.CreateTxChainContext(context.Background(), fundingUTXO, length, feeRateSatVB)
+}
+
+// CreateTxChainContext is the context-aware variant of CreateTxChain.
+func (r *Regtest) CreateTxChainContext(ctx context.Context, fundingUTXO wire.OutPoint, length int, feeRateSatVB float64) ([]*wire.MsgTx, error) {
+	if length < 1 {
+		return nil, fmt.Errorf("length must be at least 1, got %d", length)
+	}
+	if !(feeRateSatVB > 0) || math.IsInf(feeRateSatVB, 0) {
+		return nil, fmt.Errorf("fee rate must be a positive sat/vB value, got %v", feeRateSatVB)
+	}
+
+	out, err := r.GetTxOutContext(ctx, &fundingUTXO.Hash, fundingUTXO.Index, true)
+	if err != nil {
+		return nil, err
+	}
+	if out == nil {
+		return nil, fmt.Errorf("funding output %s not found or already spent", fundingUTXO)
+	}
+	script, err := hex.DecodeString(out.ScriptPubKey.Hex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode funding scriptPubKey: %w", err)
+	}
+	value, err := btcutil.NewAmount(out.Value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert funding amount %v: %w", out.Value, err)
+	}
+
+	chain := make([]*wire.MsgTx, 0, length)
+	prev := fundingUTXO
+	for i := 0; i < length; i++ {
+		amount := value.ToBTC()
+		prevTxns := []btcjson.RawTxWitnessInput{{
+			Txid:         prev.Hash.String(),
+			Vout:         prev.Index,
+			ScriptPubKey: out.ScriptPubKey.Hex,
+			Amount:       &amount,
+		}}
+		tx := wire.NewMsgTx(2)
+		tx.AddTxIn(wire.NewTxIn(&prev, nil, nil))
+		tx.AddTxOut(wire.NewTxOut(int64(value), script))
+
+		// Sign once at zero fee to learn the real vsize, then set the
+		// output and sign again.
+		signed, err := r.signWithWallet(ctx, tx, prevTxns)
+		if err != nil {
+			return nil, fmt.Errorf("signing chain tx %d: %w", i, err)
+		}
+		fee := btcutil.Amount(math.Ceil(float64(txVirtualSize(signed)+1) * feeRateSatVB))
+		if fee >= value {
+			return nil, fmt.Errorf("chain tx %d: fee %v exceeds remaining value %v", i, fee, value)
+		}
+		value -= fee
+		tx.TxOut[0].Value = int64(value)
+
+		signed, err = r.signWithWallet(ctx, tx, prevTxns)
+		if err != nil {
+			return nil, fmt.Errorf("signing chain tx %d: %w", i, err)
+		}
+		chain = append(chain, signed)
+		prev = wire.OutPoint{Hash: *ComputeTxID(signed), Index: 0}
+	}
+	return chain, nil
+}
+
+// Weight returns tx's BIP141 weight computed locally: three times its size
+// without witness data plus its full serialized size. No RPC is issued.
+//
+// Example:
+//
+//	fmt.Println("weight:", regtest.Weight(signed))
+func Weight(tx *wire.MsgTx) int {
+	return tx.SerializeSizeStripped()*(blockchain.WitnessScaleFactor-1) + tx.SerializeSize()
+}
+
+// VirtualSize returns tx's BIP141 virtual size computed locally: Weight
+// divided by four, rounded up — the same vsize decoderawtransaction and
+// fee-rate policy use. No RPC is issued, so an expected fee rate can be
+// computed before broadcasting.
+//
+// Example:
+//
+//	feeRate := float64(fee) / float64(regtest.VirtualSize(signed)) // sat/vB
+func VirtualSize(tx *wire.MsgTx) int {
+	return (Weight(tx) + blockchain.WitnessScaleFactor - 1) / blockchain.WitnessScaleFactor
+}
+
+// walletTx is the subset of gettransaction that confirmation tracking needs.
+type walletTx struct {
+	Confirmations   int64    `json:"confirmations"`
+	WalletConflicts []string `json:"walletconflicts"`
+	ReplacedBy      string   `json:"replaced_by_txid"`
+}
+
+// getWalletTx issues gettransaction for a txid known to the loaded wallet.
+func (r *Regtest) getWalletTx(ctx context.Context, txid string) (*walletTx, error) {
+	resp, err := r.rawRPC(ctx, "gettransaction", txid)
+	if err != nil {
+		return nil, fmt.Errorf("gettransaction %s: %w", txid, err)
+	}
+	var wtx walletTx
+	if err := json.Unmarshal(resp, &wtx); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal gettransaction: %w", err)
+	}
+	return &wtx, nil
+}
+
+// WaitForTxConfirmedOrReplaced mines blocks to miner one at a time until the
+// wallet transaction txid — or whatever replaced it — has at least minConf
+// confirmations, and returns the txid that actually confirmed.
+//
+// Replacement is followed through gettransaction: a replaced_by_txid (set
+// once a BIP125 replacement enters the mempool) is tracked directly, and a
+// negative confirmation count (the original conflicted with a mined tx) is
+// resolved by finding the confirmed entry in walletconflicts. Chains of
+// replacements are followed to the end. Both the original and its
+// replacement must be wallet transactions.
+//
+// Parameters:
+//   - ctx: bounds the whole wait; checked between blocks.
+//   - txid: wallet transaction to track.
+//   - minConf: confirmations required (must be >= 1).
+//   - miner: address receiving the mined blocks (must be non-empty).
+//
+// Returns:
+//   - confirmed: txid that reached minConf — txid itself unless replaced
+//   - err: validation error; ctx.Err() on cancellation; an error if the tx
+//     stays unconfirmed after a block is mined (e.g. it left the mempool)
+//     or was conflicted by a tx outside the wallet; otherwise wrapped RPC
+//     error.
+//
+// Example:
+//
+//	confirmed, err := rt.WaitForTxConfirmedOrReplaced(ctx, txid, 1, miner)
+//	if err != nil {
+//	    return err
+//	}
+//	if !confirmed.IsEqual(txid) {
+//	    fmt.Printf("%s was replaced by %s\n", txid, confirmed)
+//	}
+func (r *Regtest) WaitForTxConfirmedOrReplaced(ctx context.Context, txid *chainhash.Hash, minConf int, miner string) (confirmed *chainhash.Hash, err error) {
+	if txid == nil {
+		return nil, fmt.Errorf("txid must not be nil")
+	}
+	if minConf < 1 {
+		return nil, fmt.Errorf("minConf must be >= 1, got %d", minConf)
+	}
+	if miner == "" {
+		return nil, fmt.Errorf("miner must be provided")
+	}
+
+	current := txid.String()
+	minedAtZero := false
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		wtx, err := r.getWalletTx(ctx, current)
+		if err != nil {
+			return nil, err
+		}
+		if wtx.Confirmations >= int64(minConf) {
+			return chainhash.NewHashFromStr(current)
+		}
+
+		next := wtx.ReplacedBy
+		if wtx.Confirmations < 0 {
+			next, err = r.confirmedConflict(ctx, wtx.WalletConflicts)
+			if err != nil {
+				return nil, fmt.Errorf("tx %s was conflicted: %w", current, err)
+			}
+		}
+		if next != "" && next != current {
+			current = next
+			minedAtZero = false
+			continue
+		}
+
+		if wtx.Confirmations == 0 {
+			if minedAtZero {
+				return nil, fmt.Errorf("tx %s still unconfirmed after mining a block and has no replacement", current)
+			}
+			minedAtZero = true
+		}
+		if err := r.WarpContext(ctx, 1, miner); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// confirmedConflict returns the first wallet conflict with at least one
+// confirmation. Conflicts unknown to the wallet are skipped.
+func (r *Regtest) confirmedConflict(ctx context.Context, conflicts []string) (string, error) {
+	for _, c := range conflicts {
+		wtx, err := r.getWalletTx(ctx, c)
+		if err != nil {
+			if ctx.Err() != nil {
+				return "", ctx.Err()
+			}
+			continue
+		}
+		if wtx.Confirmations > 0 {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("no confirmed replacement found in the wallet")
+}
+
+// ConfirmStable mines blocks to miner until txid is buried minConf deep in
+// the main chain, then re-reads its containing block's depth to make sure
+// no reorg displaced it in the meantime. When a reorg (from a peer,
+// InvalidateBlock on another goroutine, ...) moved the tx back to the
+// mempool or into a different block, it keeps mining until the new
+// confirmation also reaches minConf. It returns only once the confirmation
+// is stable.
+//
+// The tx is looked up with getrawtransaction, which finds confirmed
+// transactions through the -txindex the manager script always enables, so
+// it need not be a wallet transaction.
+//
+// Parameters:
+//   - ctx: bounds the whole wait; checked between blocks.
+//   - txid: transaction to confirm (must be non-nil)
+//   - minConf: confirmations required (must be >= 1)
+//   - miner: address receiving the mined blocks (must be non-empty)
+//
+// Returns:
+//   - error: validation error for bad arguments; ctx.Err() on
+//     cancellation; an error when the tx stays unconfirmed after a block is
+//     mined (e.g. a reorg conflicted it out of the mempool);
+//     errNotConnected before Start; otherwise wrapped RPC error (including
+//     an unknown txid).
+//
+// Example:
+//
+//	if err := rt.ConfirmStable(ctx, txid, 6, miner); err != nil {
+//	    t.Fatalf("tx never reached stable depth: %v", err)
+//	}
+func (r *Regtest) ConfirmStable(ctx context.Context, txid *chainhash.Hash, minConf int, miner string) error {
+	if txid == nil {
+		return fmt.Errorf("txid must not be nil")
+	}
+	if minConf < 1 {
+		return fmt.Errorf("minConf must be >= 1, got %d", minConf)
+	}
+	if miner == "" {
+		return fmt.Errorf("miner must be provided")
+	}
+
+	minedAtZero := false
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		conf, block, err := r.txDepth(ctx, txid)
+		if err != nil {
+			return err
+		}
+		if conf >= int64(minConf) {
+			// Re-verify against the block itself: a reorg between the two
+			// reads leaves it off the main chain (confirmations -1).
+			blockConf, err := r.blockDepth(ctx, block)
+			if err != nil {
+				return err
+			}
+			if blockConf >= int64(minConf) {
+				return nil
+			}
+			continue
+		}
+		if conf == 0 {
+			if minedAtZero {
+				return fmt.Errorf("tx %s still unconfirmed after mining a block", txid)
+			}
+			minedAtZero = true
+		} else {
+			minedAtZero = false
+		}
+		if err := r.WarpContext(ctx, int64(minConf)-conf, miner); err != nil {
+			return err
+		}
+	}
+}
+
+// txDepth returns txid's confirmation count and containing block hash via
+// getrawtransaction (0 and "" while it sits in the mempool).
+func (r *Regtest) txDepth(ctx context.Context, txid *chainhash.Hash) (int64, string, error) {
+	resp, err := r.rawRPC(ctx, "getrawtransaction", txid.String(), true)
+	if err != nil {
+		return 0, "", fmt.Errorf("getrawtransaction %s: %w", txid, err)
+	}
+	var tx struct {
+		BlockHash     string `json:"blockhash"`
+		Confirmations int64  `json:"confirmations"`
+	}
+	if err := json.Unmarshal(resp, &tx); err != nil {
+		return 0, "", fmt.Errorf("unmarshal getrawtransaction: %w", err)
+	}
+	return tx.Confirmations, tx.BlockHash, nil
+}
+
+// blockDepth returns the confirmation count of block, -1 when it is not on
+// the main chain.
+func (r *Regtest) blockDepth(ctx context.Context, block string) (int64, error) {
+	resp, err := r.rawRPC(ctx, "getblockheader", block, true)
+	if err != nil {
+		return 0, fmt.Errorf("getblockheader %s: %w", block, err)
+	}
+	var hdr struct {
+		Confirmations int64 `json:"confirmations"`
+	}
+	if err := json.Unmarshal(resp, &hdr); err != nil {
+		return 0, fmt.Errorf("unmarshal getblockheader: %w", err)
+	}
+	return hdr.Confirmations, nil
+}
+
+// IsReplaceable reports whether tx explicitly signals BIP125 replaceability:
+// at least one input has an nSequence below 0xfffffffe. Computed locally
+// with no RPC, so it checks what a constructed transaction says about
+// itself — inherited signaling from unconfirmed ancestors is only visible
+// to the node (see IsReplaceableInMempool).
+//
+// Example:
+//
+//	if !regtest.IsReplaceable(tx) {
+//	    t.Fatal("expected tx to signal RBF")
+//	}
+func IsReplaceable(tx *wire.MsgTx) bool {
+	for _, in := range tx.TxIn {
+		if in.Sequence < wire.MaxTxInSequenceNum-1 {
+			return true
+		}
+	}
+	return false
+}
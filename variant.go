@@ -3,10 +3,16 @@ package regtest
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 )
 
+// ErrUnsupportedVersion is returned (wrapped) by methods whose RPC needs a
+// newer bitcoind than the running node. Match it with errors.Is to skip a
+// test on older builds.
+var ErrUnsupportedVersion = errors.New("unsupported bitcoind version")
+
 // Variant identifies which bitcoind implementation the running node belongs to.
 //
 // Inspected via getnetworkinfo's subversion field. Used by tests to gate
@@ -121,3 +127,40 @@ func parseVariant(subversion string) Variant {
 	}
 	return VariantCore
 }
+
+// nodeVersion returns the running node's numeric version from
+// getnetworkinfo, encoded as major*10000 + minor*100 + patch (e.g. 270100
+// for 27.1.0). Inquisition builds report the Core version they are based on.
+func (r *Regtest) nodeVersion(ctx context.Context) (int, error) {
+	raw, err := r.rawRPC(ctx, "getnetworkinfo")
+	if err != nil {
+		return 0, fmt.Errorf("getnetworkinfo: %w", err)
+	}
+	var info struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(raw, &info); err != nil {
+		return 0, fmt.Errorf("parse getnetworkinfo: %w", err)
+	}
+	return info.Version, nil
+}
+
+// requireVersion returns an error wrapping ErrUnsupportedVersion when the
+// running node is older than minVersion (in nodeVersion's encoding).
+// feature names the caller in the message.
+func (r *Regtest) requireVersion(ctx context.Context, minVersion int, feature string) error {
+	v, err := r.nodeVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if v < minVersion {
+		return fmt.Errorf("%w: %s requires Bitcoin Core %s or newer, node is %s",
+			ErrUnsupportedVersion, feature, formatVersion(minVersion), formatVersion(v))
+	}
+	return nil
+}
+
+// formatVersion renders a numeric bitcoind version as "major.minor.patch".
+func formatVersion(v int) string {
+	return fmt.Sprintf("%d.%d.%d", v/10000, v/100%100, v%100)
+}
@@ -2,10 +2,15 @@ package regtest
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
 )
 
 // GetWalletInformation retrieves detailed information about the currently loaded wallet.
@@ -223,3 +228,845 @@ func (r *Regtest) EnsureWalletContext(ctx context.Context, walletName string) er
 
 	return nil
 }
+
+// validateWallets checks Config.Wallets and Config.MineToMaturity.
+func validateWallets(wallets []string, mineToMaturity bool) error {
+	seen := make(map[string]bool, len(wallets))
+	for i, name := range wallets {
+		if name == "" {
+			return fmt.Errorf("Wallets[%d] must not be empty", i)
+		}
+		if seen[name] {
+			return fmt.Errorf("Wallets lists %q twice", name)
+		}
+		seen[name] = true
+	}
+	if mineToMaturity && len(wallets) == 0 {
+		return fmt.Errorf("MineToMaturity needs a wallet in Wallets to fund")
+	}
+	return nil
+}
+
+// setupWallets creates or loads Config.Wallets and, with
+// Config.MineToMaturity, mines to a mature coinbase for the first one. Run
+// by StartContext once RPC answers; the node stays up on error.
+func (r *Regtest) setupWallets(ctx context.Context) error {
+	for _, name := range r.config.Wallets {
+		if err := r.EnsureWalletContext(ctx, name); err != nil {
+			return fmt.Errorf("wallet %q: %w", name, err)
+		}
+	}
+	if !r.config.MineToMaturity {
+		return nil
+	}
+	addr, err := r.walletNewAddress(ctx, r.config.Wallets[0])
+	if err != nil {
+		return err
+	}
+	if err := r.MineToHeightContext(ctx, coinbaseMaturity+1, addr); err != nil {
+		return fmt.Errorf("mine to maturity: %w", err)
+	}
+	return nil
+}
+
+// walletNewAddress returns a fresh bech32 receive address from walletName.
+func (r *Regtest) walletNewAddress(ctx context.Context, walletName string) (string, error) {
+	raw, err := r.walletRawRPC(ctx, walletName, "getnewaddress", "", "bech32")
+	if err != nil {
+		return "", fmt.Errorf("wallet %q: getnewaddress: %w", walletName, err)
+	}
+	var addr string
+	if err := json.Unmarshal(raw, &addr); err != nil {
+		return "", fmt.Errorf("failed to unmarshal getnewaddress: %w", err)
+	}
+	return addr, nil
+}
+
+// SignerDevice is a single external signer reported by enumeratesigners.
+type SignerDevice struct {
+	// Fingerprint is the master key fingerprint of the device, as hex.
+	Fingerprint string `json:"fingerprint"`
+	// Name is the device name (the "model" field of the signer command's
+	// enumerate output).
+	Name string `json:"name"`
+}
+
+// EnumerateSigners returns the external signers visible to bitcoind via the
+// command configured in Config.ExternalSigner (-signer). Convenience wrapper
+// around EnumerateSignersContext using context.Background().
+//
+// To sign with a reported device, the wallet must be created with
+// external_signer=true (and disable_private_keys=true), e.g. via
+// Client().RawRequest("createwallet", ...); walletprocesspsbt then routes
+// signing through the signer command.
+//
+// Returns:
+//   - []SignerDevice: one entry per device the signer command enumerated
+//     (empty when none are attached).
+//   - error: errNotConnected before Start; otherwise the wrapped RPC error
+//     (e.g. "Error: restart bitcoind with -signer=<cmd>" when
+//     Config.ExternalSigner is empty).
+//
+// Example:
+//
+//	signers, err := rt.EnumerateSigners()
+//	if err != nil {
+//	    return err
+//	}
+//	for _, s := range signers {
+//	    fmt.Printf("signer %s (%s)\n", s.Name, s.Fingerprint)
+//	}
+func (r *Regtest) EnumerateSigners() ([]SignerDevice, error) {
+	return r.EnumerateSignersContext(context.Background())
+}
+
+// EnumerateSignersContext is the context-aware variant of EnumerateSigners.
+func (r *Regtest) EnumerateSignersContext(ctx context.Context) ([]SignerDevice, error) {
+	raw, err := r.rawRPC(ctx, "enumeratesigners")
+	if err != nil {
+		return nil, fmt.Errorf("enumeratesigners: %w", err)
+	}
+	var result struct {
+		Signers []SignerDevice `json:"signers"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal enumeratesigners: %w", err)
+	}
+	return result.Signers, nil
+}
+
+// listAllCount is the listtransactions count used to fetch the whole wallet
+// history in one call; regtest wallets never come close.
+const listAllCount = 1_000_000
+
+// ListConflictedTransactions returns the loaded wallet's listtransactions
+// entries for transactions that lost a double-spend. That covers two cases:
+//   - block-conflicted: a conflicting tx was mined, and bitcoind reports a
+//     negative confirmation count;
+//   - mempool-conflicted: the tx was replaced in the mempool (e.g. by RBF or
+//     bumpfee) and sits at zero confirmations, untrusted, with a non-empty
+//     walletconflicts list.
+//
+// Entries already abandoned are skipped. A tx with several wallet-relevant
+// outputs contributes one entry per output, as in listtransactions.
+//
+// Returns:
+//   - []btcjson.ListTransactionsResult: conflicted entries, oldest first
+//   - error: errNotConnected before Start; otherwise wrapped RPC or
+//     unmarshal error.
+//
+// Example:
+//
+//	conflicted, err := rt.ListConflictedTransactions()
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Printf("%d conflicted entries\n", len(conflicted))
+func (r *Regtest) ListConflictedTransactions() ([]btcjson.ListTransactionsResult, error) {
+	return r.ListConflictedTransactionsContext(context.Background())
+}
+
+// ListConflictedTransactionsContext is the context-aware variant of
+// ListConflictedTransactions.
+func (r *Regtest) ListConflictedTransactionsContext(ctx context.Context) ([]btcjson.ListTransactionsResult, error) {
+	all, err := r.listAllTransactions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var conflicted []btcjson.ListTransactionsResult
+	for _, e := range all {
+		if e.Abandoned {
+			continue
+		}
+		replaced := e.Confirmations == 0 && !e.Trusted && len(e.WalletConflicts) > 0
+		if e.Confirmations < 0 || replaced {
+			conflicted = append(conflicted, e)
+		}
+	}
+	return conflicted, nil
+}
+
+// listAllTransactions returns the loaded wallet's whole listtransactions
+// history, watch-only entries included.
+func (r *Regtest) listAllTransactions(ctx context.Context) ([]btcjson.ListTransactionsResult, error) {
+	raw, err := r.rawRPC(ctx, "listtransactions", "*", listAllCount, 0, true)
+	if err != nil {
+		return nil, fmt.Errorf("listtransactions: %w", err)
+	}
+	var all []btcjson.ListTransactionsResult
+	if err := json.Unmarshal(raw, &all); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal listtransactions: %w", err)
+	}
+	return all, nil
+}
+
+// PurgeConflicted abandons the mempool-conflicted transactions reported by
+// ListConflictedTransactions via abandontransaction, releasing any inputs
+// they still claimed and dropping them from the listing. Each txid is
+// abandoned once even if it has several entries.
+//
+// Block-conflicted entries (negative confirmations) are left alone:
+// bitcoind only abandons transactions at depth zero, and it already treats
+// block-conflicted ones as inactive for balances and coin selection. They
+// stay in ListConflictedTransactions until the conflicting block is
+// reorganised away.
+//
+// Returns:
+//   - error: errNotConnected before Start; otherwise the wrapped RPC error
+//     for the first txid bitcoind refused to abandon.
+//
+// Example:
+//
+//	if err := rt.PurgeConflicted(); err != nil {
+//	    return err
+//	}
+func (r *Regtest) PurgeConflicted() error {
+	return r.PurgeConflictedContext(context.Background())
+}
+
+// PurgeConflictedContext is the context-aware variant of PurgeConflicted.
+func (r *Regtest) PurgeConflictedContext(ctx context.Context) error {
+	conflicted, err := r.ListConflictedTransactionsContext(ctx)
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]bool, len(conflicted))
+	for _, e := range conflicted {
+		if e.Confirmations != 0 || seen[e.TxID] {
+			continue
+		}
+		seen[e.TxID] = true
+		if _, err := r.rawRPC(ctx, "abandontransaction", e.TxID); err != nil {
+			return fmt.Errorf("abandontransaction %s: %w", e.TxID, err)
+		}
+	}
+	return nil
+}
+
+// ResyncWallet is a post-reorg recovery routine for the loaded wallet. It
+// abandons every unconfirmed wallet transaction that is no longer in the
+// mempool — ones replaced by RBF, and ones a reorg disconnected that could
+// not re-enter the mempool (e.g. spends of a now-immature or orphaned
+// coinbase) — releasing the inputs they claimed. It then runs a full
+// rescanblockchain so the wallet's view is rebuilt from the canonical
+// chain, and finally mines one block to miner, confirming the transactions
+// the reorg returned to the mempool.
+//
+// Block-conflicted transactions (negative confirmations) can't be
+// abandoned — bitcoind refuses any tx with a conflicting block in the
+// chain — but it already treats them as inactive for balances and coin
+// selection. Coinbase entries are left alone too.
+//
+// Parameters:
+//   - miner: address to mine the settling block to; "" skips mining
+//
+// Returns:
+//   - error: errNotConnected before Start; otherwise the wrapped RPC error
+//     of the first failing step (abandontransaction names the txid).
+//
+// Example:
+//
+//	// After rt's chain reorganised:
+//	if err := rt.ResyncWallet(miner); err != nil {
+//	    return err
+//	}
+func (r *Regtest) ResyncWallet(miner string) error {
+	return r.ResyncWalletContext(context.Background(), miner)
+}
+
+// ResyncWalletContext is the context-aware variant of ResyncWallet.
+func (r *Regtest) ResyncWalletContext(ctx context.Context, miner string) error {
+	all, err := r.listAllTransactions(ctx)
+	if err != nil {
+		return err
+	}
+	mempool, err := r.GetRawMempoolVerboseContext(ctx)
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]bool, len(all))
+	for _, e := range all {
+		if e.Abandoned || e.Generated || e.Confirmations != 0 || seen[e.TxID] {
+			continue
+		}
+		seen[e.TxID] = true
+		if _, ok := mempool[e.TxID]; ok {
+			continue
+		}
+		if _, err := r.rawRPC(ctx, "abandontransaction", e.TxID); err != nil {
+			return fmt.Errorf("abandontransaction %s: %w", e.TxID, err)
+		}
+	}
+	if _, err := r.rawRPC(ctx, "rescanblockchain"); err != nil {
+		return fmt.Errorf("rescanblockchain: %w", err)
+	}
+	if miner == "" {
+		return nil
+	}
+	return r.WarpContext(ctx, 1, miner)
+}
+
+// SetTxFee sets the loaded wallet's fallback fee rate via settxfee, used when
+// fee estimation has no data (always the case on a fresh regtest chain).
+// Pass 0 to clear the override and fall back to -fallbackfee. Deprecated
+// upstream in Bitcoin Core 29 in favour of per-call fee_rate arguments.
+//
+// Parameters:
+//   - feeRateBTCkvB: fee rate in BTC per kvB (must be >= 0)
+//
+// Returns:
+//   - bool: true when bitcoind accepted the rate
+//   - error: validation error for a negative rate; errNotConnected before
+//     Start; otherwise wrapped RPC error (e.g. a rate below -mintxfee).
+//
+// Example:
+//
+//	if _, err := rt.SetTxFee(0.0001); err != nil { // 10 sat/vB
+//	    return err
+//	}
+func (r *Regtest) SetTxFee(feeRateBTCkvB float64) (bool, error) {
+	return r.SetTxFeeContext(context.Background(), feeRateBTCkvB)
+}
+
+// SetTxFeeContext is the context-aware variant of SetTxFee.
+func (r *Regtest) SetTxFeeContext(ctx context.Context, feeRateBTCkvB float64) (bool, error) {
+	if feeRateBTCkvB < 0 {
+		return false, fmt.Errorf("fee rate must be >= 0, got %v", feeRateBTCkvB)
+	}
+	raw, err := r.rawRPC(ctx, "settxfee", feeRateBTCkvB)
+	if err != nil {
+		return false, fmt.Errorf("settxfee: %w", err)
+	}
+	var ok bool
+	if err := json.Unmarshal(raw, &ok); err != nil {
+		return false, fmt.Errorf("failed to unmarshal settxfee: %w", err)
+	}
+	return ok, nil
+}
+
+// GetReceivedByLabel returns the total the loaded wallet has received, in
+// satoshis, across every address carrying label (as given to
+// GenerateBech32 and friends), via getreceivedbylabel. Only receives count:
+// spending from those addresses doesn't lower it, and coinbase outputs
+// aren't included.
+//
+// Parameters:
+//   - label: address label; "" is the label of unlabelled addresses
+//   - minConf: only count transactions with at least this many
+//     confirmations (must be >= 0; 0 includes the mempool)
+//
+// Returns:
+//   - int64: received total in satoshis
+//   - error: validation error for a negative minConf; errNotConnected
+//     before Start; otherwise wrapped RPC error (e.g. "No addresses with
+//     label" for an unknown label).
+//
+// Example:
+//
+//	deposits, err := rt.GetReceivedByLabel("customer-42", 1)
+//	if err != nil {
+//	    return err
+//	}
+func (r *Regtest) GetReceivedByLabel(label string, minConf int) (int64, error) {
+	return r.GetReceivedByLabelContext(context.Background(), label, minConf)
+}
+
+// GetReceivedByLabelContext is the context-aware variant of
+// GetReceivedByLabel.
+func (r *Regtest) GetReceivedByLabelContext(ctx context.Context, label string, minConf int) (int64, error) {
+	if minConf < 0 {
+		return 0, fmt.Errorf("minConf must be >= 0, got %d", minConf)
+	}
+	raw, err := r.rawRPC(ctx, "getreceivedbylabel", label, minConf)
+	if err != nil {
+		return 0, fmt.Errorf("getreceivedbylabel %q: %w", label, err)
+	}
+	var btc float64
+	if err := json.Unmarshal(raw, &btc); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal getreceivedbylabel: %w", err)
+	}
+	amt, err := btcutil.NewAmount(btc)
+	if err != nil {
+		return 0, fmt.Errorf("converting received amount %v: %w", btc, err)
+	}
+	return int64(amt), nil
+}
+
+// GetBalance returns the default wallet's trusted balance — confirmed
+// outputs plus its own unconfirmed change, excluding immature coinbase — in
+// satoshis, so assertions compare exact integers rather than BTC floats.
+//
+// Returns:
+//   - btcutil.Amount: trusted balance in satoshis
+//   - error: errNotConnected before Start; otherwise wrapped RPC error
+//     (e.g. no wallet, or several wallets, loaded).
+//
+// Example:
+//
+//	bal, err := rt.GetBalance()
+//	if err != nil {
+//	    return err
+//	}
+//	if bal < btcutil.Amount(amountSats) {
+//	    return fmt.Errorf("wallet holds only %v", bal)
+//	}
+func (r *Regtest) GetBalance() (btcutil.Amount, error) {
+	return r.GetBalanceContext(context.Background())
+}
+
+// GetBalanceContext is the context-aware variant of GetBalance.
+func (r *Regtest) GetBalanceContext(ctx context.Context) (btcutil.Amount, error) {
+	balances, err := r.GetBalancesContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	amt, err := btcutil.NewAmount(balances.Mine.Trusted)
+	if err != nil {
+		return 0, fmt.Errorf("converting trusted balance %v: %w", balances.Mine.Trusted, err)
+	}
+	return amt, nil
+}
+
+// GetBalances returns the default wallet's getbalances breakdown: Mine.Trusted
+// (spendable), Mine.UntrustedPending (unconfirmed incoming) and
+// Mine.Immature (coinbase outputs short of 100 confirmations), in BTC.
+//
+// Returns:
+//   - *btcjson.GetBalancesResult: balances by trust level
+//   - error: errNotConnected before Start; otherwise wrapped RPC error.
+//
+// Example:
+//
+//	b, err := rt.GetBalances()
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Printf("spendable %.8f, immature %.8f\n", b.Mine.Trusted, b.Mine.Immature)
+func (r *Regtest) GetBalances() (*btcjson.GetBalancesResult, error) {
+	return r.GetBalancesContext(context.Background())
+}
+
+// GetBalancesContext is the context-aware variant of GetBalances.
+func (r *Regtest) GetBalancesContext(ctx context.Context) (*btcjson.GetBalancesResult, error) {
+	client, err := r.lockedClient()
+	if err != nil {
+		return nil, err
+	}
+	balances, err := runWithContext(ctx, client.GetBalances)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balances: %w", err)
+	}
+	return balances, nil
+}
+
+// SetWalletFlagResult is the result of setwalletflag. btcjson has no type
+// for this RPC.
+type SetWalletFlagResult struct {
+	// FlagName is the flag that was changed.
+	FlagName string `json:"flag_name"`
+	// FlagState is the flag's new value.
+	FlagState bool `json:"flag_state"`
+	// Warnings is any warning bitcoind attached (e.g. that avoid_reuse
+	// marks already-used addresses only from now on). Empty when none.
+	Warnings string `json:"warnings"`
+}
+
+// SetWalletFlag changes a runtime wallet flag via setwalletflag, e.g.
+// "avoid_reuse" to keep coin selection away from previously used addresses.
+//
+// Parameters:
+//   - flag: flag name (must be non-empty)
+//   - value: new state
+//
+// Returns:
+//   - *SetWalletFlagResult: the flag name and its new state
+//   - error: validation error for an empty flag; errNotConnected before
+//     Start; otherwise wrapped RPC error (e.g. unknown flag, or the flag is
+//     already in the requested state).
+//
+// Example:
+//
+//	res, err := rt.SetWalletFlag("avoid_reuse", true)
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Println(res.FlagName, res.FlagState)
+func (r *Regtest) SetWalletFlag(flag string, value bool) (*SetWalletFlagResult, error) {
+	return r.SetWalletFlagContext(context.Background(), flag, value)
+}
+
+// SetWalletFlagContext is the context-aware variant of SetWalletFlag.
+func (r *Regtest) SetWalletFlagContext(ctx context.Context, flag string, value bool) (*SetWalletFlagResult, error) {
+	if flag == "" {
+		return nil, fmt.Errorf("flag must not be empty")
+	}
+	raw, err := r.rawRPC(ctx, "setwalletflag", flag, value)
+	if err != nil {
+		return nil, fmt.Errorf("setwalletflag %s: %w", flag, err)
+	}
+	var res SetWalletFlagResult
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal setwalletflag: %w", err)
+	}
+	return &res, nil
+}
+
+// walletInfoFields is the subset of getwalletinfo read by KeyPoolSize and
+// IsWalletLocked. UnlockedUntil is a pointer so an absent field (an
+// unencrypted wallet) is distinguishable from 0 (encrypted and locked).
+type walletInfoFields struct {
+	KeyPoolSize   int    `json:"keypoolsize"`
+	UnlockedUntil *int64 `json:"unlocked_until"`
+}
+
+// getWalletInfoFields issues getwalletinfo against the loaded wallet.
+func (r *Regtest) getWalletInfoFields(ctx context.Context) (*walletInfoFields, error) {
+	raw, err := r.rawRPC(ctx, "getwalletinfo")
+	if err != nil {
+		return nil, fmt.Errorf("getwalletinfo: %w", err)
+	}
+	var info walletInfoFields
+	if err := json.Unmarshal(raw, &info); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal getwalletinfo: %w", err)
+	}
+	return &info, nil
+}
+
+// KeyPoolSize returns the number of pre-generated keys in the loaded wallet's
+// keypool (getwalletinfo keypoolsize). For descriptor wallets this counts
+// the external-chain keys bitcoind has derived ahead.
+//
+// Returns:
+//   - int: keypool size
+//   - error: errNotConnected before Start; otherwise wrapped RPC or
+//     unmarshal error.
+//
+// Example:
+//
+//	n, err := rt.KeyPoolSize()
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Println("keypool:", n)
+func (r *Regtest) KeyPoolSize() (int, error) {
+	return r.KeyPoolSizeContext(context.Background())
+}
+
+// KeyPoolSizeContext is the context-aware variant of KeyPoolSize.
+func (r *Regtest) KeyPoolSizeContext(ctx context.Context) (int, error) {
+	info, err := r.getWalletInfoFields(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return info.KeyPoolSize, nil
+}
+
+// IsWalletLocked reports whether the loaded wallet is encrypted and currently
+// locked. It reads getwalletinfo's unlocked_until: absent means the wallet is
+// unencrypted (never locked), 0 means encrypted and locked, and any other
+// value is the unix time at which an unlocked wallet relocks.
+//
+// Returns:
+//   - bool: true only for an encrypted, locked wallet
+//   - error: errNotConnected before Start; otherwise wrapped RPC or
+//     unmarshal error.
+//
+// Example:
+//
+//	locked, err := rt.IsWalletLocked()
+//	if err != nil {
+//	    return err
+//	}
+//	if locked {
+//	    // walletpassphrase before signing
+//	}
+func (r *Regtest) IsWalletLocked() (bool, error) {
+	return r.IsWalletLockedContext(context.Background())
+}
+
+// IsWalletLockedContext is the context-aware variant of IsWalletLocked.
+func (r *Regtest) IsWalletLockedContext(ctx context.Context) (bool, error) {
+	info, err := r.getWalletInfoFields(ctx)
+	if err != nil {
+		return false, err
+	}
+	return info.UnlockedUntil != nil && *info.UnlockedUntil == 0, nil
+}
+
+// MigrateWalletResult is the result of migratewallet. btcjson has no type
+// for this RPC.
+type MigrateWalletResult struct {
+	// WalletName is the name of the migrated descriptor wallet.
+	WalletName string `json:"wallet_name"`
+	// WatchOnlyName is the wallet split off for watch-only scripts, or empty
+	// when the legacy wallet had none.
+	WatchOnlyName string `json:"watchonly_name"`
+	// SolvablesName is the wallet split off for solvable-but-not-watched
+	// scripts, or empty when the legacy wallet had none.
+	SolvablesName string `json:"solvables_name"`
+	// BackupPath is the backup of the legacy wallet bitcoind wrote before
+	// migrating.
+	BackupPath string `json:"backup_path"`
+}
+
+// MigrateWallet converts the legacy (BDB) wallet name into a descriptor
+// wallet via migratewallet, the path real users take off legacy wallets.
+// Watch-only and solvable scripts that don't belong in the main wallet are
+// moved into separate wallets whose names are reported in the result.
+//
+// migratewallet exists from Bitcoin Core 24; on older nodes this returns an
+// error wrapping ErrUnsupportedVersion without calling it.
+//
+// Parameters:
+//   - name: legacy wallet to migrate (must be non-empty)
+//
+// Returns:
+//   - *MigrateWalletResult: the migrated wallet's name, any split-off
+//     wallets, and the backup path
+//   - error: validation error for an empty name; errNotConnected before
+//     Start; ErrUnsupportedVersion (wrapped) before Core 24; otherwise
+//     wrapped RPC error (e.g. the wallet is already a descriptor wallet).
+//
+// Example:
+//
+//	res, err := rt.MigrateWallet("legacy")
+//	if errors.Is(err, regtest.ErrUnsupportedVersion) {
+//	    t.Skip(err)
+//	}
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Println("migrated to", res.WalletName, "backup at", res.BackupPath)
+func (r *Regtest) MigrateWallet(name string) (*MigrateWalletResult, error) {
+	return r.MigrateWalletContext(context.Background(), name)
+}
+
+// MigrateWalletContext is the context-aware variant of MigrateWallet.
+func (r *Regtest) MigrateWalletContext(ctx context.Context, name string) (*MigrateWalletResult, error) {
+	if name == "" {
+		return nil, fmt.Errorf("wallet name must not be empty")
+	}
+	if err := r.requireVersion(ctx, 240000, "migratewallet"); err != nil {
+		return nil, err
+	}
+	raw, err := r.rawRPC(ctx, "migratewallet", name)
+	if err != nil {
+		return nil, fmt.Errorf("migratewallet %s: %w", name, err)
+	}
+	var res MigrateWalletResult
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal migratewallet: %w", err)
+	}
+	return &res, nil
+}
+
+// UTXO is a wallet output in the coin-control shape SpendableOutPoints
+// returns: a typed outpoint and a satoshi amount instead of listunspent's
+// hex txid and BTC float.
+type UTXO struct {
+	// OutPoint identifies the output.
+	OutPoint wire.OutPoint
+	// Amount is the output value.
+	Amount btcutil.Amount
+	// Coinbase reports whether the output was created by a coinbase
+	// transaction.
+	Coinbase bool
+	// Confirmations is the output's confirmation count (0 for mempool).
+	Confirmations int64
+	// Address is the output's address, empty for scripts without one.
+	Address string
+}
+
+// SpendableOutPoints lists the spendable outputs of walletName with at least
+// minConf confirmations, in listunspent order. Immature coinbase outputs are
+// never included. The coinbase flag comes from gettxout, since listunspent
+// doesn't report it — one extra call per output.
+//
+// Parameters:
+//   - walletName: wallet to query; "" uses the default (single loaded)
+//     wallet
+//   - minConf: minimum confirmations (must be >= 0; 0 includes mempool
+//     outputs)
+//
+// Returns:
+//   - []UTXO: spendable outputs; empty (not nil) when there are none
+//   - error: validation error for a negative minConf; errNotConnected
+//     before Start; otherwise wrapped RPC or unmarshal error (e.g. the
+//     wallet is not loaded).
+//
+// Example:
+//
+//	utxos, err := rt.SpendableOutPoints("alice", 1)
+//	if err != nil {
+//	    return err
+//	}
+//	for _, u := range utxos {
+//	    fmt.Println(u.OutPoint, u.Amount, u.Coinbase)
+//	}
+func (r *Regtest) SpendableOutPoints(walletName string, minConf int) ([]UTXO, error) {
+	return r.SpendableOutPointsContext(context.Background(), walletName, minConf)
+}
+
+// SpendableOutPointsContext is the context-aware variant of
+// SpendableOutPoints.
+func (r *Regtest) SpendableOutPointsContext(ctx context.Context, walletName string, minConf int) ([]UTXO, error) {
+	if minConf < 0 {
+		return nil, fmt.Errorf("minConf must be >= 0, got %d", minConf)
+	}
+	raw, err := r.walletRawRPC(ctx, walletName, "listunspent", minConf)
+	if err != nil {
+		return nil, fmt.Errorf("listunspent: %w", err)
+	}
+	var entries []struct {
+		TxID          string  `json:"txid"`
+		Vout          uint32  `json:"vout"`
+		Address       string  `json:"address"`
+		Amount        float64 `json:"amount"`
+		Confirmations int64   `json:"confirmations"`
+		Spendable     bool    `json:"spendable"`
+	}
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal listunspent: %w", err)
+	}
+
+	out := make([]UTXO, 0, len(entries))
+	for _, e := range entries {
+		if !e.Spendable {
+			continue
+		}
+		hash, err := chainhash.NewHashFromStr(e.TxID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse utxo txid %q: %w", e.TxID, err)
+		}
+		amt, err := btcutil.NewAmount(e.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("convert utxo amount %v: %w", e.Amount, err)
+		}
+		txOut, err := r.GetTxOutContext(ctx, hash, e.Vout, true)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, UTXO{
+			OutPoint:      wire.OutPoint{Hash: *hash, Index: e.Vout},
+			Amount:        amt,
+			Coinbase:      txOut != nil && txOut.Coinbase,
+			Confirmations: e.Confirmations,
+			Address:       e.Address,
+		})
+	}
+	return out, nil
+}
+
+// ErrDescriptorWallet is returned by the legacy-only wallet RPCs
+// (DumpWallet, ImportWallet) when the loaded wallet is a descriptor wallet.
+var ErrDescriptorWallet = errors.New("loaded wallet is a descriptor wallet")
+
+// DumpWalletResult is the result of dumpwallet.
+type DumpWalletResult struct {
+	// Filename is the absolute path bitcoind wrote the dump to.
+	Filename string `json:"filename"`
+}
+
+// DumpWallet writes every key of the loaded legacy wallet to path in
+// bitcoind's human-readable dump format, via dumpwallet. Pair it with
+// ImportWallet to move keys between test wallets.
+//
+// dumpwallet only exists for legacy (BDB) wallets: descriptor wallets are
+// rejected with ErrDescriptorWallet before the call, and builds without
+// legacy wallet support fail the RPC itself.
+//
+// Parameters:
+//   - path: file to create (must be non-empty and not exist yet). A relative
+//     path is resolved by bitcoind against its working directory, so pass
+//     an absolute one.
+//
+// Returns:
+//   - *DumpWalletResult: the filename bitcoind actually wrote
+//   - error: validation error for an empty path; ErrDescriptorWallet for a
+//     descriptor wallet; errNotConnected before Start; otherwise wrapped RPC
+//     error (e.g. the file already exists).
+//
+// Example:
+//
+//	res, err := rt.DumpWallet(filepath.Join(t.TempDir(), "keys.txt"))
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Println("dumped to", res.Filename)
+func (r *Regtest) DumpWallet(path string) (*DumpWalletResult, error) {
+	return r.DumpWalletContext(context.Background(), path)
+}
+
+// DumpWalletContext is the context-aware variant of DumpWallet.
+func (r *Regtest) DumpWalletContext(ctx context.Context, path string) (*DumpWalletResult, error) {
+	if path == "" {
+		return nil, fmt.Errorf("path must not be empty")
+	}
+	if err := r.requireLegacyWallet(ctx, "dumpwallet"); err != nil {
+		return nil, err
+	}
+	raw, err := r.rawRPC(ctx, "dumpwallet", path)
+	if err != nil {
+		return nil, fmt.Errorf("dumpwallet %s: %w", path, err)
+	}
+	var res DumpWalletResult
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal dumpwallet: %w", err)
+	}
+	return &res, nil
+}
+
+// ImportWallet imports the keys in a DumpWallet file into the loaded legacy
+// wallet via importwallet, rescanning the chain for their outputs.
+// Descriptor wallets are rejected with ErrDescriptorWallet.
+//
+// Parameters:
+//   - path: dump file written by DumpWallet (must be non-empty)
+//
+// Returns:
+//   - error: validation error for an empty path; ErrDescriptorWallet for a
+//     descriptor wallet; errNotConnected before Start; otherwise wrapped RPC
+//     error (e.g. the file can't be opened).
+//
+// Example:
+//
+//	if err := rt.ImportWallet(res.Filename); err != nil {
+//	    return err
+//	}
+func (r *Regtest) ImportWallet(path string) error {
+	return r.ImportWalletContext(context.Background(), path)
+}
+
+// ImportWalletContext is the context-aware variant of ImportWallet.
+func (r *Regtest) ImportWalletContext(ctx context.Context, path string) error {
+	if path == "" {
+		return fmt.Errorf("path must not be empty")
+	}
+	if err := r.requireLegacyWallet(ctx, "importwallet"); err != nil {
+		return err
+	}
+	if _, err := r.rawRPC(ctx, "importwallet", path); err != nil {
+		return fmt.Errorf("importwallet %s: %w", path, err)
+	}
+	return nil
+}
+
+// requireLegacyWallet returns ErrDescriptorWallet, naming method, when the
+// loaded wallet is a descriptor wallet.
+func (r *Regtest) requireLegacyWallet(ctx context.Context, method string) error {
+	raw, err := r.rawRPC(ctx, "getwalletinfo")
+	if err != nil {
+		return fmt.Errorf("getwalletinfo: %w", err)
+	}
+	var info struct {
+		WalletName  string `json:"walletname"`
+		Descriptors bool   `json:"descriptors"`
+	}
+	if err := json.Unmarshal(raw, &info); err != nil {
+		return fmt.Errorf("failed to unmarshal getwalletinfo: %w", err)
+	}
+	if info.Descriptors {
+		return fmt.Errorf("%w: %s only works on legacy wallets (wallet %q)", ErrDescriptorWallet, method, info.WalletName)
+	}
+	return nil
+}